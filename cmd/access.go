@@ -0,0 +1,169 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/internal/access"
+	"github.com/spf13/cobra"
+)
+
+func NewAccessCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Manage access to the store",
+	}
+
+	cmd.AddCommand(newAccessGrantCmd(app))
+	cmd.AddCommand(newAccessShowCmd(app))
+	cmd.AddCommand(newAccessReportCmd(app))
+	cmd.AddCommand(newAccessQuorumCmd(app))
+	cmd.AddCommand(newAccessExpireCmd(app))
+
+	return cmd
+}
+
+func newAccessGrantCmd(app *App) *cobra.Command {
+	var keyFlag string
+	var secretFlag string
+	var flattenFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "grant [path]",
+		Short: "Grant a public key access to a path without a request",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (secretFlag == "") {
+				return fmt.Errorf("specify either a path or --secret")
+			}
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			w := access.NewGrantWorkflow(path, secretFlag, keyFlag, flattenFlag, repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFlag, "key", "", "public key file, fingerprint or @group to grant access to")
+	cmd.Flags().StringVar(&secretFlag, "secret", "", "grant access to a single secret instead of a path")
+	cmd.Flags().BoolVar(&flattenFlag, "flatten", false, "replace sub-folder recipients with the granted path's recipients")
+	_ = cmd.MarkFlagRequired("key")
+
+	return cmd
+}
+
+func newAccessShowCmd(app *App) *cobra.Command {
+	var recursiveFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "show [path]",
+		Short: "Show who can decrypt a path",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			w := access.NewShowWorkflow(path, recursiveFlag, repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+
+	// -r is taken by the persistent --repo flag
+	cmd.Flags().BoolVarP(&recursiveFlag, "recursive", "R", false, "include subfolders")
+
+	return cmd
+}
+
+func newAccessReportCmd(app *App) *cobra.Command {
+	var formatFlag string
+	var outputFlag string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report which identities can decrypt which paths",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !access.ValidReportFormat(formatFlag) {
+				return fmt.Errorf("invalid format %q (expected %s)", formatFlag, strings.Join(access.ReportFormats, ", "))
+			}
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := access.NewReportWorkflow(formatFlag, outputFlag, repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&formatFlag, "format", "csv", "output format: csv, json or markdown")
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "output file path")
+
+	return cmd
+}
+
+func newAccessQuorumCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quorum <path> <approvals>",
+		Short: "Set how many admins must approve requests for a path",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			approvals, err := strconv.Atoi(args[1])
+			if err != nil || approvals < 1 {
+				return fmt.Errorf("approvals must be a positive number, got %q", args[1])
+			}
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := access.NewQuorumWorkflow(args[0], approvals, repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+
+	return cmd
+}
+
+func newAccessExpireCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expire",
+		Short: "Revoke and rekey every grant past its expiry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := access.NewExpireWorkflow(repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+
+	return cmd
+}
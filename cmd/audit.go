@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/audit"
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/spf13/cobra"
+)
+
+func NewAuditCmd(app *App) *cobra.Command {
+	var sinceFlag string
+	var formatFlag string
+
+	cmd := &cobra.Command{
+		Use:   "audit [path]",
+		Short: "Show who changed secrets and access in the store, and when",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !audit.ValidFormat(formatFlag) {
+				return fmt.Errorf("invalid format %q (expected %s)", formatFlag, strings.Join(audit.Formats, ", "))
+			}
+			since, err := parseSince(sinceFlag)
+			if err != nil {
+				return err
+			}
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			w := audit.NewWorkflow(path, since, formatFlag, repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "only show changes newer than a duration (e.g. 30d) or a date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&formatFlag, "format", "table", "output format: table or json (one record per line)")
+
+	return cmd
+}
+
+// parseSince accepts either a duration back from now or a calendar date.
+func parseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return date, nil
+	}
+	d, err := common.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration such as 30d or a date such as 2025-01-31", s)
+	}
+	return time.Now().Add(-d), nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"github.com/gonzaloalvarez/kepr/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+func NewBundleCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Carry the store to machines without GitHub access",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "create [file]",
+		Short: "Write the current store to a bundle file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := bundle.NewCreateWorkflow(args[0], repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "apply [file]",
+		Short: "Load a bundle file as a read-only copy of the store",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := bundle.NewApplyWorkflow(args[0], repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	})
+
+	return cmd
+}
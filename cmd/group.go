@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"github.com/gonzaloalvarez/kepr/internal/access"
+	"github.com/spf13/cobra"
+)
+
+func NewGroupCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "Manage recipient groups referenced as @group in .gpg.id",
+	}
+
+	cmd.AddCommand(newGroupMemberCmd(app, "add [group] [key]", "Add a public key file or fingerprint to a group", false))
+	cmd.AddCommand(newGroupMemberCmd(app, "rm [group] [fingerprint]", "Remove a fingerprint from a group", true))
+
+	return cmd
+}
+
+func newGroupMemberCmd(app *App, use, short string, remove bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := access.NewGroupWorkflow(args[0], args[1], remove, repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+}
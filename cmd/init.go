@@ -18,16 +18,19 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	initialize "github.com/gonzaloalvarez/kepr/internal/init"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/provider"
 	"github.com/spf13/cobra"
 )
 
 const defaultInitRepoName = "kepr-store"
 
 func NewInitCmd(app *App) *cobra.Command {
-	var fromPK string
+	var fromPK, providerKind, providerURL, remote, userName, userEmail string
 	cmd := &cobra.Command{
 		Use:   "init [repo-name]",
 		Short: "Initialize a new kepr repository",
@@ -40,12 +43,55 @@ func NewInitCmd(app *App) *cobra.Command {
 					return fmt.Errorf("repo name must not contain '/'")
 				}
 			}
+			if remote != "" {
+				if providerURL != "" || (cmd.Flags().Changed("provider") && providerKind != provider.KindLocal) {
+					return fmt.Errorf("--remote cannot be combined with --provider or --url")
+				}
+				providerKind, providerURL = provider.KindLocal, remote
+			}
+			if !provider.ValidKind(providerKind) {
+				return fmt.Errorf("invalid provider %q (valid: %s)", providerKind, strings.Join(provider.Kinds, ", "))
+			}
+			if providerKind == provider.KindGitHub && providerURL != "" {
+				return fmt.Errorf("--url needs --provider gitlab, gitea, generic or local")
+			}
+			if providerKind == provider.KindLocal && providerURL != "" {
+				url, err := localRemote(providerURL)
+				if err != nil {
+					return err
+				}
+				providerURL = url
+			}
+			if (userName == "") != (userEmail == "") {
+				return fmt.Errorf("--name and --email must be given together")
+			}
 			headless, _ := cmd.Flags().GetBool("headless")
-			w := initialize.NewWorkflow(repoName, headless, fromPK, app.GitHub, app.Shell, app.UI)
+			w := initialize.NewWorkflow(repoName, headless, fromPK, providerKind, providerURL, userName, userEmail, app.GitHub, app.Shell, app.UI)
 			return w.Run(cmd.Context())
 		},
 	}
 	cmd.Flags().Bool("headless", false, "initialize without YubiKey or browser (for remote/VM environments)")
+	cmd.Flags().StringVar(&providerKind, "provider", provider.KindGitHub, "git host of the store: "+strings.Join(provider.Kinds, ", "))
+	cmd.Flags().StringVar(&providerURL, "url", "", "base URL of the GitLab or Gitea instance, the remote URL for generic git, or the bare repository for local")
+	cmd.Flags().StringVar(&remote, "remote", "", "bare repository path or file:// URL for a store without a hosting provider")
+	cmd.Flags().StringVar(&userName, "name", "", "user name, instead of reading it from the provider")
+	cmd.Flags().StringVar(&userEmail, "email", "", "user email, instead of reading it from the provider")
 	cmd.Flags().StringVar(&fromPK, "from-pk", "", "import existing GPG private key instead of generating (armored .gpg or .asc file)")
 	return cmd
 }
+
+// localRemote checks that remote is on this machine and makes plain paths
+// absolute, so the store keeps working from any directory.
+func localRemote(remote string) (string, error) {
+	if !git.IsLocalURL(remote) {
+		return "", fmt.Errorf("%s is not a local path; use --provider generic for remote URLs", remote)
+	}
+	if strings.HasPrefix(remote, "file://") {
+		return remote, nil
+	}
+	abs, err := filepath.Abs(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", remote, err)
+	}
+	return abs, nil
+}
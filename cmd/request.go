@@ -17,6 +17,10 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
 	"github.com/gonzaloalvarez/kepr/internal/request"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +28,21 @@ import (
 func NewRequestCmd(app *App) *cobra.Command {
 	var approveFlag bool
 	var fromFlag string
+	var flattenFlag bool
+	var expiresFlag string
+	var rejectFlag bool
+	var reasonFlag string
+	var statusFlag bool
+	var waitFlag bool
+	var prFlag bool
+	var timeoutFlag string
+	var dryRunFlag bool
+	var pathFlag string
+	var choosePathFlag bool
+	var pruneFlag bool
+	var olderThanFlag string
+	var exportFlag string
+	var importFlag string
 
 	cmd := &cobra.Command{
 		Use:   "request [path]",
@@ -35,8 +54,77 @@ func NewRequestCmd(app *App) *cobra.Command {
 				return err
 			}
 
+			opts := request.ApproveOptions{Flatten: flattenFlag, DryRun: dryRunFlag, Path: pathFlag, ChoosePath: choosePathFlag}
+			approving := approveFlag || importFlag != ""
+			if dryRunFlag && !approving && !pruneFlag {
+				return fmt.Errorf("--dry-run can only be used with --approve, --import or --prune")
+			}
+			if (pathFlag != "" || choosePathFlag) && !approving {
+				return fmt.Errorf("--path and --choose-path can only be used with --approve or --import")
+			}
+			creating := len(args) > 0 && !approving && !rejectFlag && !statusFlag && !pruneFlag
+			if (waitFlag || prFlag || exportFlag != "" || timeoutFlag != "") && !creating {
+				return fmt.Errorf("--wait, --timeout, --pr and --export can only be used when creating a request")
+			}
+			if reasonFlag != "" && !creating && !rejectFlag {
+				return fmt.Errorf("--reason can only be used when creating or rejecting a request")
+			}
+			if pathFlag != "" && choosePathFlag {
+				return fmt.Errorf("--path and --choose-path are mutually exclusive")
+			}
+			if expiresFlag != "" {
+				if !approving {
+					return fmt.Errorf("--expires can only be used with --approve or --import")
+				}
+				opts.Expires, err = common.ParseDuration(expiresFlag)
+				if err != nil {
+					return err
+				}
+			}
+
+			if olderThanFlag != "" && !pruneFlag {
+				return fmt.Errorf("--older-than can only be used with --prune")
+			}
+
+			if pruneFlag {
+				var olderThan time.Duration
+				if olderThanFlag != "" {
+					olderThan, err = common.ParseDuration(olderThanFlag)
+					if err != nil {
+						return err
+					}
+				}
+				w := request.NewPruneWorkflow(olderThan, dryRunFlag, repoPath, app.GitHub, app.Shell, app.UI)
+				return w.Run(cmd.Context())
+			}
+
+			if statusFlag {
+				w := request.NewStatusWorkflow(repoPath, app.GitHub, app.Shell, app.UI)
+				return w.Run(cmd.Context())
+			}
+
+			if rejectFlag {
+				if approveFlag {
+					return fmt.Errorf("--approve and --reject are mutually exclusive")
+				}
+				if len(args) == 0 {
+					return cmd.Help()
+				}
+				w := request.NewRejectWorkflow(args[0], reasonFlag, repoPath, app.GitHub, app.Shell, app.UI)
+				return w.Run(cmd.Context())
+			}
+
+			if importFlag != "" {
+				if len(args) > 0 || fromFlag != "" {
+					return fmt.Errorf("--import cannot be combined with a request ID or --from")
+				}
+				opts.RequestFile = importFlag
+				w := request.NewApproveWorkflow("", opts, repoPath, app.GitHub, app.Shell, app.UI)
+				return w.Run(cmd.Context())
+			}
+
 			if approveFlag && fromFlag != "" {
-				w := request.NewApproveByEmailWorkflow(fromFlag, repoPath, app.GitHub, app.Shell, app.UI)
+				w := request.NewApproveByEmailWorkflow(fromFlag, opts, repoPath, app.GitHub, app.Shell, app.UI)
 				return w.Run(cmd.Context())
 			}
 
@@ -44,7 +132,7 @@ func NewRequestCmd(app *App) *cobra.Command {
 				if len(args) == 0 {
 					return cmd.Help()
 				}
-				w := request.NewApproveWorkflow(args[0], repoPath, app.GitHub, app.Shell, app.UI)
+				w := request.NewApproveWorkflow(args[0], opts, repoPath, app.GitHub, app.Shell, app.UI)
 				return w.Run(cmd.Context())
 			}
 
@@ -53,13 +141,43 @@ func NewRequestCmd(app *App) *cobra.Command {
 				return w.Run(cmd.Context())
 			}
 
-			w := request.NewWorkflow(args[0], repoPath, app.GitHub, app.Shell, app.UI)
+			requestOpts := request.RequestOptions{Reason: reasonFlag, PullRequest: prFlag, Wait: waitFlag, Export: exportFlag}
+			if exportFlag != "" && (prFlag || waitFlag) {
+				return fmt.Errorf("--export cannot be combined with --pr or --wait")
+			}
+			if timeoutFlag != "" {
+				if !waitFlag {
+					return fmt.Errorf("--timeout can only be used with --wait")
+				}
+				requestOpts.Timeout, err = common.ParseDuration(timeoutFlag)
+				if err != nil {
+					return err
+				}
+			}
+
+			w := request.NewWorkflow(args[0], requestOpts, repoPath, app.GitHub, app.Shell, app.UI)
 			return w.Run(cmd.Context())
 		},
 	}
 
 	cmd.Flags().BoolVar(&approveFlag, "approve", false, "approve a pending request")
 	cmd.Flags().StringVar(&fromFlag, "from", "", "approve all requests from the given email (use with --approve)")
+	cmd.Flags().BoolVar(&flattenFlag, "flatten", false, "replace sub-folder recipients with the approved path's recipients (use with --approve)")
+	cmd.Flags().StringVar(&expiresFlag, "expires", "", "revoke the grant after this long, e.g. 14d, 2w or 12h (use with --approve)")
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "show what approving would re-encrypt, or which requests --prune would delete, without changing anything")
+	cmd.Flags().StringVar(&pathFlag, "path", "", "grant access to this path instead of the requested one (use with --approve)")
+	cmd.Flags().BoolVar(&choosePathFlag, "choose-path", false, "pick the granted path among the requested path's subdirectories (use with --approve)")
+	cmd.Flags().BoolVar(&rejectFlag, "reject", false, "reject a pending request")
+	cmd.Flags().StringVar(&reasonFlag, "reason", "", "justification for a new request, or the reason shown to the requester with --reject")
+	cmd.Flags().BoolVar(&statusFlag, "status", false, "show whether this machine's requests are pending, approved, rejected or closed")
+	cmd.Flags().BoolVar(&prFlag, "pr", false, "open a GitHub pull request for the new request")
+	cmd.Flags().BoolVar(&waitFlag, "wait", false, "after requesting, wait until access is granted")
+	cmd.Flags().StringVar(&exportFlag, "export", "", "write the new request to this file instead of pushing it, for machines without GitHub access")
+	cmd.Flags().StringVar(&importFlag, "import", "", "approve a request file written with --export")
+	cmd.Flags().StringVar(&timeoutFlag, "timeout", "", "give up waiting after this long, e.g. 30m (use with --wait)")
+
+	cmd.Flags().BoolVar(&pruneFlag, "prune", false, "delete duplicate requests for the same machine and path, keeping the newest")
+	cmd.Flags().StringVar(&olderThanFlag, "older-than", "", "also prune requests older than this, e.g. 7d (use with --prune)")
 
 	return cmd
 }
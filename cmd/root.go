@@ -28,6 +28,7 @@ import (
 
 var (
 	debugMode    bool
+	offlineMode  bool
 	repoFlag     string
 	resolvedRepo string
 )
@@ -47,6 +48,8 @@ func NewRootCmd(app *App) *cobra.Command {
 			}
 			slog.Debug("initialization complete")
 
+			config.SetOffline(offlineMode)
+
 			resolvedRepo = resolveRepo()
 			slog.Debug("resolved repo", "repo", resolvedRepo)
 
@@ -55,6 +58,7 @@ func NewRootCmd(app *App) *cobra.Command {
 	}
 
 	rootCmd.PersistentFlags().BoolVarP(&debugMode, "debug", "d", false, "enable debug logging")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "work from the local store without contacting GitHub")
 	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "r", "", "repository to use (owner/repo)")
 
 	rootCmd.AddCommand(NewInitCmd(app))
@@ -62,6 +66,11 @@ func NewRootCmd(app *App) *cobra.Command {
 	rootCmd.AddCommand(NewGetCmd(app))
 	rootCmd.AddCommand(NewListCmd(app))
 	rootCmd.AddCommand(NewRequestCmd(app))
+	rootCmd.AddCommand(NewAccessCmd(app))
+	rootCmd.AddCommand(NewGroupCmd(app))
+	rootCmd.AddCommand(NewBundleCmd(app))
+	rootCmd.AddCommand(NewAuditCmd(app))
+	rootCmd.AddCommand(NewSyncCmd(app))
 
 	return rootCmd
 }
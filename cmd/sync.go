@@ -0,0 +1,38 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"github.com/gonzaloalvarez/kepr/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+func NewSyncCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Pull remote changes and push local commits that have not reached the remote",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoPath, err := RequireRepo()
+			if err != nil {
+				return err
+			}
+			w := sync.NewWorkflow(repoPath, app.GitHub, app.Shell, app.UI)
+			return w.Run(cmd.Context())
+		},
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	ExpireStateStart     workflow.State = "expire_start"
+	ExpireStateValidated workflow.State = "expire_validated"
+	ExpireStatePulled    workflow.State = "expire_pulled"
+	ExpireStateRevoked   workflow.State = "expire_revoked"
+	ExpireStatePushed    workflow.State = "expire_pushed"
+	ExpireStateComplete  workflow.State = "expire_complete"
+
+	ExpireTriggerValidate   workflow.Trigger = "expire_validate"
+	ExpireTriggerPull       workflow.Trigger = "expire_pull"
+	ExpireTriggerRevoke     workflow.Trigger = "expire_revoke"
+	ExpireTriggerCommitPush workflow.Trigger = "expire_commit_push"
+	ExpireTriggerComplete   workflow.Trigger = "expire_complete"
+)
+
+type ExpireContext struct {
+	Context
+	Revoked []store.Expiration
+}
+
+func (c *ExpireContext) stepRevoke() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "revoke",
+		Execute: func(ctx context.Context) error {
+			expirations, err := store.LoadExpirations(c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			due := expirations.Due(now)
+			if len(due) == 0 {
+				c.UI.Infofln("No expired grants")
+				return nil
+			}
+
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			for _, grant := range due {
+				if _, err := s.ResolvePath(grant.Path); err != nil {
+					c.UI.Warning(fmt.Sprintf("Dropping expiry for %s on %s: path no longer exists", grant.Fingerprint, grant.Path))
+					expirations.Remove(grant.Fingerprint, grant.Path)
+					continue
+				}
+
+				if pending := notDue(expirations.Overlapping(grant), now); len(pending) > 0 {
+					c.UI.Warning(fmt.Sprintf("Keeping %s on %s: it overlaps a grant on %s that expires %s", grant.Fingerprint, grant.Path, pending[0].Path, pending[0].Expires.Format(time.RFC3339)))
+					continue
+				}
+
+				c.UI.Infofln("Revoking %s from %s", grant.Fingerprint, grant.Path)
+				if err := s.RevokeGrant(grant); err != nil {
+					return fmt.Errorf("failed to revoke %s from %s: %w", grant.Fingerprint, grant.Path, err)
+				}
+				expirations.Remove(grant.Fingerprint, grant.Path)
+				c.Revoked = append(c.Revoked, grant)
+			}
+
+			if err := store.SaveExpirations(c.SecretsPath, expirations); err != nil {
+				return err
+			}
+
+			for _, grant := range c.Revoked {
+				c.UI.Successfln("Revoked %s from %s (expired %s)", grant.Fingerprint, grant.Path, grant.Expires.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+}
+
+// notDue returns the grants that have not expired by now.
+func notDue(grants []store.Expiration, now time.Time) []store.Expiration {
+	var pending []store.Expiration
+	for _, g := range grants {
+		if g.Expires.After(now) {
+			pending = append(pending, g)
+		}
+	}
+	return pending
+}
+
+func (c *ExpireContext) stepCommitAndPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "commit_and_push",
+		Execute: func(ctx context.Context) error {
+			if len(c.Revoked) == 0 {
+				return nil
+			}
+
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			message := fmt.Sprintf("Revoke %d expired grant(s)", len(c.Revoked))
+			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to commit: %w", err)
+			}
+
+			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+
+			c.UI.Successfln("Committed and pushed to main")
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func NewExpireWorkflow(repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &ExpireContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+		},
+	}
+
+	w := workflow.New(ExpireStateStart)
+
+	w.Configure(ExpireStateStart).
+		Permit(ExpireTriggerValidate, ExpireStateValidated)
+
+	w.Configure(ExpireStateValidated).
+		OnEntryFrom(ExpireTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(ExpireTriggerPull, ExpireStatePulled)
+
+	w.Configure(ExpireStatePulled).
+		OnEntryFrom(ExpireTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(ExpireTriggerRevoke, ExpireStateRevoked)
+
+	w.Configure(ExpireStateRevoked).
+		OnEntryFrom(ExpireTriggerRevoke, entryWithRetry(c.stepRevoke())).
+		Permit(ExpireTriggerCommitPush, ExpireStatePushed)
+
+	w.Configure(ExpireStatePushed).
+		OnEntryFrom(ExpireTriggerCommitPush, entryWithRetry(c.stepCommitAndPush())).
+		Permit(ExpireTriggerComplete, ExpireStateComplete)
+
+	w.Configure(ExpireStateComplete)
+
+	w.AddTrigger(ExpireTriggerValidate)
+	w.AddTrigger(ExpireTriggerPull)
+	w.AddTrigger(ExpireTriggerRevoke)
+	w.AddTrigger(ExpireTriggerCommitPush)
+	w.AddTrigger(ExpireTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,225 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	GrantStateStart       workflow.State = "grant_start"
+	GrantStateValidated   workflow.State = "grant_validated"
+	GrantStatePulled      workflow.State = "grant_pulled"
+	GrantStateKeyImported workflow.State = "grant_key_imported"
+	GrantStateRekeyed     workflow.State = "grant_rekeyed"
+	GrantStatePushed      workflow.State = "grant_pushed"
+	GrantStateComplete    workflow.State = "grant_complete"
+
+	GrantTriggerValidate   workflow.Trigger = "grant_validate"
+	GrantTriggerPull       workflow.Trigger = "grant_pull"
+	GrantTriggerImportKey  workflow.Trigger = "grant_import_key"
+	GrantTriggerRekey      workflow.Trigger = "grant_rekey"
+	GrantTriggerCommitPush workflow.Trigger = "grant_commit_push"
+	GrantTriggerComplete   workflow.Trigger = "grant_complete"
+)
+
+type GrantContext struct {
+	Context
+	KeyRef           string
+	Secret           string
+	Flatten          bool
+	GrantFingerprint string
+}
+
+func (c *GrantContext) stepImportKey() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "import_key",
+		Execute: func(ctx context.Context) error {
+			if store.IsGroupRef(c.KeyRef) {
+				s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+				if err != nil {
+					return fmt.Errorf("failed to create store: %w", err)
+				}
+				if _, err := s.ReadGroup(strings.TrimPrefix(c.KeyRef, store.GroupPrefix)); err != nil {
+					return fmt.Errorf("failed to read group: %w", err)
+				}
+				c.GrantFingerprint = c.KeyRef
+				return nil
+			}
+
+			fingerprint, err := c.importKey(c.KeyRef)
+			if err != nil {
+				return err
+			}
+			c.GrantFingerprint = fingerprint
+			return nil
+		},
+	}
+}
+
+func (c *GrantContext) stepRekey() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "rekey",
+		Execute: func(ctx context.Context) error {
+			if err := c.checkQuorum(); err != nil {
+				return err
+			}
+
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			if c.Secret != "" {
+				c.UI.Infofln("Rekeying secret %s", c.Secret)
+				if err := s.AddSecretRecipient(c.Secret, c.GrantFingerprint); err != nil {
+					return fmt.Errorf("failed to rekey: %w", err)
+				}
+				if err := s.KeepPermanentGrant(c.Secret, c.GrantFingerprint, true); err != nil {
+					return fmt.Errorf("failed to update expirations: %w", err)
+				}
+				c.UI.Successfln("Rekeying complete")
+				return nil
+			}
+
+			c.UI.Infofln("Rekeying %s and subfolders", c.Path)
+			if err := s.AddRecipient(c.Path, c.GrantFingerprint, c.Flatten); err != nil {
+				return fmt.Errorf("failed to rekey: %w", err)
+			}
+			if err := s.KeepPermanentGrant(c.Path, c.GrantFingerprint, false); err != nil {
+				return fmt.Errorf("failed to update expirations: %w", err)
+			}
+
+			c.UI.Successfln("Rekeying complete")
+			return nil
+		},
+	}
+}
+
+// checkQuorum refuses a direct grant when the policy needs more than one
+// admin to approve access anywhere in the affected subtree. Such grants must
+// go through an access request so the other admins can sign off.
+func (c *GrantContext) checkQuorum() error {
+	policy, err := store.LoadPolicy(c.SecretsPath)
+	if err != nil {
+		return err
+	}
+
+	target := c.Path
+	if c.Secret != "" {
+		target = c.Secret
+	}
+	if required := policy.RequiredApprovals(target); required > 1 {
+		return fmt.Errorf("%s requires %d approvals; have the user run `kepr request %s` so other admins can approve it", target, required, target)
+	}
+	return nil
+}
+
+func (c *GrantContext) stepCommitAndPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "commit_and_push",
+		Execute: func(ctx context.Context) error {
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			target := c.Path
+			if c.Secret != "" {
+				target = c.Secret
+			}
+
+			message := fmt.Sprintf("Grant access to %s for %s", target, c.GrantFingerprint)
+			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to commit: %w", err)
+			}
+
+			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+
+			c.UI.Successfln("Granted %s access to %s", c.GrantFingerprint, target)
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func NewGrantWorkflow(path, secret, keyRef string, flatten bool, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &GrantContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+			Path:     path,
+		},
+		KeyRef:  keyRef,
+		Secret:  secret,
+		Flatten: flatten,
+	}
+
+	w := workflow.New(GrantStateStart)
+
+	w.Configure(GrantStateStart).
+		Permit(GrantTriggerValidate, GrantStateValidated)
+
+	w.Configure(GrantStateValidated).
+		OnEntryFrom(GrantTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(GrantTriggerPull, GrantStatePulled)
+
+	w.Configure(GrantStatePulled).
+		OnEntryFrom(GrantTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(GrantTriggerImportKey, GrantStateKeyImported)
+
+	w.Configure(GrantStateKeyImported).
+		OnEntryFrom(GrantTriggerImportKey, entryWithRetry(c.stepImportKey())).
+		Permit(GrantTriggerRekey, GrantStateRekeyed)
+
+	w.Configure(GrantStateRekeyed).
+		OnEntryFrom(GrantTriggerRekey, entryWithRetry(c.stepRekey())).
+		Permit(GrantTriggerCommitPush, GrantStatePushed)
+
+	w.Configure(GrantStatePushed).
+		OnEntryFrom(GrantTriggerCommitPush, entryWithRetry(c.stepCommitAndPush())).
+		Permit(GrantTriggerComplete, GrantStateComplete)
+
+	w.Configure(GrantStateComplete)
+
+	w.AddTrigger(GrantTriggerValidate)
+	w.AddTrigger(GrantTriggerPull)
+	w.AddTrigger(GrantTriggerImportKey)
+	w.AddTrigger(GrantTriggerRekey)
+	w.AddTrigger(GrantTriggerCommitPush)
+	w.AddTrigger(GrantTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,274 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	GroupStateStart       workflow.State = "group_start"
+	GroupStateValidated   workflow.State = "group_validated"
+	GroupStatePulled      workflow.State = "group_pulled"
+	GroupStateKeyImported workflow.State = "group_key_imported"
+	GroupStateUpdated     workflow.State = "group_updated"
+	GroupStateRekeyed     workflow.State = "group_rekeyed"
+	GroupStatePushed      workflow.State = "group_pushed"
+	GroupStateComplete    workflow.State = "group_complete"
+
+	GroupTriggerValidate   workflow.Trigger = "group_validate"
+	GroupTriggerPull       workflow.Trigger = "group_pull"
+	GroupTriggerImportKey  workflow.Trigger = "group_import_key"
+	GroupTriggerUpdate     workflow.Trigger = "group_update"
+	GroupTriggerRekey      workflow.Trigger = "group_rekey"
+	GroupTriggerCommitPush workflow.Trigger = "group_commit_push"
+	GroupTriggerComplete   workflow.Trigger = "group_complete"
+)
+
+type GroupContext struct {
+	Context
+	Group  string
+	KeyRef string
+	Remove bool
+	Member string
+}
+
+func (c *GroupContext) stepImportKey() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "import_key",
+		Execute: func(ctx context.Context) error {
+			if err := store.ValidateGroupName(c.Group); err != nil {
+				return err
+			}
+
+			if c.Remove {
+				member, err := normalizeFingerprint(c.KeyRef)
+				if err != nil {
+					return err
+				}
+				c.Member = member
+				return nil
+			}
+
+			fingerprint, err := c.importKey(c.KeyRef)
+			if err != nil {
+				return err
+			}
+			c.Member = fingerprint
+			return nil
+		},
+	}
+}
+
+func (c *GroupContext) stepUpdateGroup() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "update_group",
+		Execute: func(ctx context.Context) error {
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			members, err := s.ReadGroup(c.Group)
+			switch {
+			case err == nil:
+			case errors.Is(err, store.ErrUnsignedGroup) || errors.Is(err, store.ErrUntrustedGroup):
+				members, err = c.confirmResign(s, err)
+				if err != nil {
+					return err
+				}
+			case !c.Remove && errors.Is(err, store.ErrGroupNotFound):
+				c.UI.Infofln("Creating group @%s", c.Group)
+			default:
+				return fmt.Errorf("failed to read group: %w", err)
+			}
+
+			var updated []string
+			found := false
+			for _, fp := range members {
+				if fp == c.Member {
+					found = true
+					if c.Remove {
+						continue
+					}
+				}
+				updated = append(updated, fp)
+			}
+
+			if c.Remove && !found {
+				return fmt.Errorf("%s is not a member of @%s", c.Member, c.Group)
+			}
+			if !c.Remove {
+				if found {
+					return fmt.Errorf("%s is already a member of @%s", c.Member, c.Group)
+				}
+				updated = append(updated, c.Member)
+			}
+			if len(updated) == 0 {
+				return fmt.Errorf("refusing to remove the last member of @%s", c.Group)
+			}
+
+			if err := s.WriteGroup(c.Group, updated); err != nil {
+				return fmt.Errorf("failed to write group: %w", err)
+			}
+
+			c.UI.Successfln("Updated group @%s", c.Group)
+			return nil
+		},
+	}
+}
+
+// confirmResign shows the members of a group that failed verification and
+// asks the admin to vouch for them, so the rewritten group carries their
+// signature.
+func (c *GroupContext) confirmResign(s *store.Store, verifyErr error) ([]string, error) {
+	members, err := s.ReadUnverifiedGroup(c.Group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group: %w", err)
+	}
+
+	c.UI.Warning(verifyErr.Error())
+	for _, fp := range members {
+		c.UI.Infofln("  %s", fp)
+	}
+	ok, err := c.UI.Confirm(fmt.Sprintf("Sign @%s with these members?", c.Group))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("group @%s was not changed", c.Group)
+	}
+	return members, nil
+}
+
+func (c *GroupContext) stepRekey() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "rekey",
+		Execute: func(ctx context.Context) error {
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			c.UI.Infofln("Rekeying directories that reference @%s", c.Group)
+			if err := s.RekeyGroup(c.Group); err != nil {
+				return fmt.Errorf("failed to rekey: %w", err)
+			}
+
+			c.UI.Successfln("Rekeying complete")
+			return nil
+		},
+	}
+}
+
+func (c *GroupContext) stepCommitAndPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "commit_and_push",
+		Execute: func(ctx context.Context) error {
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			message := fmt.Sprintf("Add %s to group %s", c.Member, c.Group)
+			if c.Remove {
+				message = fmt.Sprintf("Remove %s from group %s", c.Member, c.Group)
+			}
+			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to commit: %w", err)
+			}
+
+			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+
+			c.UI.Successfln("Committed and pushed to main")
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func NewGroupWorkflow(group, keyRef string, remove bool, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &GroupContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+		},
+		Group:  strings.TrimPrefix(group, store.GroupPrefix),
+		KeyRef: keyRef,
+		Remove: remove,
+	}
+
+	w := workflow.New(GroupStateStart)
+
+	w.Configure(GroupStateStart).
+		Permit(GroupTriggerValidate, GroupStateValidated)
+
+	w.Configure(GroupStateValidated).
+		OnEntryFrom(GroupTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(GroupTriggerPull, GroupStatePulled)
+
+	w.Configure(GroupStatePulled).
+		OnEntryFrom(GroupTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(GroupTriggerImportKey, GroupStateKeyImported)
+
+	w.Configure(GroupStateKeyImported).
+		OnEntryFrom(GroupTriggerImportKey, entryWithRetry(c.stepImportKey())).
+		Permit(GroupTriggerUpdate, GroupStateUpdated)
+
+	w.Configure(GroupStateUpdated).
+		OnEntryFrom(GroupTriggerUpdate, entryWithRetry(c.stepUpdateGroup())).
+		Permit(GroupTriggerRekey, GroupStateRekeyed)
+
+	w.Configure(GroupStateRekeyed).
+		OnEntryFrom(GroupTriggerRekey, entryWithRetry(c.stepRekey())).
+		Permit(GroupTriggerCommitPush, GroupStatePushed)
+
+	w.Configure(GroupStatePushed).
+		OnEntryFrom(GroupTriggerCommitPush, entryWithRetry(c.stepCommitAndPush())).
+		Permit(GroupTriggerComplete, GroupStateComplete)
+
+	w.Configure(GroupStateComplete)
+
+	w.AddTrigger(GroupTriggerValidate)
+	w.AddTrigger(GroupTriggerPull)
+	w.AddTrigger(GroupTriggerImportKey)
+	w.AddTrigger(GroupTriggerUpdate)
+	w.AddTrigger(GroupTriggerRekey)
+	w.AddTrigger(GroupTriggerCommitPush)
+	w.AddTrigger(GroupTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+func loadIdentities(secretsPath string, g *gpg.GPG) map[string]gpg.GPGKey {
+	keysDir := filepath.Join(secretsPath, "keys")
+	entries, err := os.ReadDir(keysDir)
+	if err != nil {
+		slog.Debug("failed to read keys directory", "path", keysDir, "error", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".key") {
+			continue
+		}
+		keyData, err := os.ReadFile(filepath.Join(keysDir, entry.Name()))
+		if err != nil {
+			slog.Debug("failed to read public key, skipping", "name", entry.Name(), "error", err)
+			continue
+		}
+		if err := g.ImportPublicKey(keyData); err != nil {
+			slog.Debug("failed to import public key, skipping", "name", entry.Name(), "error", err)
+		}
+	}
+
+	identities := make(map[string]gpg.GPGKey)
+	keys, err := g.ListPublicKeys()
+	if err != nil {
+		slog.Debug("failed to list public keys", "error", err)
+		return identities
+	}
+
+	for _, k := range keys {
+		if _, ok := identities[k.Fingerprint]; !ok {
+			identities[k.Fingerprint] = k
+		}
+	}
+
+	return identities
+}
+
+func describeIdentity(identities map[string]gpg.GPGKey, fingerprint string) string {
+	k, ok := identities[fingerprint]
+	if !ok {
+		return "unknown"
+	}
+	if k.Email == "" {
+		return k.UserID
+	}
+	return k.Name + " <" + k.Email + ">"
+}
@@ -0,0 +1,239 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	QuorumStateStart     workflow.State = "quorum_start"
+	QuorumStateValidated workflow.State = "quorum_validated"
+	QuorumStatePulled    workflow.State = "quorum_pulled"
+	QuorumStateUpdated   workflow.State = "quorum_updated"
+	QuorumStatePushed    workflow.State = "quorum_pushed"
+	QuorumStateComplete  workflow.State = "quorum_complete"
+
+	QuorumTriggerValidate   workflow.Trigger = "quorum_validate"
+	QuorumTriggerPull       workflow.Trigger = "quorum_pull"
+	QuorumTriggerUpdate     workflow.Trigger = "quorum_update"
+	QuorumTriggerCommitPush workflow.Trigger = "quorum_commit_push"
+	QuorumTriggerComplete   workflow.Trigger = "quorum_complete"
+)
+
+type QuorumContext struct {
+	Context
+	Approvals int
+	// Applied is set once policy.json holds the new rule. When the current
+	// policy needs several admins to change it, earlier runs only record
+	// their signed approval.
+	Applied bool
+}
+
+func (c *QuorumContext) stepUpdatePolicy() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "update_policy",
+		Execute: func(ctx context.Context) error {
+			normalized, err := store.NormalizePath(c.Path)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			c.Path = normalized
+
+			policy, err := store.LoadPolicy(c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			required := policy.ChangeApprovals()
+			policy.SetQuorum(c.Path, c.Approvals)
+
+			if required > 1 {
+				approvers, err := c.approvePolicy(policy, required)
+				if err != nil {
+					return err
+				}
+				if approvers < required {
+					c.UI.Successfln("Recorded approval %d of %d for this policy change", approvers, required)
+					c.UI.Infofln("Another admin must run `kepr access quorum %s %d` before it takes effect", c.Path, c.Approvals)
+					return nil
+				}
+			}
+
+			if err := store.SavePolicy(c.SecretsPath, policy); err != nil {
+				return err
+			}
+			c.Applied = true
+
+			c.UI.Successfln("Requests for %s now need %d approval(s)", c.Path, c.Approvals)
+			return nil
+		},
+	}
+}
+
+// approvePolicy records this admin's signed approval of the new policy in
+// approvals/ and returns how many distinct admins have approved it.
+func (c *QuorumContext) approvePolicy(policy *store.Policy, required int) (int, error) {
+	s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create store: %w", err)
+	}
+
+	admins, err := s.ReadRecipients(c.SecretsPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read root recipients: %w", err)
+	}
+	if !slices.Contains(admins, c.Fingerprint) {
+		return 0, fmt.Errorf("only root recipients can change the approval policy")
+	}
+
+	data, err := store.MarshalPolicy(policy)
+	if err != nil {
+		return 0, err
+	}
+	changeID := store.PolicyChangeID(data)
+
+	files, err := store.ReadApprovals(c.SecretsPath)
+	if err != nil {
+		return 0, err
+	}
+	approvers := store.VerifiedApprovers(c.GPG, files, changeID, store.PolicyFile, admins)
+	if slices.Contains(approvers, c.Fingerprint) {
+		c.UI.Infofln("You already approved this policy change (%d of %d approvals)", len(approvers), required)
+		return len(approvers), nil
+	}
+
+	if err := c.GPG.RequireSigningKey(c.Fingerprint); err != nil {
+		return 0, err
+	}
+	publicKey, err := c.GPG.ExportPublicKey(c.Fingerprint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to export public key: %w", err)
+	}
+	signed, err := store.SignApproval(c.GPG, store.Approval{
+		RequestUUID: changeID,
+		Approver:    c.Fingerprint,
+		Path:        store.PolicyFile,
+		PublicKey:   string(publicKey),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	dir := filepath.Join(c.SecretsPath, store.ApprovalsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create approvals directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, store.ApprovalFileName(changeID, c.Fingerprint)), signed, 0600); err != nil {
+		return 0, fmt.Errorf("failed to write approval: %w", err)
+	}
+	if err := store.EnsureGitignore(c.SecretsPath); err != nil {
+		return 0, err
+	}
+
+	return len(approvers) + 1, nil
+}
+
+func (c *QuorumContext) stepCommitAndPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "commit_and_push",
+		Execute: func(ctx context.Context) error {
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			message := fmt.Sprintf("Require %d approval(s) for %s", c.Approvals, c.Path)
+			if !c.Applied {
+				message = fmt.Sprintf("Approve requiring %d approval(s) for %s", c.Approvals, c.Path)
+			}
+			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to commit: %w", err)
+			}
+
+			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+
+			c.UI.Successfln("Committed and pushed to main")
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func NewQuorumWorkflow(path string, approvals int, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &QuorumContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+			Path:     path,
+		},
+		Approvals: approvals,
+	}
+
+	w := workflow.New(QuorumStateStart)
+
+	w.Configure(QuorumStateStart).
+		Permit(QuorumTriggerValidate, QuorumStateValidated)
+
+	w.Configure(QuorumStateValidated).
+		OnEntryFrom(QuorumTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(QuorumTriggerPull, QuorumStatePulled)
+
+	w.Configure(QuorumStatePulled).
+		OnEntryFrom(QuorumTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(QuorumTriggerUpdate, QuorumStateUpdated)
+
+	w.Configure(QuorumStateUpdated).
+		OnEntryFrom(QuorumTriggerUpdate, entryWithRetry(c.stepUpdatePolicy())).
+		Permit(QuorumTriggerCommitPush, QuorumStatePushed)
+
+	w.Configure(QuorumStatePushed).
+		OnEntryFrom(QuorumTriggerCommitPush, entryWithRetry(c.stepCommitAndPush())).
+		Permit(QuorumTriggerComplete, QuorumStateComplete)
+
+	w.Configure(QuorumStateComplete)
+
+	w.AddTrigger(QuorumTriggerValidate)
+	w.AddTrigger(QuorumTriggerPull)
+	w.AddTrigger(QuorumTriggerUpdate)
+	w.AddTrigger(QuorumTriggerCommitPush)
+	w.AddTrigger(QuorumTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,317 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	ReportStateStart     workflow.State = "report_start"
+	ReportStateValidated workflow.State = "report_validated"
+	ReportStatePulled    workflow.State = "report_pulled"
+	ReportStateWritten   workflow.State = "report_written"
+	ReportStateComplete  workflow.State = "report_complete"
+
+	ReportTriggerValidate workflow.Trigger = "report_validate"
+	ReportTriggerPull     workflow.Trigger = "report_pull"
+	ReportTriggerWrite    workflow.Trigger = "report_write"
+	ReportTriggerComplete workflow.Trigger = "report_complete"
+)
+
+// Roles are derived from the store itself: admins are the fingerprints
+// listed in the root .gpg.id, everyone else is a recipient further down.
+const (
+	RoleAdmin     = "admin"
+	RoleRecipient = "recipient"
+)
+
+// Kinds are derived from the store's records: machine keys are those granted
+// access through an access request, as recorded in approvals/ or
+// expirations.json. Every other key, admins included, is a human key.
+const (
+	KindHuman   = "human"
+	KindMachine = "machine"
+)
+
+var ReportFormats = []string{"csv", "json", "markdown"}
+
+type ReportRow struct {
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+	Group       string `json:"group,omitempty"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	Kind        string `json:"kind"`
+	MissingKey  bool   `json:"missing_key"`
+	// Accessible is false for directories this key cannot decrypt; their
+	// Path shows the directory's UUID instead of its name.
+	Accessible bool `json:"accessible"`
+}
+
+type ReportContext struct {
+	Context
+	Format     string
+	OutputPath string
+}
+
+func ValidReportFormat(format string) bool {
+	for _, f := range ReportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ReportContext) stepWriteReport() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "write_report",
+		Execute: func(ctx context.Context) error {
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			acls, err := s.ListACLs("", true)
+			if err != nil {
+				return fmt.Errorf("failed to read recipients: %w", err)
+			}
+
+			rootFingerprints, err := s.ReadRecipients(c.SecretsPath)
+			if err != nil {
+				return fmt.Errorf("failed to read root .gpg.id: %w", err)
+			}
+			admins := make(map[string]bool, len(rootFingerprints))
+			for _, fp := range rootFingerprints {
+				admins[fp] = true
+			}
+
+			machines, err := requestedKeys(c.SecretsPath, c.GPG, rootFingerprints)
+			if err != nil {
+				return err
+			}
+
+			identities := loadIdentities(c.SecretsPath, c.GPG)
+
+			var rows []ReportRow
+			addRows := func(path string, accessible bool, entries []string) {
+				for _, member := range expandForReport(s, entries) {
+					fp := member.fingerprint
+					row := ReportRow{
+						Path:        path,
+						Fingerprint: fp,
+						Group:       member.group,
+						Role:        RoleRecipient,
+						Kind:        KindHuman,
+						Accessible:  accessible,
+					}
+					if admins[fp] {
+						row.Role = RoleAdmin
+					} else if machines[fp] {
+						row.Kind = KindMachine
+					}
+					if k, ok := identities[fp]; ok {
+						row.Name = k.Name
+						row.Email = k.Email
+					}
+					if _, err := os.Stat(store.KeyPath(c.SecretsPath, fp)); err != nil {
+						row.MissingKey = true
+					}
+					rows = append(rows, row)
+				}
+			}
+
+			for _, acl := range acls {
+				addRows(store.DisplayPath(acl.Path), acl.Accessible, acl.Fingerprints)
+				for _, secret := range acl.Secrets {
+					addRows(path.Join(store.DisplayPath(acl.Path), secret.Name), acl.Accessible, secret.Fingerprints)
+				}
+			}
+
+			if c.OutputPath == "" {
+				return writeReport(os.Stdout, c.Format, rows)
+			}
+
+			f, err := os.OpenFile(c.OutputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create report file: %w", err)
+			}
+			defer f.Close()
+
+			if err := writeReport(f, c.Format, rows); err != nil {
+				return err
+			}
+
+			c.UI.Successfln("Access report written to %s", c.OutputPath)
+			return nil
+		},
+	}
+}
+
+// requestedKeys returns the fingerprints granted access through an access
+// request: requesters named in approvals signed by an admin, and keys with an
+// expiring grant, which only approvals create.
+func requestedKeys(secretsPath string, g *gpg.GPG, admins []string) (map[string]bool, error) {
+	files, err := store.ReadApprovals(secretsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approvals: %w", err)
+	}
+	keys := store.RequestedKeys(g, files, admins)
+
+	expirations, err := store.LoadExpirations(secretsPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range expirations.Grants {
+		keys[grant.Fingerprint] = true
+	}
+	return keys, nil
+}
+
+type reportMember struct {
+	fingerprint string
+	group       string
+}
+
+func expandForReport(s *store.Store, entries []string) []reportMember {
+	var members []reportMember
+	for _, entry := range entries {
+		if !store.IsGroupRef(entry) {
+			members = append(members, reportMember{fingerprint: entry})
+			continue
+		}
+
+		fps, err := s.ReadGroup(strings.TrimPrefix(entry, store.GroupPrefix))
+		if err != nil {
+			slog.Debug("failed to expand group for report", "group", entry, "error", err)
+			members = append(members, reportMember{fingerprint: entry, group: entry})
+			continue
+		}
+		for _, fp := range fps {
+			members = append(members, reportMember{fingerprint: fp, group: entry})
+		}
+	}
+	return members
+}
+
+func writeReport(w io.Writer, format string, rows []ReportRow) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"path", "fingerprint", "group", "name", "email", "role", "kind", "missing_key", "accessible"}); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		for _, r := range rows {
+			record := []string{r.Path, r.Fingerprint, r.Group, r.Name, r.Email, r.Role, r.Kind, fmt.Sprintf("%t", r.MissingKey), fmt.Sprintf("%t", r.Accessible)}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		if rows == nil {
+			rows = []ReportRow{}
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "markdown":
+		var b strings.Builder
+		b.WriteString("| Path | Fingerprint | Group | Name | Email | Role | Kind | Missing key | Accessible |\n")
+		b.WriteString("|------|-------------|-------|------|-------|------|------|-------------|------------|\n")
+		for _, r := range rows {
+			missing := ""
+			if r.MissingKey {
+				missing = "yes"
+			}
+			accessible := "yes"
+			if !r.Accessible {
+				accessible = "no"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				markdownEscape(r.Path), r.Fingerprint, r.Group, markdownEscape(r.Name), markdownEscape(r.Email), r.Role, r.Kind, missing, accessible)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func NewReportWorkflow(format, outputPath, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &ReportContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+		},
+		Format:     format,
+		OutputPath: outputPath,
+	}
+
+	w := workflow.New(ReportStateStart)
+
+	w.Configure(ReportStateStart).
+		Permit(ReportTriggerValidate, ReportStateValidated)
+
+	w.Configure(ReportStateValidated).
+		OnEntryFrom(ReportTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(ReportTriggerPull, ReportStatePulled)
+
+	w.Configure(ReportStatePulled).
+		OnEntryFrom(ReportTriggerPull, entryWithRetry(c.stepPullForRead())).
+		Permit(ReportTriggerWrite, ReportStateWritten)
+
+	w.Configure(ReportStateWritten).
+		OnEntryFrom(ReportTriggerWrite, entryWithRetry(c.stepWriteReport())).
+		Permit(ReportTriggerComplete, ReportStateComplete)
+
+	w.Configure(ReportStateComplete)
+
+	w.AddTrigger(ReportTriggerValidate)
+	w.AddTrigger(ReportTriggerPull)
+	w.AddTrigger(ReportTriggerWrite)
+	w.AddTrigger(ReportTriggerComplete)
+
+	return w
+}
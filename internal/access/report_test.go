@@ -0,0 +1,163 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+var testReportRows = []ReportRow{
+	{
+		Path:        "prod",
+		Fingerprint: "553D727D2BC9F896DC405B0477ED18083565E063",
+		Name:        "Alice",
+		Email:       "alice@example.com",
+		Role:        RoleAdmin,
+		Kind:        KindHuman,
+		Accessible:  true,
+	},
+	{
+		Path:        "prod/db|replica",
+		Fingerprint: "0F1E2D3C4B5A69788796A5B4C3D2E1F00F1E2D3C",
+		Group:       "@ops",
+		Role:        RoleRecipient,
+		Kind:        KindMachine,
+		MissingKey:  true,
+		Accessible:  true,
+	},
+	{
+		Path:        "prod/2f1c6a0e-4a3b-4c8e-9d1e-7b6a5c4d3e2f",
+		Fingerprint: "553D727D2BC9F896DC405B0477ED18083565E063",
+		Role:        RoleAdmin,
+		Kind:        KindHuman,
+	},
+}
+
+func TestWriteReport_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "csv", testReportRows); err != nil {
+		t.Fatalf("writeReport() failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse csv: %v", err)
+	}
+
+	want := [][]string{
+		{"path", "fingerprint", "group", "name", "email", "role", "kind", "missing_key", "accessible"},
+		{"prod", "553D727D2BC9F896DC405B0477ED18083565E063", "", "Alice", "alice@example.com", "admin", "human", "false", "true"},
+		{"prod/db|replica", "0F1E2D3C4B5A69788796A5B4C3D2E1F00F1E2D3C", "@ops", "", "", "recipient", "machine", "true", "true"},
+		{"prod/2f1c6a0e-4a3b-4c8e-9d1e-7b6a5c4d3e2f", "553D727D2BC9F896DC405B0477ED18083565E063", "", "", "", "admin", "human", "false", "false"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("record %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "json", testReportRows); err != nil {
+		t.Fatalf("writeReport() failed: %v", err)
+	}
+
+	var rows []ReportRow
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("failed to parse json: %v", err)
+	}
+	if len(rows) != len(testReportRows) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(testReportRows))
+	}
+	for i := range rows {
+		if rows[i] != testReportRows[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], testReportRows[i])
+		}
+	}
+	if !strings.Contains(buf.String(), `"accessible": false`) {
+		t.Errorf("expected inaccessible rows to be explicit, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteReport_JSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "json", nil); err != nil {
+		t.Fatalf("writeReport() failed: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("writeReport() = %q, want an empty array", buf.String())
+	}
+}
+
+func TestWriteReport_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "markdown", testReportRows); err != nil {
+		t.Fatalf("writeReport() failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"| Path | Fingerprint | Group | Name | Email | Role | Kind | Missing key | Accessible |",
+		"|------|-------------|-------|------|-------|------|------|-------------|------------|",
+		"| prod | 553D727D2BC9F896DC405B0477ED18083565E063 |  | Alice | alice@example.com | admin | human |  | yes |",
+		"| prod/db\\|replica | 0F1E2D3C4B5A69788796A5B4C3D2E1F00F1E2D3C | @ops |  |  | recipient | machine | yes | yes |",
+		"| prod/2f1c6a0e-4a3b-4c8e-9d1e-7b6a5c4d3e2f | 553D727D2BC9F896DC405B0477ED18083565E063 |  |  |  | admin | human |  | no |",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestRequestedKeys_Expirations(t *testing.T) {
+	secretsPath := t.TempDir()
+	e := &store.Expirations{}
+	e.Set("FP_MACHINE", "prod", time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC), nil)
+	if err := store.SaveExpirations(secretsPath, e); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := requestedKeys(secretsPath, nil, []string{"FP_ADMIN"})
+	if err != nil {
+		t.Fatalf("requestedKeys() failed: %v", err)
+	}
+	if !keys["FP_MACHINE"] || keys["FP_ADMIN"] {
+		t.Errorf("requestedKeys() = %v, want only FP_MACHINE", keys)
+	}
+}
+
+func TestWriteReport_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "xml", testReportRows); err == nil {
+		t.Error("expected writeReport() to reject an unsupported format")
+	}
+}
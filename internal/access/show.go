@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	ShowStateStart     workflow.State = "show_start"
+	ShowStateValidated workflow.State = "show_validated"
+	ShowStatePulled    workflow.State = "show_pulled"
+	ShowStateDisplayed workflow.State = "show_displayed"
+	ShowStateComplete  workflow.State = "show_complete"
+
+	ShowTriggerValidate workflow.Trigger = "show_validate"
+	ShowTriggerPull     workflow.Trigger = "show_pull"
+	ShowTriggerDisplay  workflow.Trigger = "show_display"
+	ShowTriggerComplete workflow.Trigger = "show_complete"
+)
+
+type ShowContext struct {
+	Context
+	Recursive bool
+}
+
+func (c *ShowContext) stepDisplay() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "display",
+		Execute: func(ctx context.Context) error {
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			acls, err := s.ListACLs(c.Path, c.Recursive)
+			if err != nil {
+				return fmt.Errorf("failed to read recipients: %w", err)
+			}
+
+			identities := loadIdentities(c.SecretsPath, c.GPG)
+
+			for _, acl := range acls {
+				header := store.DisplayPath(acl.Path)
+				if !acl.Accessible {
+					header += " (not accessible)"
+				}
+				if acl.Differs {
+					c.UI.Warning(header + " (recipients differ from parent)")
+				} else {
+					c.UI.Infofln("%s", header)
+				}
+
+				c.printEntries(s, identities, acl.Fingerprints, "  ")
+
+				for _, secret := range acl.Secrets {
+					c.UI.Infofln("  secret %s has its own recipients:", secret.Name)
+					c.printEntries(s, identities, secret.Fingerprints, "    ")
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func (c *ShowContext) printEntries(s *store.Store, identities map[string]gpg.GPGKey, entries []string, indent string) {
+	for _, entry := range entries {
+		if !store.IsGroupRef(entry) {
+			c.UI.Infofln("%s%s - %s", indent, entry, describeIdentity(identities, entry))
+			continue
+		}
+
+		members, err := s.ReadGroup(strings.TrimPrefix(entry, store.GroupPrefix))
+		if err != nil {
+			c.UI.Infofln("%s%s - group (members not readable)", indent, entry)
+			continue
+		}
+		c.UI.Infofln("%s%s - group", indent, entry)
+		for _, fp := range members {
+			c.UI.Infofln("%s  %s - %s", indent, fp, describeIdentity(identities, fp))
+		}
+	}
+}
+
+func NewShowWorkflow(path string, recursive bool, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &ShowContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+			Path:     path,
+		},
+		Recursive: recursive,
+	}
+
+	w := workflow.New(ShowStateStart)
+
+	w.Configure(ShowStateStart).
+		Permit(ShowTriggerValidate, ShowStateValidated)
+
+	w.Configure(ShowStateValidated).
+		OnEntryFrom(ShowTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(ShowTriggerPull, ShowStatePulled)
+
+	w.Configure(ShowStatePulled).
+		OnEntryFrom(ShowTriggerPull, entryWithRetry(c.stepPullForRead())).
+		Permit(ShowTriggerDisplay, ShowStateDisplayed)
+
+	w.Configure(ShowStateDisplayed).
+		OnEntryFrom(ShowTriggerDisplay, entryWithRetry(c.stepDisplay())).
+		Permit(ShowTriggerComplete, ShowStateComplete)
+
+	w.Configure(ShowStateComplete)
+
+	w.AddTrigger(ShowTriggerValidate)
+	w.AddTrigger(ShowTriggerPull)
+	w.AddTrigger(ShowTriggerDisplay)
+	w.AddTrigger(ShowTriggerComplete)
+
+	return w
+}
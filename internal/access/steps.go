@@ -0,0 +1,205 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+type Context struct {
+	Shell       shell.Executor
+	UI          cout.IO
+	GitHub      github.Client
+	RepoPath    string
+	Path        string
+	Token       string
+	ConfigDir   string
+	UserName    string
+	UserEmail   string
+	Fingerprint string
+	SecretsPath string
+	GPG         *gpg.GPG
+}
+
+func (c *Context) stepValidate() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "validate",
+		Execute: func(ctx context.Context) error {
+			c.Token = config.GetRepoToken(c.RepoPath)
+			if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
+				return err
+			}
+			c.GitHub.SetToken(c.Token)
+
+			configDir, err := common.ValidateConfigDir()
+			if err != nil {
+				return err
+			}
+			c.ConfigDir = configDir
+
+			userName, userEmail, err := common.ValidateUserIdentity()
+			if err != nil {
+				return err
+			}
+			c.UserName = userName
+			c.UserEmail = userEmail
+
+			g, err := common.ValidateGPGSetup(c.ConfigDir, c.Shell, c.UI)
+			if err != nil {
+				return err
+			}
+			c.GPG = g
+
+			fingerprint, err := common.ValidateFingerprint()
+			if err != nil {
+				return err
+			}
+			c.Fingerprint = fingerprint
+
+			secretsPath, err := common.GetSecretsPath(c.RepoPath)
+			if err != nil {
+				return err
+			}
+			c.SecretsPath = secretsPath
+
+			return nil
+		},
+	}
+}
+
+func (c *Context) stepPull() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "pull",
+		Execute: func(ctx context.Context) error {
+			if err := common.RequireOnline(); err != nil {
+				return err
+			}
+			gitClient := common.NewVerifyingGit(c.Token, c.GPG)
+			if err := gitClient.Pull(c.SecretsPath, "origin", "main", true); err != nil {
+				return fmt.Errorf("failed to pull latest changes: %w", common.SyncHint(err))
+			}
+			c.UI.Successfln("Pulled latest changes from remote")
+			return nil
+		},
+	}
+}
+
+// stepPullForRead is the pull for commands that only read the store, which
+// fall back to the local copy when offline.
+func (c *Context) stepPullForRead() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "pull",
+		Execute: func(ctx context.Context) error {
+			gitClient := common.NewVerifyingGit(c.Token, c.GPG)
+			return common.PullForRead(gitClient, c.RepoPath, c.SecretsPath, c.UI)
+		},
+	}
+}
+
+// importKey accepts either an exported public key file or a fingerprint. A
+// fingerprint is imported from keys/ when present, otherwise it must already
+// be in the local keyring. The key must be able to encrypt, and it is saved
+// to keys/ before any .gpg.id references it.
+func (c *Context) importKey(keyRef string) (string, error) {
+	fingerprint, err := c.resolveKey(keyRef)
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := c.GPG.HasEncryptionKey(fingerprint)
+	if err != nil {
+		return "", fmt.Errorf("key %s is not in keys/ or the local keyring: %w", fingerprint, err)
+	}
+	if !ok {
+		return "", fmt.Errorf("key %s has no usable encryption key", fingerprint)
+	}
+
+	if err := store.SavePublicKey(c.SecretsPath, c.GPG, fingerprint); err != nil {
+		return "", fmt.Errorf("failed to save public key %s: %w", fingerprint, err)
+	}
+	return fingerprint, nil
+}
+
+func (c *Context) resolveKey(keyRef string) (string, error) {
+	keyData, err := os.ReadFile(keyRef)
+	if err == nil {
+		fingerprint, err := c.GPG.ReadKeyFingerprint(keyData)
+		if err != nil {
+			return "", fmt.Errorf("failed to read key file %s: %w", keyRef, err)
+		}
+		if err := c.GPG.ImportPublicKey(keyData); err != nil {
+			return "", fmt.Errorf("failed to import key file %s: %w", keyRef, err)
+		}
+		c.UI.Successfln("Imported public key %s", fingerprint)
+		return fingerprint, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read key file %s: %w", keyRef, err)
+	}
+
+	fingerprint, err := normalizeFingerprint(keyRef)
+	if err != nil {
+		return "", err
+	}
+
+	keyData, err = os.ReadFile(store.KeyPath(c.SecretsPath, fingerprint))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read public key %s: %w", fingerprint, err)
+		}
+		c.UI.Infofln("No key for %s in keys/, using local keyring", fingerprint)
+		return fingerprint, nil
+	}
+
+	if err := c.GPG.ImportPublicKey(keyData); err != nil {
+		return "", fmt.Errorf("failed to import public key %s: %w", fingerprint, err)
+	}
+	c.UI.Successfln("Imported public key %s from keys/", fingerprint)
+	return fingerprint, nil
+}
+
+var fingerprintPattern = regexp.MustCompile(`^[0-9A-F]{40}$`)
+
+// normalizeFingerprint accepts a v4 fingerprint with or without spaces, in
+// either case.
+func normalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+	if !fingerprintPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%q is neither a key file nor a 40 character fingerprint", fingerprint)
+	}
+	return normalized, nil
+}
+
+func entryWithRetry(cfg workflow.StepConfig) func(ctx context.Context, args ...any) error {
+	return func(ctx context.Context, args ...any) error {
+		return workflow.ExecuteWithRetry(ctx, cfg)
+	}
+}
@@ -54,8 +54,8 @@ func (c *Context) stepValidateToken() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "validate_token",
 		Execute: func(ctx context.Context) error {
-			c.Token = config.GetToken()
-			if err := common.ValidateToken(c.Token); err != nil {
+			c.Token = config.GetRepoToken(c.RepoPath)
+			if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
 				return err
 			}
 			c.GitHub.SetToken(c.Token)
@@ -97,7 +97,7 @@ func (c *Context) stepValidateGitHub() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "validate_github",
 		Execute: func(ctx context.Context) error {
-			return common.ValidateGitHubIdentity(c.GitHub, c.UserEmail)
+			return common.CheckIdentity(c.RepoPath, c.GitHub, c.UserEmail, c.UI)
 		},
 	}
 }
@@ -154,7 +154,10 @@ func (c *Context) stepInitStore() workflow.StepConfig {
 			}
 			c.Store = st
 
-			gitClient := git.NewWithAuth(c.Token)
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
 			c.Pass = pass.New(c.SecretsPath, c.GPG, gitClient, c.UI, c.Shell, c.Store)
 			return nil
 		},
@@ -185,9 +188,20 @@ func (c *Context) stepPush() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "push",
 		Execute: func(ctx context.Context) error {
+			if common.IsLocalOnly(c.RepoPath) {
+				return nil
+			}
+			if config.IsOffline() {
+				return c.queuePush()
+			}
 			gitClient := git.NewWithAuth(c.Token)
-			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
-				return fmt.Errorf("failed to push to remote: %w", err)
+			err := gitClient.Push(c.SecretsPath, "origin", "main")
+			if common.IsUnreachable(err) {
+				common.GoOffline(c.UI, err)
+				return c.queuePush()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to push to remote (the change is kept locally; run `kepr sync` to push it later): %w", err)
 			}
 			c.UI.Successfln("Pushed to remote repository")
 			return nil
@@ -200,3 +214,12 @@ func (c *Context) stepPush() workflow.StepConfig {
 		},
 	}
 }
+
+// queuePush leaves the commit in the local store for `kepr sync` to deliver.
+func (c *Context) queuePush() error {
+	if err := config.SetPendingPush(c.RepoPath, true); err != nil {
+		return fmt.Errorf("failed to record pending push: %w", err)
+	}
+	c.UI.Warning("Working offline; the change is committed locally. Run `kepr sync` to push it once back online")
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package audit
+
+import (
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+)
+
+const (
+	StateStart     workflow.State = "start"
+	StateValidated workflow.State = "validated"
+	StatePulled    workflow.State = "pulled"
+	StateCollected workflow.State = "collected"
+	StatePrinted   workflow.State = "printed"
+	StateComplete  workflow.State = "complete"
+)
+
+const (
+	TriggerValidate workflow.Trigger = "validate"
+	TriggerPull     workflow.Trigger = "pull"
+	TriggerCollect  workflow.Trigger = "collect"
+	TriggerPrint    workflow.Trigger = "print"
+	TriggerComplete workflow.Trigger = "complete"
+)
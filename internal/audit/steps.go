@@ -0,0 +1,317 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+var Formats = []string{"table", "json"}
+
+const (
+	SignatureVerified = "verified"
+	SignatureUnsigned = "unsigned"
+	SignatureInvalid  = "invalid"
+)
+
+// Record is one commit in the audit log. Paths are logical paths where this
+// machine can decrypt the metadata, and UUIDs otherwise. Author and Email
+// are whatever the committer claimed; Signer is the fingerprint whose
+// signature on the commit verified, and is empty unless Signature is
+// SignatureVerified.
+type Record struct {
+	Commit    string    `json:"commit"`
+	Time      time.Time `json:"time"`
+	Author    string    `json:"author"`
+	Email     string    `json:"email"`
+	Signer    string    `json:"signer"`
+	Signature string    `json:"signature"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	Paths     []string  `json:"paths"`
+}
+
+type Context struct {
+	Shell       shell.Executor
+	UI          cout.IO
+	GitHub      github.Client
+	RepoPath    string
+	Path        string
+	Since       time.Time
+	Format      string
+	Token       string
+	ReadOnly    bool
+	SecretsPath string
+	GPG         *gpg.GPG
+	Records     []Record
+	imported    map[string]bool
+}
+
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Context) stepValidate() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "validate",
+		Execute: func(ctx context.Context) error {
+			// Store copies fed from bundles never talk to GitHub.
+			if config.IsReadOnlyRepo(c.RepoPath) {
+				c.ReadOnly = true
+			} else {
+				c.Token = config.GetRepoToken(c.RepoPath)
+				if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
+					return err
+				}
+				c.GitHub.SetToken(c.Token)
+			}
+
+			configDir, err := common.ValidateConfigDir()
+			if err != nil {
+				return err
+			}
+			g, err := common.ValidateGPGSetup(configDir, c.Shell, c.UI)
+			if err != nil {
+				return err
+			}
+			c.GPG = g
+
+			secretsPath, err := common.GetSecretsPath(c.RepoPath)
+			if err != nil {
+				return err
+			}
+			c.SecretsPath = secretsPath
+
+			if c.Path != "" {
+				normalized, err := store.NormalizePath(c.Path)
+				if err != nil {
+					return fmt.Errorf("invalid path: %w", err)
+				}
+				c.Path = normalized
+			}
+			return nil
+		},
+	}
+}
+
+func (c *Context) stepPull() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "pull",
+		Execute: func(ctx context.Context) error {
+			if c.ReadOnly {
+				return nil
+			}
+			gitClient := common.NewVerifyingGit(c.Token, c.GPG)
+			return common.PullForRead(gitClient, c.RepoPath, c.SecretsPath, c.UI)
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Pull failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func (c *Context) stepCollect() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "collect",
+		Execute: func(ctx context.Context) error {
+			gitClient := git.New()
+			entries, err := gitClient.Log(c.SecretsPath, c.Since)
+			if err != nil {
+				return fmt.Errorf("failed to read store history: %w", err)
+			}
+
+			resolver := store.NewPathResolver(c.GPG)
+			for _, entry := range entries {
+				if record, ok := c.describe(gitClient, resolver, entry); ok {
+					c.Records = append(c.Records, record)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// describe turns a commit into a record, reporting false when none of its
+// changes fall under the requested path.
+func (c *Context) describe(gitClient *git.Git, resolver *store.PathResolver, entry git.LogEntry) (Record, bool) {
+	var added, modified, deleted []string
+	var paths []string
+	seen := make(map[string]bool)
+	matched := c.Path == ""
+
+	for _, change := range entry.Changes {
+		// Deleted files only exist in the parent, and so may the metadata
+		// of the directories they were in.
+		commits := []string{entry.Hash, entry.Parent}
+		switch change.Action {
+		case git.ChangeAdded:
+			added = append(added, change.Path)
+		case git.ChangeModified:
+			modified = append(modified, change.Path)
+		case git.ChangeDeleted:
+			deleted = append(deleted, change.Path)
+			commits = []string{entry.Parent}
+		}
+
+		read := func(relPath string) []byte {
+			for _, hash := range commits {
+				if hash == "" {
+					continue
+				}
+				if data, err := gitClient.ReadFileAtCommit(c.SecretsPath, hash, relPath); err == nil {
+					return data
+				}
+			}
+			return nil
+		}
+
+		logical := resolver.Resolve(change.Path, read)
+		if !matched {
+			// A recipient change on a parent folder changes who can read
+			// the requested path too.
+			recipients := strings.HasSuffix(path.Base(change.Path), ".gpg.id")
+			matched = store.PathWithin(logical, c.Path) || (recipients && store.PathWithin(c.Path, strings.Trim(logical, "/")))
+		}
+		if !seen[logical] {
+			seen[logical] = true
+			paths = append(paths, logical)
+		}
+	}
+
+	if !matched {
+		return Record{}, false
+	}
+
+	signer, signature := c.verifySigner(gitClient, entry)
+
+	return Record{
+		Commit:    entry.Hash,
+		Time:      entry.When,
+		Author:    entry.Author,
+		Email:     entry.Email,
+		Signer:    signer,
+		Signature: signature,
+		Kind:      store.ClassifyCommit(entry.Message, added, modified, deleted),
+		Message:   entry.Message,
+		Paths:     paths,
+	}, true
+}
+
+// verifySigner checks the commit signature against the keys stored at that
+// commit and returns the signer's fingerprint with the signature status.
+func (c *Context) verifySigner(gitClient *git.Git, entry git.LogEntry) (string, string) {
+	if entry.Signature == "" {
+		return "", SignatureUnsigned
+	}
+
+	keys, err := gitClient.ReadFilesAtCommit(c.SecretsPath, entry.Hash, "keys")
+	if err != nil {
+		slog.Debug("failed to read keys at commit", "commit", entry.Hash, "error", err)
+	}
+	if c.imported == nil {
+		c.imported = make(map[string]bool)
+	}
+	for name, data := range keys {
+		if c.imported[string(data)] {
+			continue
+		}
+		if err := c.GPG.ImportPublicKey(data); err != nil {
+			slog.Debug("failed to import key", "key", name, "error", err)
+			continue
+		}
+		c.imported[string(data)] = true
+	}
+
+	signer, err := c.GPG.VerifyDetached(entry.Payload, []byte(entry.Signature))
+	if err != nil {
+		slog.Debug("commit signature did not verify", "commit", entry.Hash, "error", err)
+		return "", SignatureInvalid
+	}
+	return signer, SignatureVerified
+}
+
+func (c *Context) stepPrint() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "print",
+		Execute: func(ctx context.Context) error {
+			if len(c.Records) == 0 && c.Format == "table" {
+				c.UI.Infofln("No changes found")
+				return nil
+			}
+			return writeRecords(os.Stdout, c.Format, c.Records)
+		},
+	}
+}
+
+// signerColumn shows the verified signer, or flags the commit together with
+// the author it claims.
+func signerColumn(r Record) string {
+	if r.Signature == SignatureVerified {
+		return r.Signer
+	}
+	return fmt.Sprintf("%s (%s)", strings.ToUpper(r.Signature), r.Author)
+}
+
+func writeRecords(w io.Writer, format string, records []Record) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DATE\tSIGNER\tKIND\tCOMMIT\tPATHS")
+		for _, r := range records {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				r.Time.Local().Format("2006-01-02 15:04"), signerColumn(r), r.Kind, r.Commit[:7], strings.Join(r.Paths, ", "))
+		}
+		return tw.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("failed to write audit record: %w", err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported audit format %q", format)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package bundle
+
+import (
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+)
+
+const (
+	CreateStateStart     workflow.State = "create_start"
+	CreateStateValidated workflow.State = "create_validated"
+	CreateStatePulled    workflow.State = "create_pulled"
+	CreateStateWritten   workflow.State = "create_written"
+	CreateStateComplete  workflow.State = "create_complete"
+
+	CreateTriggerValidate workflow.Trigger = "create_validate"
+	CreateTriggerPull     workflow.Trigger = "create_pull"
+	CreateTriggerWrite    workflow.Trigger = "create_write"
+	CreateTriggerComplete workflow.Trigger = "create_complete"
+)
+
+const (
+	ApplyStateStart      workflow.State = "apply_start"
+	ApplyStateValidated  workflow.State = "apply_validated"
+	ApplyStateApplied    workflow.State = "apply_applied"
+	ApplyStateRegistered workflow.State = "apply_registered"
+	ApplyStateComplete   workflow.State = "apply_complete"
+
+	ApplyTriggerValidate workflow.Trigger = "apply_validate"
+	ApplyTriggerApply    workflow.Trigger = "apply_apply"
+	ApplyTriggerRegister workflow.Trigger = "apply_register"
+	ApplyTriggerComplete workflow.Trigger = "apply_complete"
+)
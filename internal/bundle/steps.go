@@ -0,0 +1,175 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+)
+
+type Context struct {
+	Shell       shell.Executor
+	UI          cout.IO
+	GitHub      github.Client
+	RepoPath    string
+	BundlePath  string
+	Token       string
+	SecretsPath string
+	GPG         *gpg.GPG
+}
+
+func (c *Context) stepValidateCreate() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "validate",
+		Execute: func(ctx context.Context) error {
+			if err := common.RequireOnline(); err != nil {
+				return err
+			}
+			c.Token = config.GetRepoToken(c.RepoPath)
+			if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
+				return err
+			}
+			c.GitHub.SetToken(c.Token)
+
+			configDir, err := common.ValidateConfigDir()
+			if err != nil {
+				return err
+			}
+			g, err := common.ValidateGPGSetup(configDir, c.Shell, c.UI)
+			if err != nil {
+				return err
+			}
+			c.GPG = g
+
+			secretsPath, err := common.GetSecretsPath(c.RepoPath)
+			if err != nil {
+				return err
+			}
+			c.SecretsPath = secretsPath
+			return nil
+		},
+	}
+}
+
+func (c *Context) stepPull() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "pull",
+		Execute: func(ctx context.Context) error {
+			gitClient := common.NewVerifyingGit(c.Token, c.GPG)
+			if err := gitClient.Pull(c.SecretsPath, "origin", "main", true); err != nil {
+				return fmt.Errorf("failed to pull latest changes: %w", common.SyncHint(err))
+			}
+			c.UI.Successfln("Pulled latest changes from remote")
+			return nil
+		},
+	}
+}
+
+func (c *Context) stepWriteBundle() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "write_bundle",
+		Execute: func(ctx context.Context) error {
+			gitClient := git.New()
+			if err := gitClient.CreateBundle(c.SecretsPath, "main", c.BundlePath); err != nil {
+				return fmt.Errorf("failed to create bundle: %w", err)
+			}
+			c.UI.Successfln("Wrote store bundle to %s", c.BundlePath)
+			return nil
+		},
+	}
+}
+
+// stepValidateApply needs no GitHub token: applying a bundle is how machines
+// without GitHub access receive the store.
+func (c *Context) stepValidateApply() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "validate",
+		Execute: func(ctx context.Context) error {
+			if err := config.EnsureConfigDir(); err != nil {
+				return fmt.Errorf("failed to create config directory: %w", err)
+			}
+
+			secretsPath, err := common.GetSecretsPath(c.RepoPath)
+			if err != nil {
+				return err
+			}
+			c.SecretsPath = secretsPath
+			return nil
+		},
+	}
+}
+
+// stepApplyBundle checks the bundled history like a pull when the store
+// already exists, so a bundle cannot move main past an untrusted commit.
+func (c *Context) stepApplyBundle() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "apply_bundle",
+		Execute: func(ctx context.Context) error {
+			gitClient := git.New()
+			if _, err := os.Stat(filepath.Join(c.SecretsPath, ".git")); err == nil {
+				configDir, err := common.ValidateConfigDir()
+				if err != nil {
+					return err
+				}
+				g, err := common.ValidateGPGSetup(configDir, c.Shell, c.UI)
+				if err != nil {
+					return err
+				}
+				c.GPG = g
+				gitClient = common.NewVerifyingGit("", c.GPG)
+			}
+
+			if err := gitClient.ApplyBundle(c.SecretsPath, c.BundlePath, "main"); err != nil {
+				return fmt.Errorf("failed to apply bundle: %w", err)
+			}
+			c.UI.Successfln("Applied bundle %s to %s", c.BundlePath, c.SecretsPath)
+			return nil
+		},
+	}
+}
+
+// stepRegister records the store in the config. Without a GitHub token it is
+// marked read-only so get and list skip GitHub entirely.
+func (c *Context) stepRegister() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "register",
+		Execute: func(ctx context.Context) error {
+			if config.GetToken() != "" {
+				if err := config.SaveGitHubRepo(c.RepoPath); err != nil {
+					return fmt.Errorf("failed to save repository: %w", err)
+				}
+				return nil
+			}
+			if err := config.SaveReadOnlyRepo(c.RepoPath); err != nil {
+				return fmt.Errorf("failed to save repository: %w", err)
+			}
+			c.UI.Infofln("Registered %s as a read-only store; refresh it with `kepr bundle apply`", c.RepoPath)
+			return nil
+		},
+	}
+}
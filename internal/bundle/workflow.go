@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package bundle
+
+import (
+	"context"
+
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+)
+
+func NewCreateWorkflow(bundlePath, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &Context{
+		Shell:      sh,
+		UI:         ui,
+		GitHub:     gh,
+		RepoPath:   repoPath,
+		BundlePath: bundlePath,
+	}
+
+	w := workflow.New(CreateStateStart)
+
+	w.Configure(CreateStateStart).
+		Permit(CreateTriggerValidate, CreateStateValidated)
+
+	w.Configure(CreateStateValidated).
+		OnEntryFrom(CreateTriggerValidate, entryWithRetry(c.stepValidateCreate())).
+		Permit(CreateTriggerPull, CreateStatePulled)
+
+	w.Configure(CreateStatePulled).
+		OnEntryFrom(CreateTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(CreateTriggerWrite, CreateStateWritten)
+
+	w.Configure(CreateStateWritten).
+		OnEntryFrom(CreateTriggerWrite, entryWithRetry(c.stepWriteBundle())).
+		Permit(CreateTriggerComplete, CreateStateComplete)
+
+	w.Configure(CreateStateComplete)
+
+	w.AddTrigger(CreateTriggerValidate)
+	w.AddTrigger(CreateTriggerPull)
+	w.AddTrigger(CreateTriggerWrite)
+	w.AddTrigger(CreateTriggerComplete)
+
+	return w
+}
+
+func NewApplyWorkflow(bundlePath, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &Context{
+		Shell:      sh,
+		UI:         ui,
+		GitHub:     gh,
+		RepoPath:   repoPath,
+		BundlePath: bundlePath,
+	}
+
+	w := workflow.New(ApplyStateStart)
+
+	w.Configure(ApplyStateStart).
+		Permit(ApplyTriggerValidate, ApplyStateValidated)
+
+	w.Configure(ApplyStateValidated).
+		OnEntryFrom(ApplyTriggerValidate, entryWithRetry(c.stepValidateApply())).
+		Permit(ApplyTriggerApply, ApplyStateApplied)
+
+	w.Configure(ApplyStateApplied).
+		OnEntryFrom(ApplyTriggerApply, entryWithRetry(c.stepApplyBundle())).
+		Permit(ApplyTriggerRegister, ApplyStateRegistered)
+
+	w.Configure(ApplyStateRegistered).
+		OnEntryFrom(ApplyTriggerRegister, entryWithRetry(c.stepRegister())).
+		Permit(ApplyTriggerComplete, ApplyStateComplete)
+
+	w.Configure(ApplyStateComplete)
+
+	w.AddTrigger(ApplyTriggerValidate)
+	w.AddTrigger(ApplyTriggerApply)
+	w.AddTrigger(ApplyTriggerRegister)
+	w.AddTrigger(ApplyTriggerComplete)
+
+	return w
+}
+
+func entryWithRetry(cfg workflow.StepConfig) func(ctx context.Context, args ...any) error {
+	return func(ctx context.Context, args ...any) error {
+		return workflow.ExecuteWithRetry(ctx, cfg)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package common
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+)
+
+// IsUnreachable reports whether err means GitHub or the store's remote
+// could not be contacted at all.
+func IsUnreachable(err error) bool {
+	if errors.Is(err, git.ErrUnreachable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// GoOffline switches the rest of the run to offline mode after the remote
+// turned out to be unreachable.
+func GoOffline(ui cout.IO, err error) {
+	slog.Debug("remote unreachable, continuing offline", "error", err)
+	ui.Warning("Remote unreachable; continuing offline")
+	config.SetOffline(true)
+}
+
+// RequireOnline fails commands that cannot do their job without the remote.
+func RequireOnline() error {
+	if config.IsOffline() {
+		return fmt.Errorf("this command needs the remote; run it without --offline once back online")
+	}
+	return nil
+}
+
+// CheckIdentity validates the identity on the store's provider unless
+// working offline, and goes offline when the provider cannot be reached.
+func CheckIdentity(repoPath string, gh github.Client, expectedEmail string, ui cout.IO) error {
+	if config.IsOffline() {
+		return nil
+	}
+	p, err := RepoProvider(repoPath, gh)
+	if err != nil {
+		return err
+	}
+	err = ValidateProviderIdentity(p, expectedEmail)
+	if IsUnreachable(err) {
+		GoOffline(ui, err)
+		return nil
+	}
+	return err
+}
+
+// PullForRead brings the store up to date for commands that only read it.
+// Offline, or when the remote cannot be reached, it keeps the local copy and
+// says how old it may be.
+func PullForRead(gitClient *git.Git, repoPath, secretsPath string, ui cout.IO) error {
+	if !config.IsOffline() {
+		err := gitClient.Pull(secretsPath, "origin", "main", true)
+		switch {
+		case err == nil:
+			if config.HasPendingPush(repoPath) {
+				ui.Infofln("Changes made offline are waiting to be pushed; run `kepr sync`")
+			}
+			return nil
+		case errors.Is(err, git.ErrDiverged):
+			ui.Warning(UnsyncedWarning)
+			return nil
+		case IsUnreachable(err):
+			GoOffline(ui, err)
+		default:
+			return fmt.Errorf("failed to pull from remote: %w", err)
+		}
+	}
+
+	last, err := gitClient.LastFetch(secretsPath)
+	if err != nil || last.IsZero() {
+		ui.Warning("Working offline; the local store may be out of date")
+		return nil
+	}
+	ui.Warning(fmt.Sprintf("Working offline; the local store was last synced on %s", last.Local().Format("2006-01-02 15:04")))
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package common
+
+import (
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/provider"
+)
+
+// RepoKind returns the provider repoPath was created on.
+func RepoKind(repoPath string) string {
+	kind, _ := config.GetRepoProvider(repoPath)
+	if kind == "" {
+		return provider.KindGitHub
+	}
+	return kind
+}
+
+// IsGitHubRepo reports whether repoPath lives on GitHub, and so has pull
+// requests.
+func IsGitHubRepo(repoPath string) bool {
+	return RepoKind(repoPath) == provider.KindGitHub
+}
+
+// IsLocalOnly reports whether repoPath was created without any remote.
+func IsLocalOnly(repoPath string) bool {
+	kind, url := config.GetRepoProvider(repoPath)
+	return kind == provider.KindLocal && url == ""
+}
+
+// RepoProvider returns the provider of repoPath, authenticated with its
+// token. GitHub stores use gh itself.
+func RepoProvider(repoPath string, gh github.Client) (provider.Provider, error) {
+	kind, url := config.GetRepoProvider(repoPath)
+	if kind == "" || kind == provider.KindGitHub {
+		return gh, nil
+	}
+
+	p, err := provider.New(kind, url)
+	if err != nil {
+		return nil, err
+	}
+	p.SetToken(config.GetRepoToken(repoPath))
+	return p, nil
+}
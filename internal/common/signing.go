@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package common
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+// CommitSigner returns a signer for kepr commits using the user's signing
+// subkey, which must already exist.
+// The key must carry the commit author's email so the signature vouches for
+// the author line.
+func CommitSigner(g *gpg.GPG, fingerprint, authorEmail string) (git.Signer, error) {
+	slog.Debug("preparing commit signing", "fingerprint", fingerprint)
+
+	keys, err := g.ListPublicKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GPG keys: %w", err)
+	}
+	bound := false
+	for _, key := range keys {
+		if key.Fingerprint == fingerprint && strings.EqualFold(key.Email, authorEmail) {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		return nil, fmt.Errorf("key %s does not belong to %s; refusing to sign commits as that author", fingerprint, authorEmail)
+	}
+
+	if err := g.RequireSigningKey(fingerprint); err != nil {
+		return nil, err
+	}
+	return g.CommitSigner(fingerprint), nil
+}
+
+// NewSigningGit returns a git client whose commits are signed with the user's
+// signing subkey. The public key is kept in keys/ so other machines can
+// verify the commits.
+func NewSigningGit(token string, g *gpg.GPG, fingerprint, authorEmail, secretsPath string) (*git.Git, error) {
+	signer, err := CommitSigner(g, fingerprint, authorEmail)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.SavePublicKey(secretsPath, g, fingerprint); err != nil {
+		return nil, err
+	}
+
+	gitClient := git.NewWithAuth(token)
+	gitClient.SetSigner(signer)
+	return gitClient, nil
+}
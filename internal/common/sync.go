@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+// UnsyncedWarning is shown by commands that only read the store when it has
+// local commits the remote does not.
+const UnsyncedWarning = "This store has local commits that are not on the remote; using the local copy. Run `kepr sync` to push them."
+
+// NewSyncingGit returns a git client that verifies pulls like
+// NewVerifyingGit and rebases local commits onto the remote, signed with the
+// user's key. When both sides changed the same secret or recipients file,
+// the user picks which version to keep.
+func NewSyncingGit(token string, g *gpg.GPG, fingerprint, authorEmail, secretsPath string, ui cout.IO) (*git.Git, error) {
+	gitClient, err := NewSigningGit(token, g, fingerprint, authorEmail, secretsPath)
+	if err != nil {
+		return nil, err
+	}
+	v := &pullVerifier{git: gitClient, gpg: g, imported: make(map[string]bool)}
+	gitClient.SetVerifier(v.verify)
+	gitClient.SetConflictResolver(&conflictPrompt{
+		git:      gitClient,
+		ui:       ui,
+		repoPath: secretsPath,
+		paths:    store.NewPathResolver(g),
+	})
+	return gitClient, nil
+}
+
+// SyncHint points the user at `kepr sync` when a pull stopped because of
+// unpushed local commits.
+func SyncHint(err error) error {
+	if errors.Is(err, git.ErrDiverged) {
+		return fmt.Errorf("%w; run `kepr sync` first", err)
+	}
+	return err
+}
+
+type conflictPrompt struct {
+	git      *git.Git
+	ui       cout.IO
+	repoPath string
+	paths    *store.PathResolver
+}
+
+func (p *conflictPrompt) Key(path string) string {
+	return store.ConflictKey(path)
+}
+
+func (p *conflictPrompt) KeepLocal(c git.Conflict) (bool, error) {
+	read := func(relPath string) []byte {
+		for _, hash := range []string{c.Local, c.Remote} {
+			if data, err := p.git.ReadFileAtCommit(p.repoPath, hash, relPath); err == nil {
+				return data
+			}
+		}
+		return nil
+	}
+	name := p.paths.Resolve(c.Paths[0], read)
+
+	if c.Recipients != "" {
+		dir := p.paths.Resolve(c.Recipients, read)
+		drop, err := p.ui.Confirm(fmt.Sprintf("The recipients of %s changed on the remote, and %d local change(s) there, such as %s, were encrypted for the old ones. Drop the local changes? (no stops the sync; copy them with `kepr get` first)", dir, len(c.Paths), name))
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve conflict on %s: %w", dir, err)
+		}
+		return !drop, nil
+	}
+
+	keep, err := p.ui.Confirm(fmt.Sprintf("%s was changed both here and on the remote. Keep the local version? (no keeps the remote one)", name))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve conflict on %s: %w", name, err)
+	}
+	return keep, nil
+}
@@ -17,20 +17,21 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package common
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
 	"github.com/gonzaloalvarez/kepr/pkg/config"
 	"github.com/gonzaloalvarez/kepr/pkg/cout"
-	"github.com/gonzaloalvarez/kepr/pkg/github"
 	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/provider"
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 )
 
-func ValidateToken(token string) error {
+func ValidateToken(repoPath, token string) error {
 	slog.Debug("validating token")
-	if token == "" {
+	if token == "" && provider.NeedsToken(RepoKind(repoPath)) {
 		return fmt.Errorf("not authenticated: run 'kepr init' first")
 	}
 	return nil
@@ -60,15 +61,20 @@ func ValidateUserIdentity() (string, string, error) {
 	return userName, userEmail, nil
 }
 
-func ValidateGitHubIdentity(gh github.Client, expectedEmail string) error {
-	slog.Debug("validating GitHub identity")
-	_, email, err := gh.GetUserIdentity()
+// ValidateProviderIdentity checks that the account behind the token is the
+// configured user. Providers without accounts pass.
+func ValidateProviderIdentity(p provider.Provider, expectedEmail string) error {
+	slog.Debug("validating provider identity")
+	_, email, err := p.GetUserIdentity()
+	if errors.Is(err, provider.ErrUnsupported) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to validate GitHub token: %w", err)
+		return fmt.Errorf("failed to validate token: %w", err)
 	}
 
 	if email != expectedEmail {
-		return fmt.Errorf("email mismatch: GitHub (%s) != config (%s)", email, expectedEmail)
+		return fmt.Errorf("email mismatch: remote account (%s) != config (%s)", email, expectedEmail)
 	}
 	return nil
 }
@@ -0,0 +1,168 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package common
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+// NewVerifyingGit returns a git client whose pulls refuse commits that are
+// not signed by a known key, and changes to recipients, keys, groups or the
+// approval policy that are not signed by a root recipient. Changes to the
+// approval policy also need signed approvals from as many admins as the
+// previous policy requires. Each commit is
+// judged against the state of its parent, so the local store never moves
+// past the last trusted commit.
+func NewVerifyingGit(token string, g *gpg.GPG) *git.Git {
+	gitClient := git.NewWithAuth(token)
+	v := &pullVerifier{git: gitClient, gpg: g, imported: make(map[string]bool)}
+	gitClient.SetVerifier(v.verify)
+	return gitClient
+}
+
+type pullVerifier struct {
+	git      *git.Git
+	gpg      *gpg.GPG
+	imported map[string]bool
+}
+
+func (v *pullVerifier) verify(repoPath, from, to string) error {
+	commits, err := v.git.IncomingCommits(repoPath, from, to)
+	if err != nil {
+		return err
+	}
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+
+		trust, err := v.trustAt(repoPath, commit.Parent)
+		if err != nil {
+			return err
+		}
+		signer, err := v.signer(repoPath, commit)
+		if err != nil {
+			return err
+		}
+		if err := trust.CheckCommit(signer, commit.Changed); err != nil {
+			return fmt.Errorf("untrusted commit %s: %w", commit.Hash, err)
+		}
+		if slices.Contains(commit.Changed, store.PolicyFile) {
+			if err := v.checkPolicy(repoPath, commit, trust); err != nil {
+				return fmt.Errorf("untrusted commit %s: %w", commit.Hash, err)
+			}
+		}
+	}
+
+	slog.Debug("verified incoming commits", "count", len(commits))
+	return nil
+}
+
+func (v *pullVerifier) trustAt(repoPath, hash string) (store.CommitTrust, error) {
+	root, err := v.git.ReadFilesAtCommit(repoPath, hash, "")
+	if err != nil {
+		return store.CommitTrust{}, err
+	}
+	groups, err := v.git.ReadFilesAtCommit(repoPath, hash, "groups")
+	if err != nil {
+		return store.CommitTrust{}, err
+	}
+	keys, err := v.git.ReadFilesAtCommit(repoPath, hash, "keys")
+	if err != nil {
+		return store.CommitTrust{}, err
+	}
+	return store.NewCommitTrust(v.gpg, root[".gpg.id"], groups, keys), nil
+}
+
+// checkPolicy verifies that a commit changing policy.json carries enough
+// admin approvals of the new content under the parent's policy.
+func (v *pullVerifier) checkPolicy(repoPath string, commit git.IncomingCommit, trust store.CommitTrust) error {
+	before, err := v.git.ReadFilesAtCommit(repoPath, commit.Parent, "")
+	if err != nil {
+		return err
+	}
+	after, err := v.git.ReadFilesAtCommit(repoPath, commit.Hash, "")
+	if err != nil {
+		return err
+	}
+	approvals, err := v.git.ReadFilesAtCommit(repoPath, commit.Hash, store.ApprovalsDir)
+	if err != nil {
+		return err
+	}
+
+	admins := make([]string, 0, len(trust.Admins))
+	for fp := range trust.Admins {
+		admins = append(admins, fp)
+	}
+	return store.CheckPolicyChange(v.gpg, before[store.PolicyFile], after[store.PolicyFile], approvals, admins)
+}
+
+// signer returns the fingerprint that vouches for commit, or "" when none
+// does. A merge made by the forge is vouched for by the parent whose tree
+// it takes unchanged.
+func (v *pullVerifier) signer(repoPath string, commit git.IncomingCommit) (string, error) {
+	if err := v.importKeys(repoPath, commit.Hash); err != nil {
+		return "", err
+	}
+
+	if commit.Signature != "" {
+		if fp, err := v.gpg.VerifyDetached(commit.Payload, []byte(commit.Signature)); err == nil {
+			return fp, nil
+		}
+		slog.Debug("commit signature did not verify", "commit", commit.Hash)
+	}
+
+	for _, parent := range commit.SameTree {
+		signature, payload, err := v.git.CommitSignature(repoPath, parent)
+		if err != nil {
+			return "", err
+		}
+		if signature == "" {
+			continue
+		}
+		if fp, err := v.gpg.VerifyDetached(payload, []byte(signature)); err == nil {
+			return fp, nil
+		}
+	}
+	return "", nil
+}
+
+// importKeys loads the public keys stored at a commit so its signature can
+// be checked. Importing grants nothing: trust comes from the parent's root
+// .gpg.id and keys/.
+func (v *pullVerifier) importKeys(repoPath, hash string) error {
+	keys, err := v.git.ReadFilesAtCommit(repoPath, hash, "keys")
+	if err != nil {
+		return err
+	}
+	for name, data := range keys {
+		if v.imported[string(data)] {
+			continue
+		}
+		if err := v.gpg.ImportPublicKey(data); err != nil {
+			slog.Debug("failed to import key", "key", name, "error", err)
+			continue
+		}
+		v.imported[string(data)] = true
+	}
+	return nil
+}
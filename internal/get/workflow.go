@@ -58,14 +58,15 @@ func NewWorkflow(key, outputPath, repoPath string, gh github.Client, sh shell.Ex
 
 	w.Configure(StateSecretsPathReady).
 		OnEntryFrom(TriggerGetSecretsPath, entryWithRetry(c.stepGetSecretsPath())).
-		Permit(TriggerPull, StatePulled)
-
-	w.Configure(StatePulled).
-		OnEntryFrom(TriggerPull, entryWithRetry(c.stepPull())).
 		Permit(TriggerValidateGPG, StateGPGValidated)
 
+	// GPG comes before the pull, which verifies incoming commit signatures.
 	w.Configure(StateGPGValidated).
 		OnEntryFrom(TriggerValidateGPG, entryWithRetry(c.stepValidateGPG())).
+		Permit(TriggerPull, StatePulled)
+
+	w.Configure(StatePulled).
+		OnEntryFrom(TriggerPull, entryWithRetry(c.stepPull())).
 		Permit(TriggerCheckYubikey, StateYubikeyReady)
 
 	w.Configure(StateYubikeyReady).
@@ -91,8 +92,8 @@ func NewWorkflow(key, outputPath, repoPath string, gh github.Client, sh shell.Ex
 	w.AddTrigger(TriggerValidateIdentity)
 	w.AddTrigger(TriggerValidateGitHub)
 	w.AddTrigger(TriggerGetSecretsPath)
-	w.AddTrigger(TriggerPull)
 	w.AddTrigger(TriggerValidateGPG)
+	w.AddTrigger(TriggerPull)
 	w.AddTrigger(TriggerCheckYubikey)
 	w.AddTrigger(TriggerValidateKey)
 	w.AddTrigger(TriggerInitStore)
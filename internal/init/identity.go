@@ -17,6 +17,7 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package initialize
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -24,6 +25,9 @@ import (
 	"github.com/gonzaloalvarez/kepr/pkg/config"
 	"github.com/gonzaloalvarez/kepr/pkg/cout"
 	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/provider"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
 )
 
 var (
@@ -70,22 +74,84 @@ func AuthGithub(client github.Client, io cout.IO, headless bool) (string, error)
 	return token, nil
 }
 
-func UserInfo(client github.Client, io cout.IO) error {
+// tokenScopes are the access token scopes kepr needs on each provider.
+var tokenScopes = map[string]string{
+	provider.KindGitLab: "api",
+	provider.KindGitea:  "write:repository, read:user",
+}
+
+// AuthToken asks for a personal access token on providers kepr has no OAuth
+// app for. The token is saved with the store rather than as the GitHub token.
+func AuthToken(kind string, io cout.IO) (string, error) {
+	io.Infofln("Create a personal access token on %s with the %s scopes", kind, tokenScopes[kind])
+	token, err := io.InputPassword("Access token:")
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	if token == "" {
+		return "", fmt.Errorf("an access token is required for %s", kind)
+	}
+	return token, nil
+}
+
+// IdentitySource supplies the name and email to suggest for a new user.
+type IdentitySource interface {
+	GetUserIdentity() (name string, email string, err error)
+}
+
+// keyIdentity reads the identity from the key passed with --from-pk, for
+// stores whose provider has no account to read it from.
+type keyIdentity struct {
+	path  string
+	shell shell.Executor
+	io    cout.IO
+}
+
+func (k keyIdentity) GetUserIdentity() (string, string, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return "", "", err
+	}
+	g, err := gpg.New(configDir, k.shell, k.io)
+	if err != nil {
+		return "", "", err
+	}
+	keyData, err := os.ReadFile(k.path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	return g.ReadKeyIdentity(keyData)
+}
+
+// SaveIdentity records the identity given on the command line.
+func SaveIdentity(name, email string, io cout.IO) error {
+	if err := config.SaveUserIdentity(name, email); err != nil {
+		return fmt.Errorf("failed to save user identity: %w", err)
+	}
+	io.Successfln("User identity saved: %s <%s>", name, email)
+	return nil
+}
+
+func UserInfo(client IdentitySource, io cout.IO) error {
 	userName := config.GetUserName()
 	userEmail := config.GetUserEmail()
 	if userName == "" || userEmail == "" {
-		slog.Debug("user identity not found locally, fetching from GitHub")
+		slog.Debug("user identity not found locally, fetching from provider")
 
 		name, email, err := client.GetUserIdentity()
-		if err != nil {
+		confirmed := false
+		switch {
+		case errors.Is(err, provider.ErrUnsupported):
+			slog.Debug("provider has no accounts, asking for identity")
+		case err != nil:
 			return fmt.Errorf("failed to fetch user identity: %w", err)
-		}
-
-		io.Infofln("Detected identity: %s <%s>", name, email)
+		default:
+			io.Infofln("Detected identity: %s <%s>", name, email)
 
-		confirmed, err := io.Confirm(fmt.Sprintf("Is this identity correct? [%s <%s>]", name, email))
-		if err != nil {
-			return fmt.Errorf("confirmation failed: %w", err)
+			confirmed, err = io.Confirm(fmt.Sprintf("Is this identity correct? [%s <%s>]", name, email))
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
 		}
 
 		if !confirmed {
@@ -99,13 +165,14 @@ func UserInfo(client github.Client, io cout.IO) error {
 			if err != nil {
 				return fmt.Errorf("failed to get email: %w", err)
 			}
+			if name == "" || email == "" {
+				return fmt.Errorf("name and email are required")
+			}
 		}
 
-		if err := config.SaveUserIdentity(name, email); err != nil {
-			return fmt.Errorf("failed to save user identity: %w", err)
+		if err := SaveIdentity(name, email, io); err != nil {
+			return err
 		}
-
-		io.Successfln("User identity saved: %s <%s>", name, email)
 	} else {
 		io.Successfln("Welcome back, %s!", userName)
 		slog.Debug("user identity already configured", "name", userName, "email", userEmail)
@@ -27,17 +27,18 @@ import (
 	"github.com/gonzaloalvarez/kepr/pkg/github"
 	"github.com/gonzaloalvarez/kepr/pkg/gpg"
 	"github.com/gonzaloalvarez/kepr/pkg/pass"
+	"github.com/gonzaloalvarez/kepr/pkg/provider"
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 	"github.com/gonzaloalvarez/kepr/pkg/store"
 )
 
-func ClonePasswordStore(configDir, repoPath, token string, gh github.Client, io cout.IO) error {
+func ClonePasswordStore(configDir, repoPath, token string, host provider.Provider, io cout.IO) error {
 	slog.Debug("cloning password store", "repo", repoPath)
 
 	secretsPath := filepath.Join(configDir, repoPath)
 	repoName := github.ExtractRepoName(repoPath)
 
-	cloneURL, err := gh.GetCloneURL(repoName)
+	cloneURL, err := host.GetCloneURL(repoName)
 	if err != nil {
 		return fmt.Errorf("failed to get clone URL: %w", err)
 	}
@@ -20,12 +20,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gonzaloalvarez/kepr/internal/common"
 	"github.com/gonzaloalvarez/kepr/internal/workflow"
 	"github.com/gonzaloalvarez/kepr/pkg/config"
 	"github.com/gonzaloalvarez/kepr/pkg/cout"
 	"github.com/gonzaloalvarez/kepr/pkg/git"
 	"github.com/gonzaloalvarez/kepr/pkg/github"
 	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/provider"
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 )
 
@@ -43,37 +45,81 @@ type Context struct {
 	SecretsPath string
 	UserName    string
 	UserEmail   string
+	// Provider and URL select where the store lives; Host is the provider
+	// once authenticated, which is GitHub itself for GitHub stores.
+	Provider string
+	URL      string
+	Host     provider.Provider
 }
 
 func (c *Context) stepAuthenticate() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "authenticate",
 		Execute: func(ctx context.Context) error {
+			if !provider.IsLocal(c.Provider) {
+				if err := common.RequireOnline(); err != nil {
+					return err
+				}
+			}
 			if err := config.EnsureConfigDir(); err != nil {
 				return fmt.Errorf("failed to create config directory: %w", err)
 			}
-			token, err := AuthGithub(c.GitHub, c.UI, c.Headless)
-			if err != nil {
+			if err := c.authenticate(); err != nil {
 				return err
 			}
-			c.Token = token
-			c.GitHub.SetToken(token)
-			owner, err := c.GitHub.GetCurrentUserLogin()
+			c.Host.SetToken(c.Token)
+			owner, err := c.Host.GetCurrentUserLogin()
 			if err != nil {
 				return fmt.Errorf("failed to get current user: %w", err)
 			}
+			// Stores are named locally by the first owner kepr was set up
+			// with; outside GitHub the owner is nothing more than that.
+			if c.Provider != provider.KindGitHub && config.GetGitHubOwner() != "" {
+				owner = config.GetGitHubOwner()
+			}
 			c.RepoPath = owner + "/" + c.RepoPath
 			return nil
 		},
 	}
 }
 
+func (c *Context) authenticate() error {
+	if c.Provider == provider.KindGitHub {
+		token, err := AuthGithub(c.GitHub, c.UI, c.Headless)
+		if err != nil {
+			return err
+		}
+		c.Token = token
+		c.Host = c.GitHub
+		return nil
+	}
+
+	host, err := provider.New(c.Provider, c.URL)
+	if err != nil {
+		return err
+	}
+	if provider.NeedsToken(c.Provider) {
+		token, err := AuthToken(c.Provider, c.UI)
+		if err != nil {
+			return err
+		}
+		c.Token = token
+	}
+	c.Host = host
+	return nil
+}
+
+// localOnly reports whether the store is created without any remote.
+func (c *Context) localOnly() bool {
+	return c.Provider == provider.KindLocal && c.URL == ""
+}
+
 func (c *Context) stepCheckRepo() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "check_repo",
 		Execute: func(ctx context.Context) error {
 			repoName := github.ExtractRepoName(c.RepoPath)
-			exists, err := c.GitHub.CheckRepoExists(repoName)
+			exists, err := c.Host.CheckRepoExists(repoName)
 			if err != nil {
 				return fmt.Errorf("failed to check repository: %w", err)
 			}
@@ -94,10 +140,21 @@ func (c *Context) stepCreateRepo() workflow.StepConfig {
 				return nil
 			}
 			repoName := github.ExtractRepoName(c.RepoPath)
-			if err := c.GitHub.CreateRepo(repoName); err != nil {
+			if err := c.Host.CreateRepo(repoName); err != nil {
 				return fmt.Errorf("failed to create remote repository: %w", err)
 			}
-			c.UI.Successfln("Created private remote repository: github.com/%s", c.RepoPath)
+			switch {
+			case c.localOnly():
+				c.UI.Infofln("No remote configured; the store stays on this machine")
+			case c.Provider == provider.KindLocal:
+				c.UI.Successfln("Using bare repository %s", c.URL)
+			case c.Provider == provider.KindGitHub:
+				c.UI.Successfln("Created private remote repository: github.com/%s", c.RepoPath)
+			case c.Provider == provider.KindGeneric:
+				c.UI.Infofln("Remote %s is empty, will push a new store to it", c.URL)
+			default:
+				c.UI.Successfln("Created private remote repository %s on %s", repoName, c.Provider)
+			}
 			return nil
 		},
 		Retry: &workflow.RetryConfig{
@@ -116,6 +173,11 @@ func (c *Context) stepSaveConfig() workflow.StepConfig {
 			if err := config.SaveGitHubRepo(c.RepoPath); err != nil {
 				return err
 			}
+			if c.Provider != provider.KindGitHub {
+				if err := config.SaveRepoProvider(c.RepoPath, c.Provider, c.URL, c.Token); err != nil {
+					return fmt.Errorf("failed to save provider: %w", err)
+				}
+			}
 			if c.Headless {
 				return config.SaveHeadless(true)
 			}
@@ -128,7 +190,15 @@ func (c *Context) stepFetchUserInfo() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "fetch_user_info",
 		Execute: func(ctx context.Context) error {
-			return UserInfo(c.GitHub, c.UI)
+			if c.UserName != "" && c.UserEmail != "" {
+				return SaveIdentity(c.UserName, c.UserEmail, c.UI)
+			}
+			// Without a token there is no account to read the identity
+			// from, but an imported key carries one.
+			if c.FromKeyPath != "" && !provider.NeedsToken(c.Provider) {
+				return UserInfo(keyIdentity{path: c.FromKeyPath, shell: c.Shell, io: c.UI}, c.UI)
+			}
+			return UserInfo(c.Host, c.UI)
 		},
 	}
 }
@@ -149,7 +219,7 @@ func (c *Context) stepCloneRepo() workflow.StepConfig {
 				return err
 			}
 			c.SecretsPath = secretsPath
-			return ClonePasswordStore(configDir, c.RepoPath, c.Token, c.GitHub, c.UI)
+			return ClonePasswordStore(configDir, c.RepoPath, c.Token, c.Host, c.UI)
 		},
 	}
 }
@@ -199,7 +269,10 @@ func (c *Context) stepCommit() workflow.StepConfig {
 			}
 			c.UserName = config.GetUserName()
 			c.UserEmail = config.GetUserEmail()
-			gitClient := git.NewWithAuth(c.Token)
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
 			return gitClient.Commit(c.SecretsPath, "initialized secret store", c.UserName, c.UserEmail)
 		},
 	}
@@ -209,12 +282,12 @@ func (c *Context) stepConfigureRemote() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "configure_remote",
 		Execute: func(ctx context.Context) error {
-			if c.RepoExists {
+			if c.RepoExists || c.localOnly() {
 				return nil
 			}
 			gitClient := git.NewWithAuth(c.Token)
 			repoName := github.ExtractRepoName(c.RepoPath)
-			remoteURL, err := c.GitHub.GetCloneURL(repoName)
+			remoteURL, err := c.Host.GetCloneURL(repoName)
 			if err != nil {
 				return fmt.Errorf("failed to get clone URL: %w", err)
 			}
@@ -231,7 +304,7 @@ func (c *Context) stepPush() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "push",
 		Execute: func(ctx context.Context) error {
-			if c.RepoExists {
+			if c.RepoExists || c.localOnly() {
 				return nil
 			}
 			gitClient := git.NewWithAuth(c.Token)
@@ -25,14 +25,18 @@ import (
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 )
 
-func NewWorkflow(repoName string, headless bool, fromKeyPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+func NewWorkflow(repoName string, headless bool, fromKeyPath, providerKind, providerURL, userName, userEmail string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
 	c := &Context{
 		Shell:       sh,
 		UI:          ui,
 		GitHub:      gh,
+		Provider:    providerKind,
+		URL:         providerURL,
 		RepoPath:    repoName,
 		Headless:    headless,
 		FromKeyPath: fromKeyPath,
+		UserName:    userName,
+		UserEmail:   userEmail,
 	}
 
 	w := workflow.New(StateStart)
@@ -25,7 +25,6 @@ import (
 	"github.com/gonzaloalvarez/kepr/internal/workflow"
 	"github.com/gonzaloalvarez/kepr/pkg/config"
 	"github.com/gonzaloalvarez/kepr/pkg/cout"
-	"github.com/gonzaloalvarez/kepr/pkg/git"
 	"github.com/gonzaloalvarez/kepr/pkg/github"
 	"github.com/gonzaloalvarez/kepr/pkg/gpg"
 	"github.com/gonzaloalvarez/kepr/pkg/pass"
@@ -40,6 +39,7 @@ type Context struct {
 	RepoPath    string
 	Path        string
 	Token       string
+	ReadOnly    bool
 	ConfigDir   string
 	UserName    string
 	UserEmail   string
@@ -54,8 +54,14 @@ func (c *Context) stepValidateToken() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "validate_token",
 		Execute: func(ctx context.Context) error {
-			c.Token = config.GetToken()
-			if err := common.ValidateToken(c.Token); err != nil {
+			// Store copies fed from bundles never talk to GitHub.
+			if config.IsReadOnlyRepo(c.RepoPath) {
+				c.ReadOnly = true
+				return nil
+			}
+
+			c.Token = config.GetRepoToken(c.RepoPath)
+			if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
 				return err
 			}
 			c.GitHub.SetToken(c.Token)
@@ -97,7 +103,10 @@ func (c *Context) stepValidateGitHub() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "validate_github",
 		Execute: func(ctx context.Context) error {
-			return common.ValidateGitHubIdentity(c.GitHub, c.UserEmail)
+			if c.ReadOnly {
+				return nil
+			}
+			return common.CheckIdentity(c.RepoPath, c.GitHub, c.UserEmail, c.UI)
 		},
 	}
 }
@@ -120,11 +129,11 @@ func (c *Context) stepPull() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "pull",
 		Execute: func(ctx context.Context) error {
-			gitClient := git.NewWithAuth(c.Token)
-			if err := gitClient.Pull(c.SecretsPath, "origin", "main", true); err != nil {
-				return fmt.Errorf("failed to pull from remote: %w", err)
+			if c.ReadOnly {
+				return nil
 			}
-			return nil
+			gitClient := common.NewVerifyingGit(c.Token, c.GPG)
+			return common.PullForRead(gitClient, c.RepoPath, c.SecretsPath, c.UI)
 		},
 		Retry: &workflow.RetryConfig{
 			MaxAttempts: 3,
@@ -57,14 +57,15 @@ func NewWorkflow(path, repoPath string, gh github.Client, sh shell.Executor, ui
 
 	w.Configure(StateSecretsPathReady).
 		OnEntryFrom(TriggerGetSecretsPath, entryWithRetry(c.stepGetSecretsPath())).
-		Permit(TriggerPull, StatePulled)
-
-	w.Configure(StatePulled).
-		OnEntryFrom(TriggerPull, entryWithRetry(c.stepPull())).
 		Permit(TriggerValidateGPG, StateGPGValidated)
 
+	// GPG comes before the pull, which verifies incoming commit signatures.
 	w.Configure(StateGPGValidated).
 		OnEntryFrom(TriggerValidateGPG, entryWithRetry(c.stepValidateGPG())).
+		Permit(TriggerPull, StatePulled)
+
+	w.Configure(StatePulled).
+		OnEntryFrom(TriggerPull, entryWithRetry(c.stepPull())).
 		Permit(TriggerCheckYubikey, StateYubikeyReady)
 
 	w.Configure(StateYubikeyReady).
@@ -90,8 +91,8 @@ func NewWorkflow(path, repoPath string, gh github.Client, sh shell.Executor, ui
 	w.AddTrigger(TriggerValidateIdentity)
 	w.AddTrigger(TriggerValidateGitHub)
 	w.AddTrigger(TriggerGetSecretsPath)
-	w.AddTrigger(TriggerPull)
 	w.AddTrigger(TriggerValidateGPG)
+	w.AddTrigger(TriggerPull)
 	w.AddTrigger(TriggerCheckYubikey)
 	w.AddTrigger(TriggerValidateKey)
 	w.AddTrigger(TriggerInitStore)
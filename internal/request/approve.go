@@ -21,47 +21,121 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/gonzaloalvarez/kepr/internal/common"
 	"github.com/gonzaloalvarez/kepr/internal/workflow"
 	"github.com/gonzaloalvarez/kepr/pkg/cout"
 	"github.com/gonzaloalvarez/kepr/pkg/git"
 	"github.com/gonzaloalvarez/kepr/pkg/github"
 	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/sas"
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 	"github.com/gonzaloalvarez/kepr/pkg/store"
 )
 
 const (
-	ApproveStateStart         workflow.State = "approve_start"
-	ApproveStateValidated     workflow.State = "approve_validated"
-	ApproveStatePulled        workflow.State = "approve_pulled"
-	ApproveStateFetched       workflow.State = "approve_fetched"
-	ApproveStateRequestFound  workflow.State = "approve_request_found"
-	ApproveStateKeyImported   workflow.State = "approve_key_imported"
-	ApproveStateRekeyed       workflow.State = "approve_rekeyed"
-	ApproveStateKeyExported   workflow.State = "approve_key_exported"
-	ApproveStateCleaned       workflow.State = "approve_cleaned"
-	ApproveStatePushed        workflow.State = "approve_pushed"
-	ApproveStateBranchDeleted workflow.State = "approve_branch_deleted"
-	ApproveStateComplete      workflow.State = "approve_complete"
-
-	ApproveTriggerValidate     workflow.Trigger = "approve_validate"
-	ApproveTriggerPull         workflow.Trigger = "approve_pull"
-	ApproveTriggerFetch        workflow.Trigger = "approve_fetch"
-	ApproveTriggerFindRequest  workflow.Trigger = "approve_find_request"
-	ApproveTriggerImportKey    workflow.Trigger = "approve_import_key"
-	ApproveTriggerRekey        workflow.Trigger = "approve_rekey"
-	ApproveTriggerExportKey    workflow.Trigger = "approve_export_key"
-	ApproveTriggerCleanup      workflow.Trigger = "approve_cleanup"
-	ApproveTriggerCommitPush   workflow.Trigger = "approve_commit_push"
-	ApproveTriggerDeleteBranch workflow.Trigger = "approve_delete_branch"
-	ApproveTriggerComplete     workflow.Trigger = "approve_complete"
+	ApproveStateStart           workflow.State = "approve_start"
+	ApproveStateValidated       workflow.State = "approve_validated"
+	ApproveStatePulled          workflow.State = "approve_pulled"
+	ApproveStateFetched         workflow.State = "approve_fetched"
+	ApproveStateRequestImported workflow.State = "approve_request_imported"
+	ApproveStateRequestFound    workflow.State = "approve_request_found"
+	ApproveStateScopeChosen     workflow.State = "approve_scope_chosen"
+	ApproveStatePreviewed       workflow.State = "approve_previewed"
+	ApproveStateKeyImported     workflow.State = "approve_key_imported"
+	ApproveStateCodeVerified    workflow.State = "approve_code_verified"
+	ApproveStateQuorumChecked   workflow.State = "approve_quorum_checked"
+	ApproveStateRekeyed         workflow.State = "approve_rekeyed"
+	ApproveStateKeyExported     workflow.State = "approve_key_exported"
+	ApproveStateExpiryRecorded  workflow.State = "approve_expiry_recorded"
+	ApproveStateCleaned         workflow.State = "approve_cleaned"
+	ApproveStatePushed          workflow.State = "approve_pushed"
+	ApproveStateBranchDeleted   workflow.State = "approve_branch_deleted"
+	ApproveStateComplete        workflow.State = "approve_complete"
+
+	ApproveTriggerValidate      workflow.Trigger = "approve_validate"
+	ApproveTriggerPull          workflow.Trigger = "approve_pull"
+	ApproveTriggerFetch         workflow.Trigger = "approve_fetch"
+	ApproveTriggerImportRequest workflow.Trigger = "approve_import_request"
+	ApproveTriggerFindRequest   workflow.Trigger = "approve_find_request"
+	ApproveTriggerChooseScope   workflow.Trigger = "approve_choose_scope"
+	ApproveTriggerPreview       workflow.Trigger = "approve_preview"
+	ApproveTriggerImportKey     workflow.Trigger = "approve_import_key"
+	ApproveTriggerVerifyCode    workflow.Trigger = "approve_verify_code"
+	ApproveTriggerCheckQuorum   workflow.Trigger = "approve_check_quorum"
+	ApproveTriggerRekey         workflow.Trigger = "approve_rekey"
+	ApproveTriggerExportKey     workflow.Trigger = "approve_export_key"
+	ApproveTriggerRecordExpiry  workflow.Trigger = "approve_record_expiry"
+	ApproveTriggerCleanup       workflow.Trigger = "approve_cleanup"
+	ApproveTriggerCommitPush    workflow.Trigger = "approve_commit_push"
+	ApproveTriggerDeleteBranch  workflow.Trigger = "approve_delete_branch"
+	ApproveTriggerComplete      workflow.Trigger = "approve_complete"
 )
 
+type ApproveOptions struct {
+	Flatten bool
+	// Expires, when non-zero, schedules the grant for revocation by
+	// `kepr access expire` after this long.
+	Expires time.Duration
+	// DryRun shows what approving would re-encrypt and stops there.
+	DryRun bool
+	// Path, when set, grants access to this path instead of the requested one.
+	Path string
+	// ChoosePath lets the approver pick the granted path among the requested
+	// path's subdirectories.
+	ChoosePath bool
+	// RequestFile approves a request exported with `kepr request --export`
+	// instead of one pushed to an access-request branch.
+	RequestFile string
+}
+
 type ApproveContext struct {
 	Context
-	UUIDPrefix string
-	Request    *store.PendingRequest
+	ApproveOptions
+	UUIDPrefix    string
+	Request       *store.PendingRequest
+	GrantPath     string
+	QuorumReached bool
+	// Kept lists the recipient files that already listed the requester
+	// before an expiring grant, so revoking it leaves them in place.
+	Kept []string
+}
+
+// stepImportRequest places an exported request file into the local requests
+// directory so the rest of the workflow can treat it like a fetched one.
+func (c *ApproveContext) stepImportRequest() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "import_request",
+		Execute: func(ctx context.Context) error {
+			if c.RequestFile == "" {
+				return nil
+			}
+
+			data, err := os.ReadFile(c.RequestFile)
+			if err != nil {
+				return fmt.Errorf("failed to read request file: %w", err)
+			}
+			uuid, encrypted, err := store.ParseRequestExport(data)
+			if err != nil {
+				return fmt.Errorf("failed to parse request file: %w", err)
+			}
+
+			requestsDir := filepath.Join(c.SecretsPath, "requests")
+			if err := os.MkdirAll(requestsDir, 0700); err != nil {
+				return fmt.Errorf("failed to create requests directory: %w", err)
+			}
+			if err := os.WriteFile(filepath.Join(requestsDir, uuid+".json.gpg"), encrypted, 0600); err != nil {
+				return fmt.Errorf("failed to write request: %w", err)
+			}
+
+			c.UUIDPrefix = uuid
+			c.UI.Successfln("Imported request %s from %s", uuid, c.RequestFile)
+			return nil
+		},
+	}
 }
 
 func (c *ApproveContext) stepFindRequest() workflow.StepConfig {
@@ -72,6 +146,9 @@ func (c *ApproveContext) stepFindRequest() workflow.StepConfig {
 			if err != nil {
 				return fmt.Errorf("failed to find request: %w", err)
 			}
+			if req.Invalid != "" {
+				return fmt.Errorf("refusing to approve request %s: %s", req.UUID, req.Invalid)
+			}
 			c.Request = req
 			c.UI.Successfln("Found request %s", req.UUID)
 			return nil
@@ -79,6 +156,152 @@ func (c *ApproveContext) stepFindRequest() workflow.StepConfig {
 	}
 }
 
+// stepChooseScope settles the path actually granted, which may be narrower
+// than or different from the one the requester asked for.
+func (c *ApproveContext) stepChooseScope() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "choose_scope",
+		Execute: func(ctx context.Context) error {
+			c.GrantPath = c.Request.Path
+
+			grantPath := c.ApproveOptions.Path
+			if grantPath == "" && c.ChoosePath {
+				chosen, err := c.pickSubdirectory()
+				if err != nil {
+					return err
+				}
+				grantPath = chosen
+			}
+			if grantPath == "" || grantPath == c.Request.Path {
+				return nil
+			}
+
+			normalized, err := store.NormalizePath(grantPath)
+			if err != nil {
+				return fmt.Errorf("invalid path %q: %w", grantPath, err)
+			}
+			c.GrantPath = normalized
+
+			if store.PathWithin(c.GrantPath, c.Request.Path) {
+				c.UI.Infofln("Narrowing request %s from %s to %s", c.Request.UUID, c.Request.Path, c.GrantPath)
+			} else {
+				c.UI.Warning(fmt.Sprintf("Granting %s although request %s asked for %s", c.GrantPath, c.Request.UUID, c.Request.Path))
+			}
+			return nil
+		},
+	}
+}
+
+func (c *ApproveContext) pickSubdirectory() (string, error) {
+	s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+	if err != nil {
+		return "", fmt.Errorf("failed to create store: %w", err)
+	}
+
+	entries, err := s.List(c.Request.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %w", c.Request.Path, err)
+	}
+
+	var subdirs []string
+	for _, entry := range entries {
+		if entry.Type == store.TypeDir {
+			subdirs = append(subdirs, c.Request.Path+"/"+entry.Name)
+		}
+	}
+	if len(subdirs) == 0 {
+		c.UI.Infofln("%s has no subdirectories to choose from", c.Request.Path)
+		return "", nil
+	}
+
+	c.UI.Infofln("Request %s asks for %s. Subdirectories:", c.Request.UUID, c.Request.Path)
+	for _, dir := range subdirs {
+		c.UI.Infofln("  %s", dir)
+	}
+
+	chosen, err := c.UI.Input("Path to grant", c.Request.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read path: %w", err)
+	}
+	return validateSubdirectory(chosen, c.Request.Path, subdirs)
+}
+
+// validateSubdirectory checks the path typed at the scope prompt: it must be
+// the requested path itself or one of the subdirectories listed.
+func validateSubdirectory(chosen, requested string, subdirs []string) (string, error) {
+	chosen = strings.TrimSpace(chosen)
+	if chosen == "" || chosen == requested {
+		return requested, nil
+	}
+	if !slices.Contains(subdirs, chosen) {
+		return "", fmt.Errorf("%s is not a subdirectory of %s", chosen, requested)
+	}
+	return chosen, nil
+}
+
+// stepPreview lists every directory the approval would re-encrypt and asks
+// the approver to go ahead, unless this is a dry run.
+func (c *ApproveContext) stepPreview() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "preview",
+		Execute: func(ctx context.Context) error {
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to create store: %w", err)
+			}
+
+			update := store.RecipientUpdate{Add: []string{c.Request.Fingerprint}, Flatten: c.Flatten}
+			plan, err := s.PlanRecipientUpdate(c.GrantPath, update)
+			if err != nil {
+				return fmt.Errorf("failed to plan rekey: %w", err)
+			}
+
+			c.UI.Infofln("Approving request %s gives %s access to %s:", c.Request.UUID, c.Request.Fingerprint, c.GrantPath)
+
+			dirs, secrets := 0, 0
+			for _, entry := range plan {
+				if !entry.Reencrypt {
+					continue
+				}
+				dirs++
+				secrets += entry.Secrets
+
+				line := fmt.Sprintf("  %s (%d secrets): %s -> %s", entry.Path, entry.Secrets,
+					formatRecipients(entry.Current), formatRecipients(entry.Updated))
+				if entry.Differs {
+					line += " [sub-folder has different recipients]"
+				}
+				c.UI.Infoln(line)
+			}
+			c.UI.Infofln("%d directories and %d secrets will be re-encrypted", dirs, secrets)
+
+			if c.DryRun {
+				return nil
+			}
+
+			proceed, err := c.UI.Confirm(fmt.Sprintf("Approve request %s?", c.Request.UUID))
+			if err != nil {
+				return fmt.Errorf("failed to get user confirmation: %w", err)
+			}
+			if !proceed {
+				return fmt.Errorf("approval of request %s cancelled", c.Request.UUID)
+			}
+			return nil
+		},
+	}
+}
+
+func formatRecipients(entries []string) string {
+	short := make([]string, len(entries))
+	for i, entry := range entries {
+		short[i] = entry
+		if !store.IsGroupRef(entry) && len(entry) > 16 {
+			short[i] = entry[:16]
+		}
+	}
+	return strings.Join(short, ", ")
+}
+
 func (c *ApproveContext) stepImportRequesterKey() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "import_requester_key",
@@ -92,32 +315,208 @@ func (c *ApproveContext) stepImportRequesterKey() workflow.StepConfig {
 	}
 }
 
-func (c *ApproveContext) stepRekey() workflow.StepConfig {
+// stepVerifyCode asks the approver to type back the code the requester sees,
+// so a swapped key or request is caught before anything is re-encrypted.
+func (c *ApproveContext) stepVerifyCode() workflow.StepConfig {
 	return workflow.StepConfig{
-		Name: "rekey",
+		Name: "verify_code",
+		Execute: func(ctx context.Context) error {
+			code := sas.Code(c.Request.Fingerprint, c.Request.UUID)
+			c.UI.Infofln("Verification code for request %s: %s", c.Request.UUID, code)
+
+			input, err := c.UI.Input("Type the code shown on the requesting machine", "")
+			if err != nil {
+				return fmt.Errorf("failed to read verification code: %w", err)
+			}
+			if !sas.Matches(code, input) {
+				return fmt.Errorf("verification code does not match; confirm the request with its owner before approving")
+			}
+
+			c.UI.Successfln("Verification code matches")
+			return nil
+		},
+	}
+}
+
+// stepCheckQuorum records this admin's signed approval on the request branch
+// when the store policy requires more than one approver. The remaining steps
+// only run once enough distinct admins have approved.
+func (c *ApproveContext) stepCheckQuorum() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "check_quorum",
 		Execute: func(ctx context.Context) error {
+			policy, err := store.LoadPolicy(c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			required := policy.RequiredApprovals(c.GrantPath)
+			if required <= 1 {
+				c.QuorumReached = true
+				return c.recordApprovals(nil, []string{c.Fingerprint})
+			}
+			if c.RequestFile != "" {
+				return fmt.Errorf("%s requires %d approvals, which imported requests do not support", c.GrantPath, required)
+			}
+
 			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
 			if err != nil {
 				return fmt.Errorf("failed to create store: %w", err)
 			}
 
-			targetDir, err := s.ResolvePath(c.Request.Path)
+			admins, err := s.ReadRecipients(c.SecretsPath)
+			if err != nil {
+				return fmt.Errorf("failed to read root recipients: %w", err)
+			}
+			if !slices.Contains(admins, c.Fingerprint) {
+				return fmt.Errorf("only root recipients can approve requests for %s", c.GrantPath)
+			}
+
+			branchName := "access-request/" + c.Request.UUID
+			gitClient := git.NewWithAuth(c.Token)
+
+			if _, err := gitClient.FetchBranches(c.SecretsPath, "origin", branchName); err != nil {
+				return fmt.Errorf("failed to fetch request branch: %w", err)
+			}
+
+			files, err := gitClient.ReadFilesFromBranch(c.SecretsPath, "origin", branchName, store.ApprovalsDir)
+			if err != nil {
+				return fmt.Errorf("failed to read approvals: %w", err)
+			}
+
+			approvers := store.VerifiedApprovers(c.GPG, files, c.Request.UUID, c.GrantPath, admins)
+			alreadyApproved := slices.Contains(approvers, c.Fingerprint)
+			if !alreadyApproved {
+				approvers = append(approvers, c.Fingerprint)
+			}
+
+			if len(approvers) >= required {
+				if err := c.recordApprovals(files, approvers); err != nil {
+					return err
+				}
+				c.QuorumReached = true
+				c.UI.Successfln("Quorum reached for request %s (%d of %d approvals)", c.Request.UUID, len(approvers), required)
+				return nil
+			}
+
+			if alreadyApproved {
+				c.UI.Infofln("You already approved request %s (%d of %d approvals)", c.Request.UUID, len(approvers), required)
+				return nil
+			}
+
+			signed, err := c.signApproval()
+			if err != nil {
+				return err
+			}
+			signer, err := common.CommitSigner(c.GPG, c.Fingerprint, c.UserEmail)
+			if err != nil {
+				return err
+			}
+			gitClient.SetSigner(signer)
+
+			name := store.ApprovalFileName(c.Request.UUID, c.Fingerprint)
+			message := fmt.Sprintf("Approve access request %s (%d of %d)", c.Request.UUID, len(approvers), required)
+			if err := gitClient.CommitFilesToBranch(c.SecretsPath, "origin", branchName,
+				map[string][]byte{store.ApprovalsDir + "/" + name: signed},
+				message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to record approval: %w", err)
+			}
+
+			if err := gitClient.PushBranch(c.SecretsPath, "origin", branchName); err != nil {
+				return fmt.Errorf("failed to push approval: %w", err)
+			}
+
+			c.UI.Successfln("Recorded approval %d of %d for request %s", len(approvers), required, c.Request.UUID)
+			c.UI.Infofln("Another admin must approve before access is granted")
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Recording approval failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func (c *ApproveContext) signApproval() ([]byte, error) {
+	if err := c.GPG.RequireSigningKey(c.Fingerprint); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := c.GPG.ExportPublicKey(c.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export public key: %w", err)
+	}
+
+	return store.SignApproval(c.GPG, store.Approval{
+		RequestUUID: c.Request.UUID,
+		Approver:    c.Fingerprint,
+		Path:        c.Request.Path,
+		GrantPath:   c.GrantPath,
+		Requester:   c.Request.Fingerprint,
+		PublicKey:   string(publicKey),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// recordApprovals copies the signed approvals into approvals/ on main so the
+// final commit keeps a record of who approved the grant and which path it
+// covers; the requester reads the granted path from there.
+func (c *ApproveContext) recordApprovals(files map[string][]byte, approvers []string) error {
+	dir := filepath.Join(c.SecretsPath, store.ApprovalsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create approvals directory: %w", err)
+	}
+
+	for _, fp := range approvers {
+		name := store.ApprovalFileName(c.Request.UUID, fp)
+		data, ok := files[name]
+		if !ok {
+			signed, err := c.signApproval()
 			if err != nil {
-				return fmt.Errorf("failed to resolve path %q: %w", c.Request.Path, err)
+				return err
 			}
+			data = signed
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return fmt.Errorf("failed to write approval %s: %w", name, err)
+		}
+	}
+
+	return store.EnsureGitignore(c.SecretsPath)
+}
 
-			existingFingerprints, err := store.ReadGpgID(targetDir)
+func (c *ApproveContext) stepRekey() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "rekey",
+		Execute: func(ctx context.Context) error {
+			if !c.QuorumReached {
+				return nil
+			}
+			s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
 			if err != nil {
-				return fmt.Errorf("failed to read existing fingerprints: %w", err)
+				return fmt.Errorf("failed to create store: %w", err)
 			}
 
-			updatedFingerprints := append(existingFingerprints, c.Request.Fingerprint)
+			if c.Expires != 0 {
+				c.Kept, err = s.RecipientFiles(c.GrantPath, c.Request.Fingerprint)
+				if err != nil {
+					return fmt.Errorf("failed to read current recipients: %w", err)
+				}
+			}
 
-			c.UI.Infofln("Rekeying %s and subfolders", c.Request.Path)
-			if err := s.Rekey(targetDir, updatedFingerprints, c.Request.Path); err != nil {
+			c.UI.Infofln("Rekeying %s and subfolders", c.GrantPath)
+			if err := s.AddRecipient(c.GrantPath, c.Request.Fingerprint, c.Flatten); err != nil {
 				return fmt.Errorf("failed to rekey: %w", err)
 			}
 
+			if c.Expires == 0 {
+				if err := s.KeepPermanentGrant(c.GrantPath, c.Request.Fingerprint, false); err != nil {
+					return fmt.Errorf("failed to update expirations: %w", err)
+				}
+			}
+
 			c.UI.Successfln("Rekeying complete")
 			return nil
 		},
@@ -128,22 +527,39 @@ func (c *ApproveContext) stepExportRequesterKey() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "export_key",
 		Execute: func(ctx context.Context) error {
-			keysDir := filepath.Join(c.SecretsPath, "keys")
-			if err := os.MkdirAll(keysDir, 0700); err != nil {
-				return fmt.Errorf("failed to create keys directory: %w", err)
+			if !c.QuorumReached {
+				return nil
+			}
+			if err := store.SavePublicKey(c.SecretsPath, c.GPG, c.Request.Fingerprint); err != nil {
+				return fmt.Errorf("failed to export requester public key: %w", err)
+			}
+
+			c.UI.Successfln("Exported requester public key to keys/")
+			return nil
+		},
+	}
+}
+
+func (c *ApproveContext) stepRecordExpiry() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "record_expiry",
+		Execute: func(ctx context.Context) error {
+			if !c.QuorumReached || c.Expires == 0 {
+				return nil
 			}
 
-			pubKey, err := c.GPG.ExportPublicKey(c.Request.Fingerprint)
+			expirations, err := store.LoadExpirations(c.SecretsPath)
 			if err != nil {
-				return fmt.Errorf("failed to export requester public key: %w", err)
+				return err
 			}
 
-			keyPath := filepath.Join(keysDir, c.Request.Fingerprint+".key")
-			if err := os.WriteFile(keyPath, pubKey, 0644); err != nil {
-				return fmt.Errorf("failed to write requester public key: %w", err)
+			expires := time.Now().UTC().Add(c.Expires).Truncate(time.Second)
+			expirations.Set(c.Request.Fingerprint, c.GrantPath, expires, c.Kept)
+			if err := store.SaveExpirations(c.SecretsPath, expirations); err != nil {
+				return err
 			}
 
-			c.UI.Successfln("Exported requester public key to keys/")
+			c.UI.Successfln("Access to %s expires on %s", c.GrantPath, expires.Format(time.RFC3339))
 			return nil
 		},
 	}
@@ -153,6 +569,9 @@ func (c *ApproveContext) stepCleanupRequest() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "cleanup",
 		Execute: func(ctx context.Context) error {
+			if !c.QuorumReached {
+				return nil
+			}
 			requestPath := filepath.Join(c.SecretsPath, "requests", c.Request.UUID+".json.gpg")
 			if err := os.Remove(requestPath); err != nil && !os.IsNotExist(err) {
 				return fmt.Errorf("failed to remove request file: %w", err)
@@ -167,13 +586,25 @@ func (c *ApproveContext) stepApproveCommitAndPush() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "commit_and_push",
 		Execute: func(ctx context.Context) error {
-			gitClient := git.NewWithAuth(c.Token)
+			if !c.QuorumReached {
+				return nil
+			}
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
 
 			message := fmt.Sprintf("Approve access request %s", c.Request.UUID)
 			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
 				return fmt.Errorf("failed to commit: %w", err)
 			}
 
+			if c.RequestFile == "" {
+				if pr := c.findPullRequest(c.Request.UUID); pr != nil {
+					return c.mergePullRequest(gitClient, pr, message)
+				}
+			}
+
 			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
 				return fmt.Errorf("failed to push: %w", err)
 			}
@@ -190,10 +621,38 @@ func (c *ApproveContext) stepApproveCommitAndPush() workflow.StepConfig {
 	}
 }
 
+// mergePullRequest lands the approval through the requester's pull request:
+// the rekey commit goes onto the request branch, which is then merged.
+func (c *ApproveContext) mergePullRequest(gitClient *git.Git, pr *github.PullRequest, message string) error {
+	branchName := "access-request/" + c.Request.UUID
+
+	if _, err := gitClient.FetchBranches(c.SecretsPath, "origin", branchName); err != nil {
+		return fmt.Errorf("failed to fetch request branch: %w", err)
+	}
+	if err := gitClient.CommitHeadToBranch(c.SecretsPath, "origin", branchName, message, c.UserName, c.UserEmail); err != nil {
+		return fmt.Errorf("failed to commit to request branch: %w", err)
+	}
+	if err := gitClient.PushBranch(c.SecretsPath, "origin", branchName); err != nil {
+		return fmt.Errorf("failed to push request branch: %w", err)
+	}
+	if err := c.GitHub.MergePullRequest(c.RepoPath, pr.Number, message); err != nil {
+		return err
+	}
+	if err := c.pullMain(); err != nil {
+		return err
+	}
+
+	c.UI.Successfln("Merged pull request #%d into main", pr.Number)
+	return nil
+}
+
 func (c *ApproveContext) stepDeleteBranch() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "delete_branch",
 		Execute: func(ctx context.Context) error {
+			if !c.QuorumReached || c.RequestFile != "" {
+				return nil
+			}
 			branchName := "access-request/" + c.Request.UUID
 			gitClient := git.NewWithAuth(c.Token)
 
@@ -209,15 +668,33 @@ func (c *ApproveContext) stepDeleteBranch() workflow.StepConfig {
 }
 
 func (c *ApproveContext) approveRequest(ctx context.Context) error {
+	if err := c.stepChooseScope().Execute(ctx); err != nil {
+		return err
+	}
+	if err := c.stepPreview().Execute(ctx); err != nil {
+		return err
+	}
+	if c.DryRun {
+		return nil
+	}
 	if err := c.stepImportRequesterKey().Execute(ctx); err != nil {
 		return err
 	}
+	if err := c.stepVerifyCode().Execute(ctx); err != nil {
+		return err
+	}
+	if err := c.stepCheckQuorum().Execute(ctx); err != nil {
+		return err
+	}
 	if err := c.stepRekey().Execute(ctx); err != nil {
 		return err
 	}
 	if err := c.stepExportRequesterKey().Execute(ctx); err != nil {
 		return err
 	}
+	if err := c.stepRecordExpiry().Execute(ctx); err != nil {
+		return err
+	}
 	if err := c.stepCleanupRequest().Execute(ctx); err != nil {
 		return err
 	}
@@ -232,6 +709,7 @@ func (c *ApproveContext) approveRequest(ctx context.Context) error {
 
 type ApproveByEmailContext struct {
 	Context
+	ApproveOptions
 	Email string
 }
 
@@ -259,8 +737,9 @@ func (c *ApproveByEmailContext) stepApproveAllByEmail() workflow.StepConfig {
 			for _, req := range matches {
 				reqCopy := req
 				ac := &ApproveContext{
-					Context: c.Context,
-					Request: &reqCopy,
+					Context:        c.Context,
+					ApproveOptions: c.ApproveOptions,
+					Request:        &reqCopy,
 				}
 				c.UI.Infofln("Approving request %s for path %s", req.UUID, req.Path)
 				if err := ac.approveRequest(ctx); err != nil {
@@ -288,7 +767,7 @@ const (
 	ApproveByEmailTriggerComplete workflow.Trigger = "approve_email_complete"
 )
 
-func NewApproveByEmailWorkflow(email, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+func NewApproveByEmailWorkflow(email string, opts ApproveOptions, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
 	c := &ApproveByEmailContext{
 		Context: Context{
 			Shell:    sh,
@@ -296,7 +775,8 @@ func NewApproveByEmailWorkflow(email, repoPath string, gh github.Client, sh shel
 			GitHub:   gh,
 			RepoPath: repoPath,
 		},
-		Email: email,
+		ApproveOptions: opts,
+		Email:          email,
 	}
 
 	w := workflow.New(ApproveByEmailStateStart)
@@ -331,7 +811,7 @@ func NewApproveByEmailWorkflow(email, repoPath string, gh github.Client, sh shel
 	return w
 }
 
-func NewApproveWorkflow(uuidPrefix, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+func NewApproveWorkflow(uuidPrefix string, opts ApproveOptions, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
 	c := &ApproveContext{
 		Context: Context{
 			Shell:    sh,
@@ -339,7 +819,8 @@ func NewApproveWorkflow(uuidPrefix, repoPath string, gh github.Client, sh shell.
 			GitHub:   gh,
 			RepoPath: repoPath,
 		},
-		UUIDPrefix: uuidPrefix,
+		ApproveOptions: opts,
+		UUIDPrefix:     uuidPrefix,
 	}
 
 	w := workflow.New(ApproveStateStart)
@@ -357,14 +838,50 @@ func NewApproveWorkflow(uuidPrefix, repoPath string, gh github.Client, sh shell.
 
 	w.Configure(ApproveStateFetched).
 		OnEntryFrom(ApproveTriggerFetch, entryWithRetry(c.stepFetchRequests())).
+		Permit(ApproveTriggerImportRequest, ApproveStateRequestImported)
+
+	w.Configure(ApproveStateRequestImported).
+		OnEntryFrom(ApproveTriggerImportRequest, entryWithRetry(c.stepImportRequest())).
 		Permit(ApproveTriggerFindRequest, ApproveStateRequestFound)
 
 	w.Configure(ApproveStateRequestFound).
 		OnEntryFrom(ApproveTriggerFindRequest, entryWithRetry(c.stepFindRequest())).
-		Permit(ApproveTriggerImportKey, ApproveStateKeyImported)
+		Permit(ApproveTriggerChooseScope, ApproveStateScopeChosen)
+
+	w.Configure(ApproveStateScopeChosen).
+		OnEntryFrom(ApproveTriggerChooseScope, entryWithRetry(c.stepChooseScope())).
+		Permit(ApproveTriggerPreview, ApproveStatePreviewed)
+
+	previewed := w.Configure(ApproveStatePreviewed).
+		OnEntryFrom(ApproveTriggerPreview, entryWithRetry(c.stepPreview()))
+
+	if opts.DryRun {
+		previewed.Permit(ApproveTriggerComplete, ApproveStateComplete)
+		w.Configure(ApproveStateComplete)
+
+		w.AddTrigger(ApproveTriggerValidate)
+		w.AddTrigger(ApproveTriggerPull)
+		w.AddTrigger(ApproveTriggerFetch)
+		w.AddTrigger(ApproveTriggerImportRequest)
+		w.AddTrigger(ApproveTriggerFindRequest)
+		w.AddTrigger(ApproveTriggerChooseScope)
+		w.AddTrigger(ApproveTriggerPreview)
+		w.AddTrigger(ApproveTriggerComplete)
+		return w
+	}
+
+	previewed.Permit(ApproveTriggerImportKey, ApproveStateKeyImported)
 
 	w.Configure(ApproveStateKeyImported).
 		OnEntryFrom(ApproveTriggerImportKey, entryWithRetry(c.stepImportRequesterKey())).
+		Permit(ApproveTriggerVerifyCode, ApproveStateCodeVerified)
+
+	w.Configure(ApproveStateCodeVerified).
+		OnEntryFrom(ApproveTriggerVerifyCode, entryWithRetry(c.stepVerifyCode())).
+		Permit(ApproveTriggerCheckQuorum, ApproveStateQuorumChecked)
+
+	w.Configure(ApproveStateQuorumChecked).
+		OnEntryFrom(ApproveTriggerCheckQuorum, entryWithRetry(c.stepCheckQuorum())).
 		Permit(ApproveTriggerRekey, ApproveStateRekeyed)
 
 	w.Configure(ApproveStateRekeyed).
@@ -373,6 +890,10 @@ func NewApproveWorkflow(uuidPrefix, repoPath string, gh github.Client, sh shell.
 
 	w.Configure(ApproveStateKeyExported).
 		OnEntryFrom(ApproveTriggerExportKey, entryWithRetry(c.stepExportRequesterKey())).
+		Permit(ApproveTriggerRecordExpiry, ApproveStateExpiryRecorded)
+
+	w.Configure(ApproveStateExpiryRecorded).
+		OnEntryFrom(ApproveTriggerRecordExpiry, entryWithRetry(c.stepRecordExpiry())).
 		Permit(ApproveTriggerCleanup, ApproveStateCleaned)
 
 	w.Configure(ApproveStateCleaned).
@@ -392,10 +913,16 @@ func NewApproveWorkflow(uuidPrefix, repoPath string, gh github.Client, sh shell.
 	w.AddTrigger(ApproveTriggerValidate)
 	w.AddTrigger(ApproveTriggerPull)
 	w.AddTrigger(ApproveTriggerFetch)
+	w.AddTrigger(ApproveTriggerImportRequest)
 	w.AddTrigger(ApproveTriggerFindRequest)
+	w.AddTrigger(ApproveTriggerChooseScope)
+	w.AddTrigger(ApproveTriggerPreview)
 	w.AddTrigger(ApproveTriggerImportKey)
+	w.AddTrigger(ApproveTriggerVerifyCode)
+	w.AddTrigger(ApproveTriggerCheckQuorum)
 	w.AddTrigger(ApproveTriggerRekey)
 	w.AddTrigger(ApproveTriggerExportKey)
+	w.AddTrigger(ApproveTriggerRecordExpiry)
 	w.AddTrigger(ApproveTriggerCleanup)
 	w.AddTrigger(ApproveTriggerCommitPush)
 	w.AddTrigger(ApproveTriggerDeleteBranch)
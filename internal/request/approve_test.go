@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gonzaloalvarez/kepr/pkg/sas"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+	"github.com/gonzaloalvarez/kepr/tests/mocks"
+)
+
+func TestValidateSubdirectory(t *testing.T) {
+	subdirs := []string{"prod/app", "prod/payments"}
+
+	tests := []struct {
+		chosen  string
+		want    string
+		wantErr bool
+	}{
+		{"", "prod", false},
+		{"prod", "prod", false},
+		{" prod/app \n", "prod/app", false},
+		{"prod/payments", "prod/payments", false},
+		{"prod/payments/stripe", "", true},
+		{"staging", "", true},
+		{"prod/app/../payments", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := validateSubdirectory(tt.chosen, "prod", subdirs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateSubdirectory(%q) error = %v, wantErr %v", tt.chosen, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("validateSubdirectory(%q) = %q, want %q", tt.chosen, got, tt.want)
+		}
+	}
+}
+
+func TestStepVerifyCode(t *testing.T) {
+	t.Setenv("KEPR_CI", "true")
+	request := &store.PendingRequest{UUID: "3f2a", Fingerprint: "FP_AAA"}
+
+	for _, tt := range []struct {
+		input   string
+		wantErr bool
+	}{
+		{sas.Code(request.Fingerprint, request.UUID), false},
+		{"", true},
+		{"000-000", true},
+	} {
+		ui := mocks.NewMockUI()
+		ui.TextInputs = []string{tt.input}
+		c := &ApproveContext{Context: Context{UI: ui}, Request: request}
+
+		err := c.stepVerifyCode().Execute(context.Background())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("stepVerifyCode() with input %q error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+	}
+}
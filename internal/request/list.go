@@ -19,6 +19,7 @@ package request
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gonzaloalvarez/kepr/internal/workflow"
 	"github.com/gonzaloalvarez/kepr/pkg/cout"
@@ -62,8 +63,18 @@ func (c *ListContext) stepListDisplay() workflow.StepConfig {
 			}
 
 			for _, req := range requests {
+				if req.Invalid != "" {
+					c.UI.Warning(fmt.Sprintf("%s - %s - unverified: %s", req.UUID, req.Path, req.Invalid))
+					continue
+				}
 				name, email := resolveIdentity(c.GPG, req)
 				c.UI.Infofln("%s - %s - %s - %s", req.UUID, name, email, req.Path)
+				if details := requestDetails(req); details != "" {
+					c.UI.Infofln("  %s", details)
+				}
+				if req.Reason != "" {
+					c.UI.Infofln("  Reason: %s", req.Reason)
+				}
 			}
 
 			return nil
@@ -71,6 +82,28 @@ func (c *ListContext) stepListDisplay() workflow.StepConfig {
 	}
 }
 
+// requestDetails summarises the machine facts a requester attached, which
+// older kepr versions do not send.
+func requestDetails(req store.PendingRequest) string {
+	var parts []string
+	if req.Hostname != "" {
+		parts = append(parts, "host "+req.Hostname)
+	}
+	if req.OS != "" {
+		parts = append(parts, req.OS+"/"+req.Arch)
+	}
+	if req.KeprVersion != "" {
+		parts = append(parts, "kepr "+req.KeprVersion)
+	}
+	if req.GitHubLogin != "" {
+		parts = append(parts, "GitHub @"+req.GitHubLogin)
+	}
+	if req.Timestamp != "" {
+		parts = append(parts, "requested "+req.Timestamp)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func resolveIdentity(g *gpg.GPG, req store.PendingRequest) (string, string) {
 	_ = g.ImportPublicKey([]byte(req.PublicKey))
 
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"testing"
+
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+func TestRequestDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		req  store.PendingRequest
+		want string
+	}{
+		{
+			name: "all facts",
+			req: store.PendingRequest{
+				Hostname:    "build-01",
+				OS:          "linux",
+				Arch:        "amd64",
+				KeprVersion: "1.2.0",
+				GitHubLogin: "octocat",
+				Timestamp:   "2025-01-02T03:04:05Z",
+			},
+			want: "host build-01, linux/amd64, kepr 1.2.0, GitHub @octocat, requested 2025-01-02T03:04:05Z",
+		},
+		{
+			name: "older request",
+			req:  store.PendingRequest{Timestamp: "2025-01-02T03:04:05Z"},
+			want: "requested 2025-01-02T03:04:05Z",
+		},
+		{
+			name: "nothing",
+			req:  store.PendingRequest{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestDetails(tt.req); got != tt.want {
+				t.Errorf("requestDetails() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	PruneStateStart           workflow.State = "prune_start"
+	PruneStateValidated       workflow.State = "prune_validated"
+	PruneStatePulled          workflow.State = "prune_pulled"
+	PruneStateFetched         workflow.State = "prune_fetched"
+	PruneStateSelected        workflow.State = "prune_selected"
+	PruneStateCleaned         workflow.State = "prune_cleaned"
+	PruneStatePushed          workflow.State = "prune_pushed"
+	PruneStateBranchesDeleted workflow.State = "prune_branches_deleted"
+	PruneStateComplete        workflow.State = "prune_complete"
+
+	PruneTriggerValidate       workflow.Trigger = "prune_validate"
+	PruneTriggerPull           workflow.Trigger = "prune_pull"
+	PruneTriggerFetch          workflow.Trigger = "prune_fetch"
+	PruneTriggerSelect         workflow.Trigger = "prune_select"
+	PruneTriggerCleanup        workflow.Trigger = "prune_cleanup"
+	PruneTriggerCommitPush     workflow.Trigger = "prune_commit_push"
+	PruneTriggerDeleteBranches workflow.Trigger = "prune_delete_branches"
+	PruneTriggerComplete       workflow.Trigger = "prune_complete"
+)
+
+type PruneContext struct {
+	Context
+	OlderThan time.Duration
+	// DryRun lists the requests that would be pruned and stops there.
+	DryRun bool
+	Stale  []store.StaleRequest
+}
+
+func (c *PruneContext) stepSelectStale() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "select_stale",
+		Execute: func(ctx context.Context) error {
+			requests, err := store.ListRequests(c.SecretsPath, c.GPG)
+			if err != nil {
+				return fmt.Errorf("failed to list requests: %w", err)
+			}
+
+			c.Stale = store.FindStaleRequests(requests, c.OlderThan, time.Now())
+			if len(c.Stale) == 0 {
+				c.UI.Infoln("No stale or duplicate access requests")
+				return nil
+			}
+
+			for _, s := range c.Stale {
+				c.UI.Infofln("%s - %s - %s", s.Request.UUID, s.Request.Path, s.Reason)
+			}
+
+			if c.DryRun {
+				return nil
+			}
+
+			proceed, err := c.UI.Confirm(fmt.Sprintf("Prune %d access request(s) and delete their branches?", len(c.Stale)))
+			if err != nil {
+				return fmt.Errorf("failed to get user confirmation: %w", err)
+			}
+			if !proceed {
+				return fmt.Errorf("pruning cancelled")
+			}
+			return nil
+		},
+	}
+}
+
+func (c *PruneContext) stepPruneCleanup() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "cleanup",
+		Execute: func(ctx context.Context) error {
+			for _, s := range c.Stale {
+				requestPath := filepath.Join(c.SecretsPath, "requests", s.Request.UUID+".json.gpg")
+				if err := os.Remove(requestPath); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove request file: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (c *PruneContext) stepPruneCommitAndPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "commit_and_push",
+		Execute: func(ctx context.Context) error {
+			if len(c.Stale) == 0 {
+				return nil
+			}
+
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			message := fmt.Sprintf("Prune %d access request(s)", len(c.Stale))
+			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to commit: %w", err)
+			}
+
+			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func (c *PruneContext) stepDeleteBranches() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "delete_branches",
+		Execute: func(ctx context.Context) error {
+			if len(c.Stale) == 0 {
+				return nil
+			}
+
+			gitClient := git.NewWithAuth(c.Token)
+
+			pruned := 0
+			for _, s := range c.Stale {
+				branchName := "access-request/" + s.Request.UUID
+				c.closePullRequest(s.Request.UUID)
+				if err := gitClient.DeleteRemoteBranch(c.SecretsPath, "origin", branchName); err != nil {
+					c.UI.Warning(fmt.Sprintf("Failed to delete remote branch %s: %v", branchName, err))
+					continue
+				}
+				pruned++
+			}
+
+			c.UI.Successfln("Pruned %d access request(s)", pruned)
+			return nil
+		},
+	}
+}
+
+func NewPruneWorkflow(olderThan time.Duration, dryRun bool, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &PruneContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+		},
+		OlderThan: olderThan,
+		DryRun:    dryRun,
+	}
+
+	w := workflow.New(PruneStateStart)
+
+	w.Configure(PruneStateStart).
+		Permit(PruneTriggerValidate, PruneStateValidated)
+
+	w.Configure(PruneStateValidated).
+		OnEntryFrom(PruneTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(PruneTriggerPull, PruneStatePulled)
+
+	w.Configure(PruneStatePulled).
+		OnEntryFrom(PruneTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(PruneTriggerFetch, PruneStateFetched)
+
+	w.Configure(PruneStateFetched).
+		OnEntryFrom(PruneTriggerFetch, entryWithRetry(c.stepFetchRequests())).
+		Permit(PruneTriggerSelect, PruneStateSelected)
+
+	selected := w.Configure(PruneStateSelected).
+		OnEntryFrom(PruneTriggerSelect, entryWithRetry(c.stepSelectStale()))
+
+	if dryRun {
+		selected.Permit(PruneTriggerComplete, PruneStateComplete)
+		w.Configure(PruneStateComplete)
+
+		w.AddTrigger(PruneTriggerValidate)
+		w.AddTrigger(PruneTriggerPull)
+		w.AddTrigger(PruneTriggerFetch)
+		w.AddTrigger(PruneTriggerSelect)
+		w.AddTrigger(PruneTriggerComplete)
+		return w
+	}
+
+	selected.Permit(PruneTriggerCleanup, PruneStateCleaned)
+
+	w.Configure(PruneStateCleaned).
+		OnEntryFrom(PruneTriggerCleanup, entryWithRetry(c.stepPruneCleanup())).
+		Permit(PruneTriggerCommitPush, PruneStatePushed)
+
+	w.Configure(PruneStatePushed).
+		OnEntryFrom(PruneTriggerCommitPush, entryWithRetry(c.stepPruneCommitAndPush())).
+		Permit(PruneTriggerDeleteBranches, PruneStateBranchesDeleted)
+
+	w.Configure(PruneStateBranchesDeleted).
+		OnEntryFrom(PruneTriggerDeleteBranches, entryWithRetry(c.stepDeleteBranches())).
+		Permit(PruneTriggerComplete, PruneStateComplete)
+
+	w.Configure(PruneStateComplete)
+
+	w.AddTrigger(PruneTriggerValidate)
+	w.AddTrigger(PruneTriggerPull)
+	w.AddTrigger(PruneTriggerFetch)
+	w.AddTrigger(PruneTriggerSelect)
+	w.AddTrigger(PruneTriggerCleanup)
+	w.AddTrigger(PruneTriggerCommitPush)
+	w.AddTrigger(PruneTriggerDeleteBranches)
+	w.AddTrigger(PruneTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,238 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	RejectStateStart         workflow.State = "reject_start"
+	RejectStateValidated     workflow.State = "reject_validated"
+	RejectStatePulled        workflow.State = "reject_pulled"
+	RejectStateFetched       workflow.State = "reject_fetched"
+	RejectStateRequestFound  workflow.State = "reject_request_found"
+	RejectStateRecorded      workflow.State = "reject_recorded"
+	RejectStateCleaned       workflow.State = "reject_cleaned"
+	RejectStatePushed        workflow.State = "reject_pushed"
+	RejectStateBranchDeleted workflow.State = "reject_branch_deleted"
+	RejectStateComplete      workflow.State = "reject_complete"
+
+	RejectTriggerValidate     workflow.Trigger = "reject_validate"
+	RejectTriggerPull         workflow.Trigger = "reject_pull"
+	RejectTriggerFetch        workflow.Trigger = "reject_fetch"
+	RejectTriggerFindRequest  workflow.Trigger = "reject_find_request"
+	RejectTriggerRecord       workflow.Trigger = "reject_record"
+	RejectTriggerCleanup      workflow.Trigger = "reject_cleanup"
+	RejectTriggerCommitPush   workflow.Trigger = "reject_commit_push"
+	RejectTriggerDeleteBranch workflow.Trigger = "reject_delete_branch"
+	RejectTriggerComplete     workflow.Trigger = "reject_complete"
+)
+
+type RejectContext struct {
+	Context
+	UUIDPrefix string
+	Reason     string
+	Request    *store.PendingRequest
+}
+
+func (c *RejectContext) stepFindRequest() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "find_request",
+		Execute: func(ctx context.Context) error {
+			req, err := store.FindRequestByPrefix(c.SecretsPath, c.GPG, c.UUIDPrefix)
+			if err != nil {
+				return fmt.Errorf("failed to find request: %w", err)
+			}
+			c.Request = req
+			c.UI.Successfln("Found request %s", req.UUID)
+			return nil
+		},
+	}
+}
+
+func (c *RejectContext) stepRecordRejection() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "record_rejection",
+		Execute: func(ctx context.Context) error {
+			if c.Request.Invalid != "" {
+				c.UI.Warning(fmt.Sprintf("Request failed verification (%s); not notifying the requester", c.Request.Invalid))
+				return nil
+			}
+
+			if err := c.GPG.ImportPublicKey([]byte(c.Request.PublicKey)); err != nil {
+				return fmt.Errorf("failed to import requester public key: %w", err)
+			}
+
+			rejection := store.Rejection{
+				RequestUUID: c.Request.UUID,
+				Fingerprint: c.Request.Fingerprint,
+				Path:        c.Request.Path,
+				Reason:      c.Reason,
+				RejectedBy:  fmt.Sprintf("%s <%s>", c.UserName, c.UserEmail),
+				Signer:      c.Fingerprint,
+				Timestamp:   time.Now().UTC().Format(time.RFC3339),
+			}
+			if err := store.WriteRejection(c.SecretsPath, c.GPG, rejection); err != nil {
+				return err
+			}
+
+			c.UI.Successfln("Recorded rejection for the requester")
+			return nil
+		},
+	}
+}
+
+func (c *RejectContext) stepCleanupRequest() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "cleanup",
+		Execute: func(ctx context.Context) error {
+			requestPath := filepath.Join(c.SecretsPath, "requests", c.Request.UUID+".json.gpg")
+			if err := os.Remove(requestPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove request file: %w", err)
+			}
+			c.UI.Successfln("Removed request file")
+			return nil
+		},
+	}
+}
+
+func (c *RejectContext) stepRejectCommitAndPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "commit_and_push",
+		Execute: func(ctx context.Context) error {
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			message := fmt.Sprintf("Reject access request %s", c.Request.UUID)
+			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
+				return fmt.Errorf("failed to commit: %w", err)
+			}
+
+			if err := gitClient.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+
+			c.UI.Successfln("Committed and pushed to main")
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func (c *RejectContext) stepDeleteBranch() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "delete_branch",
+		Execute: func(ctx context.Context) error {
+			branchName := "access-request/" + c.Request.UUID
+			gitClient := git.NewWithAuth(c.Token)
+
+			c.closePullRequest(c.Request.UUID)
+
+			if err := gitClient.DeleteRemoteBranch(c.SecretsPath, "origin", branchName); err != nil {
+				c.UI.Warning(fmt.Sprintf("Failed to delete remote branch %s: %v", branchName, err))
+				return nil
+			}
+
+			c.UI.Successfln("Rejected request %s and deleted branch %s", c.Request.UUID, branchName)
+			return nil
+		},
+	}
+}
+
+func NewRejectWorkflow(uuidPrefix, reason, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &RejectContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+		},
+		UUIDPrefix: uuidPrefix,
+		Reason:     reason,
+	}
+
+	w := workflow.New(RejectStateStart)
+
+	w.Configure(RejectStateStart).
+		Permit(RejectTriggerValidate, RejectStateValidated)
+
+	w.Configure(RejectStateValidated).
+		OnEntryFrom(RejectTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(RejectTriggerPull, RejectStatePulled)
+
+	w.Configure(RejectStatePulled).
+		OnEntryFrom(RejectTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(RejectTriggerFetch, RejectStateFetched)
+
+	w.Configure(RejectStateFetched).
+		OnEntryFrom(RejectTriggerFetch, entryWithRetry(c.stepFetchRequests())).
+		Permit(RejectTriggerFindRequest, RejectStateRequestFound)
+
+	w.Configure(RejectStateRequestFound).
+		OnEntryFrom(RejectTriggerFindRequest, entryWithRetry(c.stepFindRequest())).
+		Permit(RejectTriggerRecord, RejectStateRecorded)
+
+	w.Configure(RejectStateRecorded).
+		OnEntryFrom(RejectTriggerRecord, entryWithRetry(c.stepRecordRejection())).
+		Permit(RejectTriggerCleanup, RejectStateCleaned)
+
+	w.Configure(RejectStateCleaned).
+		OnEntryFrom(RejectTriggerCleanup, entryWithRetry(c.stepCleanupRequest())).
+		Permit(RejectTriggerCommitPush, RejectStatePushed)
+
+	w.Configure(RejectStatePushed).
+		OnEntryFrom(RejectTriggerCommitPush, entryWithRetry(c.stepRejectCommitAndPush())).
+		Permit(RejectTriggerDeleteBranch, RejectStateBranchDeleted)
+
+	w.Configure(RejectStateBranchDeleted).
+		OnEntryFrom(RejectTriggerDeleteBranch, entryWithRetry(c.stepDeleteBranch())).
+		Permit(RejectTriggerComplete, RejectStateComplete)
+
+	w.Configure(RejectStateComplete)
+
+	w.AddTrigger(RejectTriggerValidate)
+	w.AddTrigger(RejectTriggerPull)
+	w.AddTrigger(RejectTriggerFetch)
+	w.AddTrigger(RejectTriggerFindRequest)
+	w.AddTrigger(RejectTriggerRecord)
+	w.AddTrigger(RejectTriggerCleanup)
+	w.AddTrigger(RejectTriggerCommitPush)
+	w.AddTrigger(RejectTriggerDeleteBranch)
+	w.AddTrigger(RejectTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gonzaloalvarez/kepr/internal/buildflags"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+	"github.com/gonzaloalvarez/kepr/tests/mocks"
+)
+
+func TestNewAccessRequest(t *testing.T) {
+	req := newAccessRequest("3f2a", "FP_AAA", "prod", []byte("key"), "deploy bot", "octocat")
+
+	hostname, _ := os.Hostname()
+	if req.UUID != "3f2a" || req.Fingerprint != "FP_AAA" || req.Path != "prod" || req.PublicKey != "key" {
+		t.Errorf("newAccessRequest() = %+v, want the request's UUID, key and path", req)
+	}
+	if req.Hostname != hostname || req.OS != runtime.GOOS || req.Arch != runtime.GOARCH {
+		t.Errorf("machine facts = %q %q/%q, want %q %q/%q", req.Hostname, req.OS, req.Arch, hostname, runtime.GOOS, runtime.GOARCH)
+	}
+	if req.KeprVersion != buildflags.Version {
+		t.Errorf("KeprVersion = %q, want %q", req.KeprVersion, buildflags.Version)
+	}
+	if req.Reason != "deploy bot" || req.GitHubLogin != "octocat" {
+		t.Errorf("Reason = %q, GitHubLogin = %q", req.Reason, req.GitHubLogin)
+	}
+	if req.Timestamp == "" {
+		t.Error("expected a timestamp")
+	}
+}
+
+func TestAccessRequest_OmitsEmptyFacts(t *testing.T) {
+	data, err := json.Marshal(AccessRequest{Fingerprint: "FP_AAA", Path: "prod", PublicKey: "key"})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, field := range []string{"hostname", "os", "arch", "kepr_version", "reason", "github_login"} {
+		if strings.Contains(string(data), `"`+field+`"`) {
+			t.Errorf("empty %s should be omitted: %s", field, data)
+		}
+	}
+}
+
+func TestStepImportRootKey_SkipsGroups(t *testing.T) {
+	secretsPath := t.TempDir()
+	if err := store.WriteGpgID(secretsPath, []string{"@ops", "FP_AAA"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(secretsPath, "keys"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.KeyPath(secretsPath, "FP_AAA"), []byte("admin-key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := mocks.NewMockShell()
+	sh.AddResponse("/usr/bin/gpg", []string{"--import"}, "", "", nil)
+	g, err := gpg.New(t.TempDir(), sh, mocks.NewMockUI())
+	if err != nil {
+		t.Fatalf("gpg.New() failed: %v", err)
+	}
+
+	c := &Context{UI: mocks.NewMockUI(), GPG: g, SecretsPath: secretsPath}
+	if err := c.stepImportRootKey().Execute(context.Background()); err != nil {
+		t.Fatalf("stepImportRootKey() with a group in the root failed: %v", err)
+	}
+	if stdin := sh.GetStdinForCall("/usr/bin/gpg", "--import"); stdin != "admin-key" {
+		t.Errorf("imported %q, want only the direct root recipient's key", stdin)
+	}
+}
@@ -29,6 +29,8 @@ const (
 	StateRequestBuilt   workflow.State = "request_built"
 	StateBranchCreated  workflow.State = "branch_created"
 	StatePushed         workflow.State = "pushed"
+	StatePullRequestOpened workflow.State = "pull_request_opened"
+	StateWaited         workflow.State = "waited"
 	StateComplete       workflow.State = "complete"
 )
 
@@ -40,5 +42,7 @@ const (
 	TriggerBuildRequest  workflow.Trigger = "build_request"
 	TriggerCreateBranch  workflow.Trigger = "create_branch"
 	TriggerCommitAndPush workflow.Trigger = "commit_and_push"
+	TriggerOpenPullRequest workflow.Trigger = "open_pull_request"
+	TriggerWait          workflow.Trigger = "wait"
 	TriggerComplete      workflow.Trigger = "complete"
 )
@@ -0,0 +1,213 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+const (
+	StatusStateStart     workflow.State = "status_start"
+	StatusStateValidated workflow.State = "status_validated"
+	StatusStatePulled    workflow.State = "status_pulled"
+	StatusStateDisplayed workflow.State = "status_displayed"
+	StatusStateComplete  workflow.State = "status_complete"
+
+	StatusTriggerValidate workflow.Trigger = "status_validate"
+	StatusTriggerPull     workflow.Trigger = "status_pull"
+	StatusTriggerDisplay  workflow.Trigger = "status_display"
+	StatusTriggerComplete workflow.Trigger = "status_complete"
+)
+
+type StatusContext struct {
+	Context
+}
+
+type requestState int
+
+const (
+	requestPending requestState = iota
+	requestApproved
+	requestRejected
+	requestClosed
+)
+
+// requestOutcome is what happened to a request this machine made.
+type requestOutcome struct {
+	state requestState
+	// grantPath is the path access was granted to, once approved.
+	grantPath string
+	rejection *store.Rejection
+}
+
+// checkRequest works out what happened to a request this machine made, from
+// the store on main and the request branch on the remote. The granted path
+// comes from the approval recorded on main, since an admin may grant less
+// than, or something other than, the requested path.
+func (c *Context) checkRequest(uuid, path string, rejections []store.Rejection) (requestOutcome, error) {
+	s, err := store.New(c.SecretsPath, c.GPG, c.Fingerprint)
+	if err != nil {
+		return requestOutcome{}, fmt.Errorf("failed to create store: %w", err)
+	}
+
+	approval, err := c.recordedApproval(s, uuid)
+	if err != nil {
+		return requestOutcome{}, err
+	}
+	if approval != nil && s.HasAccessTo(approval.Granted()) {
+		return requestOutcome{state: requestApproved, grantPath: approval.Granted()}, nil
+	}
+	if s.HasAccessTo(path) {
+		return requestOutcome{state: requestApproved, grantPath: path}, nil
+	}
+
+	for i := range rejections {
+		if rejections[i].RequestUUID == uuid {
+			return requestOutcome{state: requestRejected, rejection: &rejections[i]}, nil
+		}
+	}
+
+	gitClient := git.NewWithAuth(c.Token)
+	exists, err := gitClient.RemoteBranchExists(c.SecretsPath, "origin", "access-request/"+uuid)
+	if err != nil {
+		return requestOutcome{}, fmt.Errorf("failed to check request branch: %w", err)
+	}
+	if exists {
+		return requestOutcome{state: requestPending}, nil
+	}
+	return requestOutcome{state: requestClosed}, nil
+}
+
+// recordedApproval returns the approval of the request that an admin
+// recorded in approvals/ on main, or nil when there is none. Admins are the
+// root recipients, including the members of root groups this machine can
+// read, as when the approval was recorded.
+func (c *Context) recordedApproval(s *store.Store, uuid string) (*store.Approval, error) {
+	files, err := store.ReadApprovals(c.SecretsPath)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	admins, err := s.RootAdmins()
+	if err != nil {
+		return nil, err
+	}
+	return store.RecordedApproval(c.GPG, files, uuid, admins), nil
+}
+
+func (c *StatusContext) stepStatusDisplay() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "display",
+		Execute: func(ctx context.Context) error {
+			rejections, err := store.ListRejections(c.SecretsPath, c.GPG, c.Fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to list rejections: %w", err)
+			}
+
+			records := config.GetAccessRequests(c.RepoPath)
+			if len(records) == 0 && len(rejections) == 0 {
+				c.UI.Infoln("No access requests from this machine")
+				return nil
+			}
+
+			shown := make(map[string]bool)
+			for _, record := range records {
+				shown[record.UUID] = true
+
+				outcome, err := c.checkRequest(record.UUID, record.Path, rejections)
+				if err != nil {
+					return err
+				}
+
+				switch outcome.state {
+				case requestApproved:
+					if outcome.grantPath != record.Path {
+						c.UI.Successfln("Request %s for %s: approved for %s", record.UUID, record.Path, outcome.grantPath)
+					} else {
+						c.UI.Successfln("Request %s for %s: approved", record.UUID, record.Path)
+					}
+				case requestRejected:
+					c.printRejection(*outcome.rejection)
+				case requestPending:
+					c.UI.Infofln("Request %s for %s: pending approval", record.UUID, record.Path)
+				case requestClosed:
+					c.UI.Warning(fmt.Sprintf("Request %s for %s: closed without access being granted", record.UUID, record.Path))
+				}
+			}
+
+			for _, r := range rejections {
+				if !shown[r.RequestUUID] {
+					c.printRejection(r)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func (c *StatusContext) printRejection(r store.Rejection) {
+	c.UI.Warning(fmt.Sprintf("Request %s for %s: rejected by %s on %s", r.RequestUUID, r.Path, r.RejectedBy, r.Timestamp))
+	if r.Reason != "" {
+		c.UI.Infofln("  Reason: %s", r.Reason)
+	}
+}
+
+func NewStatusWorkflow(repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+	c := &StatusContext{
+		Context: Context{
+			Shell:    sh,
+			UI:       ui,
+			GitHub:   gh,
+			RepoPath: repoPath,
+		},
+	}
+
+	w := workflow.New(StatusStateStart)
+
+	w.Configure(StatusStateStart).
+		Permit(StatusTriggerValidate, StatusStateValidated)
+
+	w.Configure(StatusStateValidated).
+		OnEntryFrom(StatusTriggerValidate, entryWithRetry(c.stepValidate())).
+		Permit(StatusTriggerPull, StatusStatePulled)
+
+	w.Configure(StatusStatePulled).
+		OnEntryFrom(StatusTriggerPull, entryWithRetry(c.stepPull())).
+		Permit(StatusTriggerDisplay, StatusStateDisplayed)
+
+	w.Configure(StatusStateDisplayed).
+		OnEntryFrom(StatusTriggerDisplay, entryWithRetry(c.stepStatusDisplay())).
+		Permit(StatusTriggerComplete, StatusStateComplete)
+
+	w.Configure(StatusStateComplete)
+
+	w.AddTrigger(StatusTriggerValidate)
+	w.AddTrigger(StatusTriggerPull)
+	w.AddTrigger(StatusTriggerDisplay)
+	w.AddTrigger(StatusTriggerComplete)
+
+	return w
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package request
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+	"github.com/gonzaloalvarez/kepr/tests/mocks"
+)
+
+func TestCheckRequest_Rejected(t *testing.T) {
+	secretsPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(secretsPath, ".gpg.id"), []byte("ADMIN\n"), 0600); err != nil {
+		t.Fatalf("failed to write .gpg.id: %v", err)
+	}
+
+	c := &Context{SecretsPath: secretsPath, GPG: &gpg.GPG{}, Fingerprint: "MACHINE"}
+	rejections := []store.Rejection{
+		{RequestUUID: "other", Fingerprint: "MACHINE", Path: "staging", Signer: "ADMIN"},
+		{RequestUUID: "3f2a", Fingerprint: "MACHINE", Path: "prod", Reason: "use the CI key", Signer: "ADMIN"},
+	}
+
+	outcome, err := c.checkRequest("3f2a", "prod", rejections)
+	if err != nil {
+		t.Fatalf("checkRequest() error = %v", err)
+	}
+	if outcome.state != requestRejected {
+		t.Fatalf("checkRequest() state = %v, want rejected", outcome.state)
+	}
+	if outcome.rejection.RequestUUID != "3f2a" || outcome.rejection.Reason != "use the CI key" {
+		t.Errorf("checkRequest() rejection = %+v, want the one for 3f2a", outcome.rejection)
+	}
+}
+
+func TestPrintRejection(t *testing.T) {
+	ui := mocks.NewMockUI()
+	c := &StatusContext{Context: Context{UI: ui}}
+
+	c.printRejection(store.Rejection{
+		RequestUUID: "3f2a",
+		Path:        "prod",
+		Reason:      "use the CI key",
+		RejectedBy:  "Alice <alice@example.com>",
+		Timestamp:   "2025-01-02T03:04:05Z",
+	})
+	c.printRejection(store.Rejection{RequestUUID: "9b1c", Path: "staging", RejectedBy: "Bob <bob@example.com>"})
+
+	output := ui.Output.String()
+	for _, want := range []string{
+		"Request 3f2a for prod: rejected by Alice <alice@example.com> on 2025-01-02T03:04:05Z",
+		"Reason: use the CI key",
+		"Request 9b1c for staging: rejected by Bob <bob@example.com>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, "Reason:") != 1 {
+		t.Errorf("expected a reason line only for the rejection with a reason:\n%s", output)
+	}
+}
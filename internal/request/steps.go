@@ -20,11 +20,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
+	"github.com/gonzaloalvarez/kepr/internal/buildflags"
 	"github.com/gonzaloalvarez/kepr/internal/common"
 	"github.com/gonzaloalvarez/kepr/internal/workflow"
 	"github.com/gonzaloalvarez/kepr/pkg/config"
@@ -32,15 +35,23 @@ import (
 	"github.com/gonzaloalvarez/kepr/pkg/git"
 	"github.com/gonzaloalvarez/kepr/pkg/github"
 	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/sas"
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 	"github.com/gonzaloalvarez/kepr/pkg/store"
 )
 
 type AccessRequest struct {
+	UUID        string `json:"uuid"`
 	Fingerprint string `json:"fingerprint"`
 	Path        string `json:"path"`
 	PublicKey   string `json:"public_key"`
 	Timestamp   string `json:"timestamp"`
+	Hostname    string `json:"hostname,omitempty"`
+	OS          string `json:"os,omitempty"`
+	Arch        string `json:"arch,omitempty"`
+	KeprVersion string `json:"kepr_version,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	GitHubLogin string `json:"github_login,omitempty"`
 }
 
 type Context struct {
@@ -57,17 +68,41 @@ type Context struct {
 	SecretsPath string
 	GPG         *gpg.GPG
 	RequestUUID string
+	RequestOptions
+}
+
+type RequestOptions struct {
+	Reason string
+	// PullRequest opens a GitHub pull request for the request branch.
+	PullRequest bool
+	// Export writes the encrypted request to this file instead of pushing a
+	// request branch, for machines without GitHub access.
+	Export string
+	Wait   bool
+	// Timeout bounds Wait; zero waits until interrupted.
+	Timeout time.Duration
 }
 
 func (c *Context) stepValidate() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "validate",
 		Execute: func(ctx context.Context) error {
-			c.Token = config.GetToken()
-			if err := common.ValidateToken(c.Token); err != nil {
-				return err
+			if c.PullRequest && !common.IsGitHubRepo(c.RepoPath) {
+				return fmt.Errorf("pull requests need a GitHub store; %s is on %s", c.RepoPath, common.RepoKind(c.RepoPath))
+			}
+
+			c.Token = config.GetRepoToken(c.RepoPath)
+			if c.Export == "" {
+				if err := common.RequireOnline(); err != nil {
+					return err
+				}
+				if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
+					return err
+				}
+			}
+			if c.Token != "" {
+				c.GitHub.SetToken(c.Token)
 			}
-			c.GitHub.SetToken(c.Token)
 
 			configDir, err := common.ValidateConfigDir()
 			if err != nil {
@@ -109,9 +144,11 @@ func (c *Context) stepPull() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "pull",
 		Execute: func(ctx context.Context) error {
-			gitClient := git.NewWithAuth(c.Token)
-			if err := gitClient.Pull(c.SecretsPath, "origin", "main", true); err != nil {
-				return fmt.Errorf("failed to pull latest changes: %w", err)
+			if c.Export != "" {
+				return nil
+			}
+			if err := c.pullMain(); err != nil {
+				return err
 			}
 			c.UI.Successfln("Pulled latest changes from remote")
 			return nil
@@ -119,6 +156,20 @@ func (c *Context) stepPull() workflow.StepConfig {
 	}
 }
 
+func (c *Context) pullMain() error {
+	gitClient := common.NewVerifyingGit(c.Token, c.GPG)
+	// A requester is left on its access-request branch after pushing.
+	if branch, err := gitClient.CurrentBranch(c.SecretsPath); err == nil && branch != "main" {
+		if err := gitClient.CheckoutMain(c.SecretsPath); err != nil {
+			return fmt.Errorf("failed to check out main: %w", err)
+		}
+	}
+	if err := gitClient.Pull(c.SecretsPath, "origin", "main", true); err != nil {
+		return fmt.Errorf("failed to pull latest changes: %w", common.SyncHint(err))
+	}
+	return nil
+}
+
 func (c *Context) stepFetchRequests() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "fetch_requests",
@@ -140,19 +191,21 @@ func (c *Context) stepFetchRequests() workflow.StepConfig {
 				return fmt.Errorf("failed to create requests directory: %w", err)
 			}
 
+			// Only the request named after its branch is taken, and its
+			// signed UUID is checked against that name when it is read.
 			for _, branch := range branches {
 				files, err := gitClient.ReadFilesFromBranch(c.SecretsPath, "origin", branch, "requests")
 				if err != nil {
 					continue
 				}
-				for name, data := range files {
-					if !strings.HasSuffix(name, ".json.gpg") {
-						continue
-					}
-					localPath := filepath.Join(requestsDir, name)
-					if err := os.WriteFile(localPath, data, 0600); err != nil {
-						return fmt.Errorf("failed to write request file %s: %w", name, err)
-					}
+				name := strings.TrimPrefix(branch, "access-request/") + ".json.gpg"
+				data, ok := files[name]
+				if !ok {
+					slog.Debug("request branch has no request named after it", "branch", branch)
+					continue
+				}
+				if err := os.WriteFile(filepath.Join(requestsDir, name), data, 0600); err != nil {
+					return fmt.Errorf("failed to write request file %s: %w", name, err)
 				}
 			}
 
@@ -172,18 +225,73 @@ func (c *Context) stepCheckAccess() workflow.StepConfig {
 			if found {
 				return fmt.Errorf("you already have access (fingerprint %s found in store)", c.Fingerprint)
 			}
+
+			if c.Export != "" {
+				return nil
+			}
+
+			if common.IsLocalOnly(c.RepoPath) {
+				return nil
+			}
+
+			uuid, err := c.pendingRequest()
+			if err != nil {
+				return err
+			}
+			if uuid != "" {
+				return fmt.Errorf("request %s is already pending; check it with kepr request --status", uuid)
+			}
 			return nil
 		},
 	}
 }
 
+// pendingRequest returns the UUID of an access-request branch on the remote
+// whose tip this key signed, so a request made from another clone of the
+// same key is found too.
+func (c *Context) pendingRequest() (string, error) {
+	gitClient := git.NewWithAuth(c.Token)
+	branches, err := gitClient.FetchBranches(c.SecretsPath, "origin", "access-request/*")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch access-request branches: %w", err)
+	}
+
+	for _, branch := range branches {
+		head, err := gitClient.RemoteBranchHead(c.SecretsPath, "origin", branch)
+		if err != nil {
+			return "", err
+		}
+		signature, payload, err := gitClient.CommitSignature(c.SecretsPath, head)
+		if err != nil {
+			return "", err
+		}
+		if signature == "" {
+			continue
+		}
+		signer, err := c.GPG.VerifyDetached(payload, []byte(signature))
+		if err != nil || signer != c.Fingerprint {
+			continue
+		}
+
+		// Fetching never prunes, so the branch may be gone upstream.
+		pending, err := gitClient.RemoteBranchExists(c.SecretsPath, "origin", branch)
+		if err != nil {
+			return "", fmt.Errorf("failed to check request branch: %w", err)
+		}
+		if pending {
+			return strings.TrimPrefix(branch, "access-request/"), nil
+		}
+	}
+	return "", nil
+}
+
 func (c *Context) stepImportRootKey() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "import_root_key",
 		Execute: func(ctx context.Context) error {
-			rootFingerprints, err := store.ReadGpgID(c.SecretsPath)
+			rootFingerprints, err := store.RootFingerprints(c.SecretsPath)
 			if err != nil {
-				return fmt.Errorf("failed to read root .gpg.id: %w", err)
+				return err
 			}
 
 			for _, fp := range rootFingerprints {
@@ -214,6 +322,13 @@ func (c *Context) stepCreateBranch() workflow.StepConfig {
 			}
 			c.RequestUUID = uuid
 
+			if c.Export != "" {
+				return nil
+			}
+			if common.IsLocalOnly(c.RepoPath) {
+				return fmt.Errorf("%s has no remote to push the request to; use --export", c.RepoPath)
+			}
+
 			branchName := "access-request/" + c.RequestUUID
 			gitClient := git.New()
 			if err := gitClient.CreateBranch(c.SecretsPath, branchName); err != nil {
@@ -225,33 +340,68 @@ func (c *Context) stepCreateBranch() workflow.StepConfig {
 	}
 }
 
+// newAccessRequest describes a request from this machine, with the facts an
+// approver needs to recognise it.
+func newAccessRequest(uuid, fingerprint, path string, pubKey []byte, reason, login string) AccessRequest {
+	hostname, err := os.Hostname()
+	if err != nil {
+		slog.Debug("failed to read hostname", "error", err)
+	}
+
+	return AccessRequest{
+		UUID:        uuid,
+		Fingerprint: fingerprint,
+		Path:        path,
+		PublicKey:   string(pubKey),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Hostname:    hostname,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		KeprVersion: buildflags.Version,
+		Reason:      reason,
+		GitHubLogin: login,
+	}
+}
+
 func (c *Context) stepBuildRequest() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "build_request",
 		Execute: func(ctx context.Context) error {
+			if err := c.GPG.RequireSigningKey(c.Fingerprint); err != nil {
+				return err
+			}
+
 			pubKey, err := c.GPG.ExportPublicKey(c.Fingerprint)
 			if err != nil {
 				return fmt.Errorf("failed to export requester public key: %w", err)
 			}
 
-			req := AccessRequest{
-				Fingerprint: c.Fingerprint,
-				Path:        c.Path,
-				PublicKey:   string(pubKey),
-				Timestamp:   time.Now().UTC().Format(time.RFC3339),
+			var login string
+			if c.Token != "" && !config.IsOffline() {
+				login, err = c.currentLogin()
+				if err != nil {
+					slog.Debug("failed to read login", "error", err)
+				}
 			}
 
+			req := newAccessRequest(c.RequestUUID, c.Fingerprint, c.Path, pubKey, c.Reason, login)
+
 			jsonData, err := json.MarshalIndent(req, "", "  ")
 			if err != nil {
 				return fmt.Errorf("failed to marshal request JSON: %w", err)
 			}
 
-			rootFingerprints, err := store.ReadGpgID(c.SecretsPath)
+			rootFingerprints, err := store.RootFingerprints(c.SecretsPath)
+			if err != nil {
+				return err
+			}
+
+			signed, err := c.GPG.Sign(jsonData, c.Fingerprint)
 			if err != nil {
-				return fmt.Errorf("failed to read root .gpg.id: %w", err)
+				return fmt.Errorf("failed to sign request: %w", err)
 			}
 
-			encrypted, err := c.GPG.Encrypt(jsonData, rootFingerprints...)
+			encrypted, err := c.GPG.Encrypt(signed, rootFingerprints...)
 			if err != nil {
 				return fmt.Errorf("failed to encrypt request: %w", err)
 			}
@@ -267,6 +417,8 @@ func (c *Context) stepBuildRequest() workflow.StepConfig {
 			}
 
 			c.UI.Successfln("Created access request %s", c.RequestUUID)
+			c.UI.Infofln("Verification code: %s", sas.Code(c.Fingerprint, c.RequestUUID))
+			c.UI.Infofln("Read this code to the approver; they will be asked to type it back")
 			return nil
 		},
 	}
@@ -276,7 +428,14 @@ func (c *Context) stepCommitAndPush() workflow.StepConfig {
 	return workflow.StepConfig{
 		Name: "commit_and_push",
 		Execute: func(ctx context.Context) error {
-			gitClient := git.NewWithAuth(c.Token)
+			if c.Export != "" {
+				return c.exportRequest()
+			}
+
+			gitClient, err := common.NewSigningGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath)
+			if err != nil {
+				return err
+			}
 
 			message := fmt.Sprintf("New access request %s", c.RequestUUID)
 			if err := gitClient.Commit(c.SecretsPath, message, c.UserName, c.UserEmail); err != nil {
@@ -289,6 +448,16 @@ func (c *Context) stepCommitAndPush() workflow.StepConfig {
 			}
 
 			c.UI.Successfln("Pushed access request to branch %s", branchName)
+
+			record := config.AccessRequestRecord{
+				UUID:      c.RequestUUID,
+				Repo:      c.RepoPath,
+				Path:      c.Path,
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+			}
+			if err := config.AddAccessRequest(record); err != nil {
+				c.UI.Warning(fmt.Sprintf("Failed to remember request %s locally: %v", c.RequestUUID, err))
+			}
 			return nil
 		},
 		Retry: &workflow.RetryConfig{
@@ -299,3 +468,145 @@ func (c *Context) stepCommitAndPush() workflow.StepConfig {
 		},
 	}
 }
+
+// exportRequest moves the encrypted request out of the store into the export
+// file, which an admin loads with `kepr request --import`.
+func (c *Context) exportRequest() error {
+	requestPath := filepath.Join(c.SecretsPath, "requests", c.RequestUUID+".json.gpg")
+	encrypted, err := os.ReadFile(requestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read encrypted request: %w", err)
+	}
+
+	if err := os.WriteFile(c.Export, store.ExportRequest(c.RequestUUID, encrypted), 0600); err != nil {
+		return fmt.Errorf("failed to write request file: %w", err)
+	}
+	if err := os.Remove(requestPath); err != nil {
+		return fmt.Errorf("failed to remove request file: %w", err)
+	}
+
+	c.UI.Successfln("Wrote access request to %s", c.Export)
+	return nil
+}
+
+func (c *Context) stepOpenPullRequest() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "open_pull_request",
+		Execute: func(ctx context.Context) error {
+			if !c.PullRequest {
+				return nil
+			}
+
+			branchName := "access-request/" + c.RequestUUID
+			title := fmt.Sprintf("Access request %s for %s", c.RequestUUID, c.Path)
+			body := fmt.Sprintf("Requests access to `%s`.\n\nApprove with `kepr request --approve %s`, or reject with `kepr request --reject %s`.", c.Path, c.RequestUUID, c.RequestUUID)
+			if c.Reason != "" {
+				body += "\n\nReason: " + c.Reason
+			}
+
+			pr, err := c.GitHub.CreatePullRequest(c.RepoPath, branchName, "main", title, body)
+			if err != nil {
+				return err
+			}
+
+			c.UI.Successfln("Opened pull request #%d: %s", pr.Number, pr.URL)
+			return nil
+		},
+	}
+}
+
+func (c *Context) currentLogin() (string, error) {
+	p, err := common.RepoProvider(c.RepoPath, c.GitHub)
+	if err != nil {
+		return "", err
+	}
+	return p.GetCurrentUserLogin()
+}
+
+// findPullRequest returns the open pull request for a request branch, if the
+// requester opened one. Only GitHub stores have pull requests.
+func (c *Context) findPullRequest(uuid string) *github.PullRequest {
+	if !common.IsGitHubRepo(c.RepoPath) {
+		return nil
+	}
+	prs, err := c.GitHub.ListPullRequests(c.RepoPath, "access-request/"+uuid)
+	if err != nil {
+		slog.Debug("failed to list pull requests", "uuid", uuid, "error", err)
+		return nil
+	}
+	if len(prs) == 0 {
+		return nil
+	}
+	return &prs[0]
+}
+
+func (c *Context) closePullRequest(uuid string) {
+	pr := c.findPullRequest(uuid)
+	if pr == nil {
+		return
+	}
+	if err := c.GitHub.ClosePullRequest(c.RepoPath, pr.Number); err != nil {
+		c.UI.Warning(fmt.Sprintf("Failed to close pull request #%d: %v", pr.Number, err))
+		return
+	}
+	c.UI.Successfln("Closed pull request #%d", pr.Number)
+}
+
+var waitPollInterval = 15 * time.Second
+
+func (c *Context) stepWaitForApproval() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "wait_for_approval",
+		Execute: func(ctx context.Context) error {
+			if !c.Wait {
+				return nil
+			}
+
+			c.UI.Infofln("Waiting for request %s to be approved", c.RequestUUID)
+
+			var deadline time.Time
+			if c.Timeout > 0 {
+				deadline = time.Now().Add(c.Timeout)
+			}
+
+			for {
+				if err := c.pullMain(); err != nil {
+					return err
+				}
+
+				rejections, err := store.ListRejections(c.SecretsPath, c.GPG, c.Fingerprint)
+				if err != nil {
+					return fmt.Errorf("failed to list rejections: %w", err)
+				}
+
+				outcome, err := c.checkRequest(c.RequestUUID, c.Path, rejections)
+				if err != nil {
+					return err
+				}
+
+				switch outcome.state {
+				case requestApproved:
+					c.UI.Successfln("Access to %s granted", outcome.grantPath)
+					return nil
+				case requestRejected:
+					if outcome.rejection.Reason != "" {
+						return fmt.Errorf("request %s was rejected: %s", c.RequestUUID, outcome.rejection.Reason)
+					}
+					return fmt.Errorf("request %s was rejected", c.RequestUUID)
+				case requestClosed:
+					return fmt.Errorf("request %s was closed without access being granted", c.RequestUUID)
+				}
+
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					return fmt.Errorf("timed out after %s waiting for request %s to be approved", c.Timeout, c.RequestUUID)
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(waitPollInterval):
+				}
+			}
+		},
+	}
+}
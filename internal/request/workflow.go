@@ -25,13 +25,14 @@ import (
 	"github.com/gonzaloalvarez/kepr/pkg/shell"
 )
 
-func NewWorkflow(path, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
+func NewWorkflow(path string, opts RequestOptions, repoPath string, gh github.Client, sh shell.Executor, ui cout.IO) *workflow.Workflow {
 	c := &Context{
-		Shell:    sh,
-		UI:       ui,
-		GitHub:   gh,
-		RepoPath: repoPath,
-		Path:     path,
+		Shell:          sh,
+		UI:             ui,
+		GitHub:         gh,
+		RepoPath:       repoPath,
+		Path:           path,
+		RequestOptions: opts,
 	}
 
 	w := workflow.New(StateStart)
@@ -65,6 +66,14 @@ func NewWorkflow(path, repoPath string, gh github.Client, sh shell.Executor, ui
 
 	w.Configure(StatePushed).
 		OnEntryFrom(TriggerCommitAndPush, entryWithRetry(c.stepCommitAndPush())).
+		Permit(TriggerOpenPullRequest, StatePullRequestOpened)
+
+	w.Configure(StatePullRequestOpened).
+		OnEntryFrom(TriggerOpenPullRequest, entryWithRetry(c.stepOpenPullRequest())).
+		Permit(TriggerWait, StateWaited)
+
+	w.Configure(StateWaited).
+		OnEntryFrom(TriggerWait, entryWithRetry(c.stepWaitForApproval())).
 		Permit(TriggerComplete, StateComplete)
 
 	w.Configure(StateComplete)
@@ -76,6 +85,8 @@ func NewWorkflow(path, repoPath string, gh github.Client, sh shell.Executor, ui
 	w.AddTrigger(TriggerCreateBranch)
 	w.AddTrigger(TriggerBuildRequest)
 	w.AddTrigger(TriggerCommitAndPush)
+	w.AddTrigger(TriggerOpenPullRequest)
+	w.AddTrigger(TriggerWait)
 	w.AddTrigger(TriggerComplete)
 
 	return w
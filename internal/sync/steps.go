@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gonzaloalvarez/kepr/internal/common"
+	"github.com/gonzaloalvarez/kepr/internal/workflow"
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+)
+
+type Context struct {
+	Shell       shell.Executor
+	UI          cout.IO
+	GitHub      github.Client
+	RepoPath    string
+	Token       string
+	UserEmail   string
+	Fingerprint string
+	SecretsPath string
+	GPG         *gpg.GPG
+	Git         *git.Git
+}
+
+func (c *Context) stepValidate() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "validate",
+		Execute: func(ctx context.Context) error {
+			if config.IsReadOnlyRepo(c.RepoPath) {
+				return fmt.Errorf("%s is a read-only store; refresh it with `kepr bundle apply`", c.RepoPath)
+			}
+			if err := common.RequireOnline(); err != nil {
+				return err
+			}
+
+			c.Token = config.GetRepoToken(c.RepoPath)
+			if err := common.ValidateToken(c.RepoPath, c.Token); err != nil {
+				return err
+			}
+			c.GitHub.SetToken(c.Token)
+
+			configDir, err := common.ValidateConfigDir()
+			if err != nil {
+				return err
+			}
+			_, userEmail, err := common.ValidateUserIdentity()
+			if err != nil {
+				return err
+			}
+			c.UserEmail = userEmail
+
+			g, err := common.ValidateGPGSetup(configDir, c.Shell, c.UI)
+			if err != nil {
+				return err
+			}
+			c.GPG = g
+
+			fingerprint, err := common.ValidateFingerprint()
+			if err != nil {
+				return err
+			}
+			c.Fingerprint = fingerprint
+
+			secretsPath, err := common.GetSecretsPath(c.RepoPath)
+			if err != nil {
+				return err
+			}
+			c.SecretsPath = secretsPath
+
+			hasRemote, err := git.New().HasRemote(c.SecretsPath, "origin")
+			if err != nil {
+				return err
+			}
+			if !hasRemote {
+				return fmt.Errorf("%s is a local-only store; there is no remote to sync with", c.RepoPath)
+			}
+			return nil
+		},
+	}
+}
+
+// stepPull brings in remote changes, rebasing any local commits on top of
+// them.
+func (c *Context) stepPull() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "pull",
+		Execute: func(ctx context.Context) error {
+			gitClient, err := common.NewSyncingGit(c.Token, c.GPG, c.Fingerprint, c.UserEmail, c.SecretsPath, c.UI)
+			if err != nil {
+				return err
+			}
+			c.Git = gitClient
+
+			if err := gitClient.Pull(c.SecretsPath, "origin", "main", true); err != nil {
+				return fmt.Errorf("failed to pull latest changes: %w", err)
+			}
+			c.UI.Successfln("Pulled latest changes from remote")
+			return nil
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Pull failed: %v. Retry?", err))
+			},
+		},
+	}
+}
+
+func (c *Context) stepPush() workflow.StepConfig {
+	return workflow.StepConfig{
+		Name: "push",
+		Execute: func(ctx context.Context) error {
+			count, err := c.Git.UnpushedCommits(c.SecretsPath, "origin", "main")
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				c.UI.Infofln("Store is in sync with the remote")
+				return config.SetPendingPush(c.RepoPath, false)
+			}
+
+			if err := c.Git.Push(c.SecretsPath, "origin", "main"); err != nil {
+				return fmt.Errorf("failed to push to remote: %w", err)
+			}
+			c.UI.Successfln("Pushed %d local commit(s) to remote", count)
+			return config.SetPendingPush(c.RepoPath, false)
+		},
+		Retry: &workflow.RetryConfig{
+			MaxAttempts: 3,
+			PromptRetry: func(err error, attempt int) (bool, error) {
+				return c.UI.Confirm(fmt.Sprintf("Push failed: %v. Retry?", err))
+			},
+		},
+	}
+}
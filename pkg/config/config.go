@@ -28,6 +28,19 @@ import (
 type GitHubRepo struct {
 	Name    string `json:"name"`
 	Default bool   `json:"default,omitempty"`
+	// ReadOnly marks a store copy fed from bundles, which is never pulled
+	// from or pushed to GitHub.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// PendingPush records local commits made while offline that
+	// `kepr sync` has yet to deliver.
+	PendingPush bool `json:"pending_push,omitempty"`
+	// Provider is the git host of the store; empty means GitHub.
+	Provider string `json:"provider,omitempty"`
+	// URL is the API base URL for GitLab and Gitea, or the remote URL of a
+	// generic git remote.
+	URL string `json:"url,omitempty"`
+	// Token authenticates to Provider when it is not GitHub.
+	Token string `json:"token,omitempty"`
 }
 
 type GitHub struct {
@@ -36,19 +49,33 @@ type GitHub struct {
 	Repos []GitHubRepo `json:"repos,omitempty"`
 }
 
+// AccessRequestRecord remembers a request this machine made so that
+// `kepr request --status` can follow it up.
+type AccessRequestRecord struct {
+	UUID      string `json:"uuid"`
+	Repo      string `json:"repo"`
+	Path      string `json:"path"`
+	Timestamp string `json:"timestamp"`
+}
+
 type Config struct {
-	GitHub          GitHub `json:"github"`
-	Headless        bool   `json:"headless,omitempty"`
-	UserName        string `json:"user_name,omitempty"`
-	UserEmail       string `json:"user_email,omitempty"`
-	UserFingerprint string `json:"user_fingerprint,omitempty"`
-	YubikeyAdminPin string `json:"yubikey_admin_pin,omitempty"`
-	YubikeyUserPin  string `json:"yubikey_user_pin,omitempty"`
-	YubikeySerial   string `json:"yubikey_serial,omitempty"`
+	GitHub          GitHub                `json:"github"`
+	Headless        bool                  `json:"headless,omitempty"`
+	UserName        string                `json:"user_name,omitempty"`
+	UserEmail       string                `json:"user_email,omitempty"`
+	UserFingerprint string                `json:"user_fingerprint,omitempty"`
+	YubikeyAdminPin string                `json:"yubikey_admin_pin,omitempty"`
+	YubikeyUserPin  string                `json:"yubikey_user_pin,omitempty"`
+	YubikeySerial   string                `json:"yubikey_serial,omitempty"`
+	Requests        []AccessRequestRecord `json:"requests,omitempty"`
 }
 
 var cfg *Config
 
+// offline is set for the current run only, by --offline or when the remote
+// turns out to be unreachable.
+var offline bool
+
 func Dir() (string, error) {
 	if keprHome := os.Getenv("KEPR_HOME"); keprHome != "" {
 		absPath, err := filepath.Abs(keprHome)
@@ -360,6 +387,144 @@ func SaveGitHubRepo(repoPath string) error {
 	return AddRepo(name)
 }
 
+// SaveReadOnlyRepo adds repoPath to the configured repos and marks it as fed
+// from bundles.
+func SaveReadOnlyRepo(repoPath string) error {
+	if err := SaveGitHubRepo(repoPath); err != nil {
+		return err
+	}
+
+	_, name := splitRepoPath(repoPath)
+	for i := range cfg.GitHub.Repos {
+		if cfg.GitHub.Repos[i].Name == name {
+			cfg.GitHub.Repos[i].ReadOnly = true
+		}
+	}
+	return saveConfig()
+}
+
+func IsReadOnlyRepo(repoPath string) bool {
+	r := findRepo(repoPath)
+	return r != nil && r.ReadOnly
+}
+
+// SetPendingPush records whether repoPath has local commits waiting for
+// `kepr sync`.
+func SetPendingPush(repoPath string, pending bool) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	r := findRepo(repoPath)
+	if r == nil || r.PendingPush == pending {
+		return nil
+	}
+	r.PendingPush = pending
+	return saveConfig()
+}
+
+func HasPendingPush(repoPath string) bool {
+	r := findRepo(repoPath)
+	return r != nil && r.PendingPush
+}
+
+// SaveRepoProvider records where a store that is not on GitHub lives, and
+// the token for it.
+func SaveRepoProvider(repoPath, provider, url, token string) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	r := findRepo(repoPath)
+	if r == nil {
+		return fmt.Errorf("unknown repo: %s", repoPath)
+	}
+	r.Provider = provider
+	r.URL = url
+	r.Token = token
+	return saveConfig()
+}
+
+// GetRepoProvider returns the provider and URL of repoPath. An empty
+// provider means GitHub.
+func GetRepoProvider(repoPath string) (provider, url string) {
+	r := findRepo(repoPath)
+	if r == nil {
+		return "", ""
+	}
+	return r.Provider, r.URL
+}
+
+// GetRepoToken returns the token for repoPath: its own for stores outside
+// GitHub, so the GitHub token is never sent to another host, and the GitHub
+// token otherwise.
+func GetRepoToken(repoPath string) string {
+	if r := findRepo(repoPath); r != nil && r.Provider != "" && r.Provider != "github" {
+		return r.Token
+	}
+	return GetToken()
+}
+
+func findRepo(repoPath string) *GitHubRepo {
+	if cfg == nil {
+		return nil
+	}
+
+	owner, name := splitRepoPath(repoPath)
+	if owner != cfg.GitHub.Owner {
+		return nil
+	}
+	for i := range cfg.GitHub.Repos {
+		if cfg.GitHub.Repos[i].Name == name {
+			return &cfg.GitHub.Repos[i]
+		}
+	}
+	return nil
+}
+
+func SetOffline(value bool) {
+	offline = value
+}
+
+func IsOffline() bool {
+	return offline
+}
+
+func AddAccessRequest(record AccessRequestRecord) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	cfg.Requests = append(cfg.Requests, record)
+	return saveConfig()
+}
+
+func GetAccessRequests(repo string) []AccessRequestRecord {
+	if cfg == nil {
+		return nil
+	}
+	var records []AccessRequestRecord
+	for _, r := range cfg.Requests {
+		if r.Repo == repo {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+func RemoveAccessRequest(uuid string) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	kept := cfg.Requests[:0]
+	for _, r := range cfg.Requests {
+		if r.UUID != uuid {
+			kept = append(kept, r)
+		}
+	}
+	cfg.Requests = kept
+	return saveConfig()
+}
+
 func splitRepoPath(repoPath string) (owner, name string) {
 	for i := 0; i < len(repoPath); i++ {
 		if repoPath[i] == '/' {
@@ -569,3 +569,143 @@ func TestGettersWithValues(t *testing.T) {
 		t.Errorf("GetYubikeySerial() = %q, want \"12345678\"", GetYubikeySerial())
 	}
 }
+
+func TestAddAccessRequest_NilConfig(t *testing.T) {
+	oldCfg := cfg
+	cfg = nil
+	defer func() { cfg = oldCfg }()
+
+	err := AddAccessRequest(AccessRequestRecord{UUID: "uuid-1"})
+	if err == nil {
+		t.Error("AddAccessRequest() with nil config should return error")
+	}
+}
+
+func TestGetAccessRequests_NilConfig(t *testing.T) {
+	oldCfg := cfg
+	cfg = nil
+	defer func() { cfg = oldCfg }()
+
+	if records := GetAccessRequests("owner/repo"); records != nil {
+		t.Errorf("GetAccessRequests() with nil config = %v, want nil", records)
+	}
+}
+
+func TestAccessRequests_AddFilterRemove(t *testing.T) {
+	t.Setenv("KEPR_HOME", t.TempDir())
+	oldCfg := cfg
+	cfg = &Config{}
+	defer func() { cfg = oldCfg }()
+
+	if err := AddAccessRequest(AccessRequestRecord{UUID: "uuid-1", Repo: "owner/a", Path: "prod"}); err != nil {
+		t.Fatalf("AddAccessRequest() returned error: %v", err)
+	}
+	if err := AddAccessRequest(AccessRequestRecord{UUID: "uuid-2", Repo: "owner/b", Path: "dev"}); err != nil {
+		t.Fatalf("AddAccessRequest() returned error: %v", err)
+	}
+
+	records := GetAccessRequests("owner/a")
+	if len(records) != 1 || records[0].UUID != "uuid-1" {
+		t.Errorf("GetAccessRequests(\"owner/a\") = %v, want only uuid-1", records)
+	}
+
+	if err := RemoveAccessRequest("uuid-1"); err != nil {
+		t.Fatalf("RemoveAccessRequest() returned error: %v", err)
+	}
+	if records := GetAccessRequests("owner/a"); len(records) != 0 {
+		t.Errorf("GetAccessRequests() after remove = %v, want none", records)
+	}
+	if records := GetAccessRequests("owner/b"); len(records) != 1 {
+		t.Errorf("RemoveAccessRequest() removed records for another repo: %v", records)
+	}
+}
+
+func TestReadOnlyRepo(t *testing.T) {
+	t.Setenv("KEPR_HOME", t.TempDir())
+	oldCfg := cfg
+	cfg = &Config{}
+	defer func() { cfg = oldCfg }()
+
+	if IsReadOnlyRepo("owner/store") {
+		t.Error("IsReadOnlyRepo() should be false for an unknown repo")
+	}
+
+	if err := SaveReadOnlyRepo("owner/store"); err != nil {
+		t.Fatalf("SaveReadOnlyRepo() returned error: %v", err)
+	}
+	if !IsReadOnlyRepo("owner/store") {
+		t.Error("IsReadOnlyRepo() should be true after SaveReadOnlyRepo()")
+	}
+	if IsReadOnlyRepo("other/store") {
+		t.Error("IsReadOnlyRepo() should not match a different owner")
+	}
+	if GetDefaultRepo() != "owner/store" {
+		t.Errorf("GetDefaultRepo() = %q, want owner/store", GetDefaultRepo())
+	}
+}
+
+func TestPendingPush(t *testing.T) {
+	t.Setenv("KEPR_HOME", t.TempDir())
+	oldCfg := cfg
+	cfg = &Config{}
+	defer func() { cfg = oldCfg }()
+
+	if err := SaveGitHubRepo("owner/store"); err != nil {
+		t.Fatalf("SaveGitHubRepo() returned error: %v", err)
+	}
+	if HasPendingPush("owner/store") {
+		t.Error("HasPendingPush() should be false for a new repo")
+	}
+
+	if err := SetPendingPush("owner/store", true); err != nil {
+		t.Fatalf("SetPendingPush() returned error: %v", err)
+	}
+	if !HasPendingPush("owner/store") {
+		t.Error("HasPendingPush() should be true after SetPendingPush(true)")
+	}
+	if HasPendingPush("other/store") {
+		t.Error("HasPendingPush() should not match a different owner")
+	}
+
+	if err := SetPendingPush("owner/store", false); err != nil {
+		t.Fatalf("SetPendingPush() returned error: %v", err)
+	}
+	if HasPendingPush("owner/store") {
+		t.Error("HasPendingPush() should be false after SetPendingPush(false)")
+	}
+}
+
+func TestRepoProvider(t *testing.T) {
+	t.Setenv("KEPR_HOME", t.TempDir())
+	oldCfg := cfg
+	cfg = &Config{GitHub: GitHub{Token: "gh-token"}}
+	defer func() { cfg = oldCfg }()
+
+	if err := SaveGitHubRepo("owner/store"); err != nil {
+		t.Fatalf("SaveGitHubRepo() returned error: %v", err)
+	}
+	if provider, _ := GetRepoProvider("owner/store"); provider != "" {
+		t.Errorf("GetRepoProvider() = %q for a GitHub store, want empty", provider)
+	}
+	if GetRepoToken("owner/store") != "gh-token" {
+		t.Errorf("GetRepoToken() = %q, want the GitHub token", GetRepoToken("owner/store"))
+	}
+
+	if err := SaveGitHubRepo("owner/other"); err != nil {
+		t.Fatalf("SaveGitHubRepo() returned error: %v", err)
+	}
+	if err := SaveRepoProvider("owner/other", "generic", "ssh://git@host/srv/other.git", ""); err != nil {
+		t.Fatalf("SaveRepoProvider() returned error: %v", err)
+	}
+	provider, url := GetRepoProvider("owner/other")
+	if provider != "generic" || url != "ssh://git@host/srv/other.git" {
+		t.Errorf("GetRepoProvider() = %q, %q", provider, url)
+	}
+	if GetRepoToken("owner/other") != "" {
+		t.Errorf("GetRepoToken() = %q, want no token for a generic remote", GetRepoToken("owner/other"))
+	}
+
+	if err := SaveRepoProvider("owner/missing", "gitlab", "", "t"); err == nil {
+		t.Error("SaveRepoProvider() should fail for an unknown repo")
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+)
+
+// Bundles use git's v2 bundle format, so `git clone store.bundle` works on
+// them too. They always carry the full history of a single branch.
+
+const bundleSignature = "# v2 git bundle"
+
+// CreateBundle writes branch and everything reachable from it to destPath.
+func (g *Git) CreateBundle(repoPath, branch, destPath string) error {
+	slog.Debug("creating bundle", "path", repoPath, "branch", branch, "dest", destPath)
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	refName := plumbing.NewBranchReferenceName(branch)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref %s: %w", refName, err)
+	}
+
+	hashes, err := revlist.Objects(repo.Storer, []plumbing.Hash{ref.Hash()}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	f, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s\n%s %s\n\n", bundleSignature, ref.Hash(), refName); err != nil {
+		return fmt.Errorf("failed to write bundle header: %w", err)
+	}
+
+	if _, err := packfile.NewEncoder(f, repo.Storer, false).Encode(hashes, 10); err != nil {
+		return fmt.Errorf("failed to write bundle pack: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	slog.Debug("bundle created", "objects", len(hashes), "commit", ref.Hash().String())
+	return nil
+}
+
+// ApplyBundle loads a bundle made by CreateBundle into the repository at
+// repoPath, creating it if needed, and resets branch and the worktree to the
+// bundled commit. When branch already exists, the Verifier must accept the
+// bundled commits first.
+func (g *Git) ApplyBundle(repoPath, bundlePath, branch string) error {
+	slog.Debug("applying bundle", "path", repoPath, "bundle", bundlePath, "branch", branch)
+
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	refs, err := readBundleHeader(reader)
+	if err != nil {
+		return err
+	}
+
+	refName := plumbing.NewBranchReferenceName(branch)
+	hash, ok := refs[refName]
+	if !ok {
+		return fmt.Errorf("bundle does not contain %s", refName)
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		if err := g.Init(repoPath); err != nil {
+			return err
+		}
+		repo, err = git.PlainOpen(repoPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if err := packfile.UpdateObjectStorage(repo.Storer, reader); err != nil {
+		return fmt.Errorf("failed to read bundle pack: %w", err)
+	}
+
+	// The objects are in, but branch only moves once the bundled history
+	// passes the same checks as a pull. A first apply has nothing to check
+	// against, like a clone.
+	if current, err := repo.Reference(refName, true); err == nil && current.Hash() != hash && g.Verifier != nil {
+		if err := g.Verifier(repoPath, current.Hash().String(), hash.String()); err != nil {
+			return fmt.Errorf("refusing to apply bundle: %w", err)
+		}
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, hash)); err != nil {
+		return fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := w.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset to bundle: %w", err)
+	}
+
+	slog.Debug("bundle applied", "commit", hash.String())
+	return nil
+}
+
+func readBundleHeader(r *bufio.Reader) (map[plumbing.ReferenceName]plumbing.Hash, error) {
+	signature, err := r.ReadString('\n')
+	if err != nil || strings.TrimSpace(signature) != bundleSignature {
+		return nil, fmt.Errorf("not a git bundle")
+	}
+
+	refs := make(map[plumbing.ReferenceName]plumbing.Hash)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("truncated bundle header")
+			}
+			return nil, fmt.Errorf("failed to read bundle header: %w", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return refs, nil
+		}
+		if strings.HasPrefix(line, "-") {
+			return nil, fmt.Errorf("incremental bundles are not supported")
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("malformed bundle header line %q", line)
+		}
+		refs[plumbing.ReferenceName(name)] = plumbing.NewHash(hash)
+	}
+}
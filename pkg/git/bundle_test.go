@@ -0,0 +1,132 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateAndApplyBundle(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourcePath := filepath.Join(tempDir, "source")
+	g := New()
+	if err := g.Init(sourcePath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, ".gpg.id"), []byte("FP_AAA\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .gpg.id: %v", err)
+	}
+	if err := g.Commit(sourcePath, "init", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+
+	bundlePath := filepath.Join(tempDir, "store.bundle")
+	if err := g.CreateBundle(sourcePath, "main", bundlePath); err != nil {
+		t.Fatalf("CreateBundle() returned error: %v", err)
+	}
+
+	destPath := filepath.Join(tempDir, "dest")
+	if err := g.ApplyBundle(destPath, bundlePath, "main"); err != nil {
+		t.Fatalf("ApplyBundle() returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(destPath, ".gpg.id"))
+	if err != nil {
+		t.Fatalf("bundle was not checked out: %v", err)
+	}
+	if string(data) != "FP_AAA\n" {
+		t.Errorf(".gpg.id = %q, want %q", data, "FP_AAA\n")
+	}
+
+	if err := os.WriteFile(filepath.Join(sourcePath, ".gpg.id"), []byte("FP_AAA\nFP_BBB\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .gpg.id: %v", err)
+	}
+	if err := g.Commit(sourcePath, "add recipient", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.CreateBundle(sourcePath, "main", bundlePath); err != nil {
+		t.Fatalf("CreateBundle() returned error: %v", err)
+	}
+	if err := g.ApplyBundle(destPath, bundlePath, "main"); err != nil {
+		t.Fatalf("ApplyBundle() on existing repo returned error: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(destPath, ".gpg.id"))
+	if string(data) != "FP_AAA\nFP_BBB\n" {
+		t.Errorf(".gpg.id after update = %q", data)
+	}
+}
+
+func TestApplyBundle_NotABundle(t *testing.T) {
+	tempDir := t.TempDir()
+	bundlePath := filepath.Join(tempDir, "bogus.bundle")
+	if err := os.WriteFile(bundlePath, []byte("hello\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := New()
+	if err := g.ApplyBundle(filepath.Join(tempDir, "dest"), bundlePath, "main"); err == nil {
+		t.Fatal("expected error for a file that is not a bundle")
+	}
+}
+
+func TestApplyBundle_Verifier(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourcePath := filepath.Join(tempDir, "source")
+	g := New()
+	if err := g.Init(sourcePath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, sourcePath, ".gpg.id", "FP_AAA\n", "init")
+
+	bundlePath := filepath.Join(tempDir, "store.bundle")
+	if err := g.CreateBundle(sourcePath, "main", bundlePath); err != nil {
+		t.Fatalf("CreateBundle() returned error: %v", err)
+	}
+
+	var verified [][2]string
+	verifying := New()
+	verifying.SetVerifier(func(repoPath, from, to string) error {
+		verified = append(verified, [2]string{from, to})
+		return errors.New("untrusted commit")
+	})
+
+	destPath := filepath.Join(tempDir, "dest")
+	if err := verifying.ApplyBundle(destPath, bundlePath, "main"); err != nil {
+		t.Fatalf("first ApplyBundle() returned error: %v", err)
+	}
+	if len(verified) != 0 {
+		t.Errorf("verifier called on first apply: %v", verified)
+	}
+
+	trusted := commitFile(t, g, sourcePath, ".gpg.id", "FP_AAA\nFP_EVIL\n", "add recipient")
+	if err := g.CreateBundle(sourcePath, "main", bundlePath); err != nil {
+		t.Fatalf("CreateBundle() returned error: %v", err)
+	}
+	if err := verifying.ApplyBundle(destPath, bundlePath, "main"); err == nil {
+		t.Fatal("ApplyBundle() accepted history the verifier rejected")
+	}
+	if len(verified) != 1 || verified[0][1] != trusted {
+		t.Errorf("verifier calls = %v, want one call ending at %s", verified, trusted)
+	}
+	if got := readFile(t, destPath, ".gpg.id"); got != "FP_AAA\n" {
+		t.Errorf(".gpg.id = %q after a rejected bundle, want the previous recipients", got)
+	}
+}
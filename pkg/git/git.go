@@ -17,22 +17,44 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package git
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
+// Signer produces an armored detached signature over a commit. It matches
+// go-git's Signer interface.
+type Signer interface {
+	Sign(message io.Reader) ([]byte, error)
+}
+
+// PullVerifier vets the commits between the local branch and the fetched
+// one before a pull moves the worktree to them.
+type PullVerifier func(repoPath, from, to string) error
+
 type Git struct {
 	AuthToken string
+	// Signer, when set, signs every commit kepr creates.
+	Signer Signer
+	// Verifier, when set, can refuse a pull.
+	Verifier PullVerifier
+	// Resolver, when set, decides conflicts when a pull rebases local
+	// commits onto the remote.
+	Resolver ConflictResolver
 }
 
 func New() *Git {
@@ -47,6 +69,18 @@ func (g *Git) SetAuth(token string) {
 	g.AuthToken = token
 }
 
+func (g *Git) SetSigner(signer Signer) {
+	g.Signer = signer
+}
+
+func (g *Git) SetVerifier(verifier PullVerifier) {
+	g.Verifier = verifier
+}
+
+func (g *Git) SetConflictResolver(resolver ConflictResolver) {
+	g.Resolver = resolver
+}
+
 func (g *Git) getAuth() *http.BasicAuth {
 	if g.AuthToken == "" {
 		return nil
@@ -64,19 +98,41 @@ func (g *Git) getAuthForRemote(repo *git.Repository, remoteName string) transpor
 	}
 
 	urls := remote.Config().URLs
-	if len(urls) > 0 && strings.HasPrefix(urls[0], "file://") {
-		slog.Debug("file:// URL detected, skipping auth", "url", urls[0])
+	if len(urls) > 0 && IsLocalURL(urls[0]) {
+		slog.Debug("local remote detected, skipping auth", "url", urls[0])
 		return nil
 	}
 
 	return g.getAuth()
 }
 
+// IsLocalURL reports whether url is a repository on this machine, either a
+// file:// URL or a plain path, which never needs a token.
+func IsLocalURL(url string) bool {
+	ep, err := transport.NewEndpoint(url)
+	return err == nil && ep.Protocol == "file"
+}
+
+// HasRemote reports whether repoPath has remoteName configured. Stores
+// created without one are local only, and Pull and Push leave them alone.
+func (g *Git) HasRemote(repoPath, remoteName string) (bool, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return hasRemote(repo, remoteName), nil
+}
+
+func hasRemote(repo *git.Repository, remoteName string) bool {
+	_, err := repo.Remote(remoteName)
+	return !errors.Is(err, git.ErrRemoteNotFound)
+}
+
 func (g *Git) Clone(url, destPath string) error {
 	slog.Debug("cloning repository", "url", url, "dest", destPath)
 
 	auth := g.getAuth()
-	if strings.HasPrefix(url, "file://") {
+	if IsLocalURL(url) {
 		auth = nil
 	}
 
@@ -109,6 +165,23 @@ func (g *Git) Init(repoPath string) error {
 	return nil
 }
 
+// InitBare creates an empty bare repository to serve as the remote of a
+// store kept on this machine or a shared filesystem.
+func (g *Git) InitBare(repoPath string) error {
+	slog.Debug("initializing bare git repository", "path", repoPath)
+
+	repo, err := git.PlainInit(repoPath, true)
+	if err != nil {
+		return fmt.Errorf("failed to initialize bare repository: %w", err)
+	}
+
+	headRef := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))
+	if err := repo.Storer.SetReference(headRef); err != nil {
+		return fmt.Errorf("failed to set HEAD to main: %w", err)
+	}
+	return nil
+}
+
 func (g *Git) Commit(repoPath, message, authorName, authorEmail string) error {
 	slog.Debug("committing changes", "path", repoPath, "message", message)
 
@@ -142,10 +215,15 @@ func (g *Git) Commit(repoPath, message, authorName, authorEmail string) error {
 		When:  time.Now(),
 	}
 
-	_, err = w.Commit(message, &git.CommitOptions{
+	opts := &git.CommitOptions{
 		Author:    sig,
 		Committer: sig,
-	})
+	}
+	if g.Signer != nil {
+		opts.Signer = g.Signer
+	}
+
+	_, err = w.Commit(message, opts)
 	if err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
@@ -184,13 +262,17 @@ func (g *Git) Push(repoPath, remoteName, branch string) error {
 	if err != nil {
 		return fmt.Errorf("failed to open repository: %w", err)
 	}
+	if !hasRemote(repo, remoteName) {
+		slog.Debug("no remote configured, store is local only", "remote", remoteName)
+		return nil
+	}
 
 	err = repo.Push(&git.PushOptions{
 		RemoteName: remoteName,
 		Auth:       g.getAuthForRemote(repo, remoteName),
 	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return fmt.Errorf("failed to push: %w", err)
+		return fmt.Errorf("failed to push: %w", remoteError(err))
 	}
 
 	slog.Debug("successfully pushed to remote")
@@ -281,14 +363,45 @@ func (g *Git) ReadFilesFromBranch(repoPath, remoteName, branch, dirPath string)
 		return nil, fmt.Errorf("failed to get commit: %w", err)
 	}
 
+	files, err := readCommitDir(commit, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("read files from branch", "count", len(files))
+	return files, nil
+}
+
+// RemoteBranchHead returns the commit a remote-tracking branch points to.
+func (g *Git) RemoteBranchHead(repoPath, remoteName, branch string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	refName := plumbing.NewRemoteReferenceName(remoteName, branch)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ref %s: %w", refName, err)
+	}
+	return ref.Hash().String(), nil
+}
+
+// readCommitDir returns the regular files directly inside dirPath in the
+// commit's tree, or nil when the directory does not exist. An empty dirPath
+// reads the root of the tree.
+func readCommitDir(commit *object.Commit, dirPath string) (map[string][]byte, error) {
 	tree, err := commit.Tree()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tree: %w", err)
 	}
 
-	subTree, err := tree.Tree(dirPath)
-	if err != nil {
-		return nil, nil
+	subTree := tree
+	if dirPath != "" {
+		subTree, err = tree.Tree(dirPath)
+		if err != nil {
+			return nil, nil
+		}
 	}
 
 	files := make(map[string][]byte)
@@ -310,8 +423,6 @@ func (g *Git) ReadFilesFromBranch(repoPath, remoteName, branch, dirPath string)
 			files[entry.Name] = data
 		}
 	}
-
-	slog.Debug("read files from branch", "count", len(files))
 	return files, nil
 }
 
@@ -345,6 +456,76 @@ func (g *Git) DeleteRemoteBranch(repoPath, remoteName, branch string) error {
 	return nil
 }
 
+func (g *Git) CurrentBranch(repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	return head.Name().Short(), nil
+}
+
+// RemoteBranchExists asks the remote directly, since fetching never prunes
+// remote-tracking refs for branches deleted upstream.
+func (g *Git) RemoteBranchExists(repoPath, remoteName, branch string) (bool, error) {
+	slog.Debug("checking remote branch", "path", repoPath, "remote", remoteName, "branch", branch)
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	refs, err := remote.List(&git.ListOptions{Auth: g.getAuthForRemote(repo, remoteName)})
+	if err != nil {
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to list remote references: %w", err)
+	}
+
+	want := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RemoteHasCommits reports whether the repository at url has any refs,
+// without cloning it. An empty repository has none.
+func (g *Git) RemoteHasCommits(url string) (bool, error) {
+	slog.Debug("listing remote references", "url", url)
+
+	auth := transport.AuthMethod(g.getAuth())
+	if g.AuthToken == "" || IsLocalURL(url) {
+		auth = nil
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return false, nil
+		}
+		return false, remoteError(fmt.Errorf("failed to list remote references: %w", err))
+	}
+	return len(refs) > 0, nil
+}
+
 func (g *Git) CheckoutMain(repoPath string) error {
 	slog.Debug("checking out main", "path", repoPath)
 
@@ -376,6 +557,10 @@ func (g *Git) Pull(repoPath, remoteName, branch string, silent bool) error {
 	if err != nil {
 		return fmt.Errorf("failed to open repository: %w", err)
 	}
+	if !hasRemote(repo, remoteName) {
+		slog.Debug("no remote configured, store is local only", "remote", remoteName)
+		return nil
+	}
 
 	refSpec := config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remoteName, branch))
 	err = repo.Fetch(&git.FetchOptions{
@@ -384,7 +569,10 @@ func (g *Git) Pull(repoPath, remoteName, branch string, silent bool) error {
 		Auth:       g.getAuthForRemote(repo, remoteName),
 	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return fmt.Errorf("failed to fetch: %w", err)
+		return fmt.Errorf("failed to fetch: %w", remoteError(err))
+	}
+	if err := recordFetch(repo); err != nil {
+		slog.Debug("failed to record fetch time", "error", err)
 	}
 
 	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), true)
@@ -392,24 +580,297 @@ func (g *Git) Pull(repoPath, remoteName, branch string, silent bool) error {
 		return fmt.Errorf("failed to get remote reference: %w", err)
 	}
 
+	// Only a branch that does not exist yet, as in a fresh clone, takes the
+	// remote tip without verification.
+	target := remoteRef.Hash()
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	switch {
+	case err == plumbing.ErrReferenceNotFound:
+	case err != nil:
+		return fmt.Errorf("failed to get local reference: %w", err)
+	case localRef.Hash() != remoteRef.Hash():
+		target, err = g.integrate(repo, repoPath, branch, localRef.Hash(), remoteRef.Hash())
+		if err != nil {
+			return err
+		}
+	}
+
 	w, err := repo.Worktree()
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
 	err = w.Reset(&git.ResetOptions{
-		Commit: remoteRef.Hash(),
+		Commit: target,
 		Mode:   git.HardReset,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to reset to remote: %w", err)
 	}
 
-	localRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), remoteRef.Hash())
-	if err := repo.Storer.SetReference(localRef); err != nil {
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), target)
+	if err := repo.Storer.SetReference(newRef); err != nil {
 		return fmt.Errorf("failed to update local branch: %w", err)
 	}
 
 	slog.Debug("successfully pulled from remote")
 	return nil
 }
+
+// CommitFilesToBranch records files on top of the remote-tracking branch
+// without touching the worktree, and points the local branch at the new commit.
+func (g *Git) CommitFilesToBranch(repoPath, remoteName, branch string, files map[string][]byte, message, authorName, authorEmail string) error {
+	slog.Debug("committing files to branch", "path", repoPath, "remote", remoteName, "branch", branch, "files", len(files))
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	refName := plumbing.NewRemoteReferenceName(remoteName, branch)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref %s: %w", refName, err)
+	}
+
+	parent, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	treeHash := parent.TreeHash
+	for _, p := range paths {
+		blobHash, err := storeBlob(repo.Storer, files[p])
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", p, err)
+		}
+		treeHash, err = insertTreeEntry(repo.Storer, treeHash, strings.Split(p, "/"), blobHash)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to tree: %w", p, err)
+		}
+	}
+
+	signature := object.Signature{
+		Name:  authorName,
+		Email: authorEmail,
+		When:  time.Now(),
+	}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{parent.Hash},
+	}
+
+	commitHash, err := g.storeCommit(repo.Storer, commit)
+	if err != nil {
+		return err
+	}
+
+	branchRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), commitHash)
+	if err := repo.Storer.SetReference(branchRef); err != nil {
+		return fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+
+	slog.Debug("committed files to branch", "branch", branch, "commit", commitHash.String())
+	return nil
+}
+
+// CommitHeadToBranch records the tree of HEAD as a merge commit on top of
+// remoteName/branch, with HEAD as its second parent, and points the local
+// branch at it. Pushing the branch then lets it merge cleanly into main.
+func (g *Git) CommitHeadToBranch(repoPath, remoteName, branch, message, authorName, authorEmail string) error {
+	slog.Debug("committing HEAD to branch", "path", repoPath, "remote", remoteName, "branch", branch)
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	refName := plumbing.NewRemoteReferenceName(remoteName, branch)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref %s: %w", refName, err)
+	}
+
+	signature := object.Signature{
+		Name:  authorName,
+		Email: authorEmail,
+		When:  time.Now(),
+	}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     headCommit.TreeHash,
+		ParentHashes: []plumbing.Hash{ref.Hash(), headCommit.Hash},
+	}
+
+	commitHash, err := g.storeCommit(repo.Storer, commit)
+	if err != nil {
+		return err
+	}
+
+	branchRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), commitHash)
+	if err := repo.Storer.SetReference(branchRef); err != nil {
+		return fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+
+	slog.Debug("committed HEAD to branch", "branch", branch, "commit", commitHash.String())
+	return nil
+}
+
+// storeCommit signs commit when a Signer is configured and writes it to s.
+func (g *Git) storeCommit(s storer.EncodedObjectStorer, commit *object.Commit) (plumbing.Hash, error) {
+	if g.Signer != nil {
+		unsigned := s.NewEncodedObject()
+		if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+		}
+		reader, err := unsigned.Reader()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read commit: %w", err)
+		}
+		signature, err := g.Signer.Sign(reader)
+		reader.Close()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to sign commit: %w", err)
+		}
+		commit.PGPSignature = string(signature)
+	}
+
+	obj := s.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	commitHash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store commit: %w", err)
+	}
+	return commitHash, nil
+}
+
+func storeBlob(s storer.EncodedObjectStorer, data []byte) (plumbing.Hash, error) {
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.SetEncodedObject(obj)
+}
+
+// insertTreeEntry returns treeHash with the file at parts set to blobHash.
+// A zero blobHash removes the file instead, along with any directories it
+// leaves empty.
+func insertTreeEntry(s storer.EncodedObjectStorer, treeHash plumbing.Hash, parts []string, blobHash plumbing.Hash) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	if !treeHash.IsZero() {
+		tree, err := object.GetTree(s, treeHash)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, tree.Entries...)
+	}
+
+	name := parts[0]
+	entry := object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: blobHash}
+	if len(parts) > 1 {
+		subTreeHash := plumbing.ZeroHash
+		for _, e := range entries {
+			if e.Name == name && e.Mode == filemode.Dir {
+				subTreeHash = e.Hash
+			}
+		}
+		hash, err := insertTreeEntry(s, subTreeHash, parts[1:], blobHash)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entry = object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash}
+	}
+
+	remove := entry.Hash.IsZero()
+	replaced := false
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Name == name {
+			if remove {
+				continue
+			}
+			e = entry
+			replaced = true
+		}
+		kept = append(kept, e)
+	}
+	entries = kept
+	if !replaced && !remove {
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return treeSortName(entries[i]) < treeSortName(entries[j])
+	})
+
+	obj := s.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.SetEncodedObject(obj)
+}
+
+// treeSortName matches git's tree ordering, which compares directories as if
+// their names ended with a slash.
+func treeSortName(e object.TreeEntry) string {
+	if e.Mode == filemode.Dir {
+		return e.Name + "/"
+	}
+	return e.Name
+}
+
+func (g *Git) PushBranch(repoPath, remoteName, branch string) error {
+	slog.Debug("pushing branch to remote", "path", repoPath, "remote", remoteName, "branch", branch)
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
+	err = repo.Push(&git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       g.getAuthForRemote(repo, remoteName),
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push branch: %w", remoteError(err))
+	}
+
+	slog.Debug("successfully pushed branch", "branch", branch)
+	return nil
+}
@@ -17,6 +17,7 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package git
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -192,6 +193,57 @@ func TestCommit_WithChanges(t *testing.T) {
 	}
 }
 
+type fakeSigner struct {
+	signed []string
+}
+
+func (f *fakeSigner) Sign(message io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	f.signed = append(f.signed, string(data))
+	return []byte("-----BEGIN PGP SIGNATURE-----\nfake\n-----END PGP SIGNATURE-----\n"), nil
+}
+
+func TestCommit_Signed(t *testing.T) {
+	tempDir := t.TempDir()
+	repoPath := filepath.Join(tempDir, "test-repo")
+
+	signer := &fakeSigner{}
+	g := New()
+	g.SetSigner(signer)
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "test.txt"), []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := g.Commit(repoPath, "signed commit", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+
+	repo, err := gogit.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to get commit: %v", err)
+	}
+
+	if !strings.Contains(commit.PGPSignature, "fake") {
+		t.Errorf("PGPSignature = %q, want the signer's output", commit.PGPSignature)
+	}
+	if len(signer.signed) != 1 || !strings.Contains(signer.signed[0], "signed commit") {
+		t.Errorf("signer was given %q, want the commit payload", signer.signed)
+	}
+}
+
 func TestCommit_InvalidRepo(t *testing.T) {
 	g := New()
 	err := g.Commit("/nonexistent/path", "test", "User", "user@example.com")
@@ -347,6 +399,51 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestRemoteHasCommits(t *testing.T) {
+	tempDir := t.TempDir()
+
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	g := New()
+	has, err := g.RemoteHasCommits("file://" + bareRepoPath)
+	if err != nil {
+		t.Fatalf("RemoteHasCommits() returned error: %v", err)
+	}
+	if has {
+		t.Error("RemoteHasCommits() = true for an empty repository")
+	}
+
+	srcPath := filepath.Join(tempDir, "src")
+	if err := g.Init(srcPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcPath, "secret.txt"), []byte("secret-data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := g.Commit(srcPath, "initial commit", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.ConfigureRemote(srcPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(srcPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	has, err = g.RemoteHasCommits("file://" + bareRepoPath)
+	if err != nil {
+		t.Fatalf("RemoteHasCommits() returned error: %v", err)
+	}
+	if !has {
+		t.Error("RemoteHasCommits() = false after a push")
+	}
+
+	if _, err := g.RemoteHasCommits("file://" + filepath.Join(tempDir, "missing.git")); err == nil {
+		t.Error("RemoteHasCommits() should fail for a missing repository")
+	}
+}
+
 func TestClone_InvalidURL(t *testing.T) {
 	tempDir := t.TempDir()
 	clonePath := filepath.Join(tempDir, "clone")
@@ -412,6 +509,13 @@ func TestCreateBranch(t *testing.T) {
 	if head.Name().Short() != "access-request/test-uuid" {
 		t.Errorf("HEAD branch = %q, want %q", head.Name().Short(), "access-request/test-uuid")
 	}
+	current, err := g.CurrentBranch(repoPath)
+	if err != nil {
+		t.Fatalf("CurrentBranch() returned error: %v", err)
+	}
+	if current != "access-request/test-uuid" {
+		t.Errorf("CurrentBranch() = %q, want %q", current, "access-request/test-uuid")
+	}
 }
 
 func TestCreateBranch_InvalidRepo(t *testing.T) {
@@ -495,6 +599,15 @@ func TestFetchBranches(t *testing.T) {
 	if !found["access-request/uuid1"] || !found["access-request/uuid2"] {
 		t.Errorf("FetchBranches() = %v, want access-request/uuid1 and access-request/uuid2", branches)
 	}
+
+	head, err := g.RemoteBranchHead(fetchRepoPath, "origin", "access-request/uuid1")
+	if err != nil {
+		t.Fatalf("RemoteBranchHead() returned error: %v", err)
+	}
+	data, err := g.ReadFileAtCommit(fetchRepoPath, head, "access-request_uuid1.txt")
+	if err != nil || string(data) != "access-request/uuid1" {
+		t.Errorf("RemoteBranchHead() = %s, whose file reads %q, %v", head, data, err)
+	}
 }
 
 func TestReadFilesFromBranch(t *testing.T) {
@@ -722,9 +835,25 @@ func TestDeleteRemoteBranch(t *testing.T) {
 		t.Fatalf("Push() returned error: %v", err)
 	}
 
+	exists, err := g.RemoteBranchExists(workRepoPath, "origin", "test-branch")
+	if err != nil {
+		t.Fatalf("RemoteBranchExists() returned error: %v", err)
+	}
+	if !exists {
+		t.Error("RemoteBranchExists() = false before delete, want true")
+	}
+
 	if err := g.DeleteRemoteBranch(workRepoPath, "origin", "test-branch"); err != nil {
 		t.Fatalf("DeleteRemoteBranch() returned error: %v", err)
 	}
+
+	exists, err = g.RemoteBranchExists(workRepoPath, "origin", "test-branch")
+	if err != nil {
+		t.Fatalf("RemoteBranchExists() returned error: %v", err)
+	}
+	if exists {
+		t.Error("RemoteBranchExists() = true after delete, want false")
+	}
 }
 
 func TestPush_WithLocalFileRemote(t *testing.T) {
@@ -756,3 +885,299 @@ func TestPush_WithLocalFileRemote(t *testing.T) {
 		t.Fatalf("Push() returned error: %v", err)
 	}
 }
+
+func TestCommitFilesToBranch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	pushRepoPath := filepath.Join(tempDir, "push")
+	g := New()
+	if err := g.Init(pushRepoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(pushRepoPath, "requests"), 0700); err != nil {
+		t.Fatalf("Failed to create requests dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pushRepoPath, "requests", "test-uuid.json.gpg"), []byte("request"), 0600); err != nil {
+		t.Fatalf("Failed to write request file: %v", err)
+	}
+	if err := g.Commit(pushRepoPath, "add request", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.ConfigureRemote(pushRepoPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(pushRepoPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+	if err := g.CreateBranch(pushRepoPath, "access-request/test-uuid"); err != nil {
+		t.Fatalf("CreateBranch() returned error: %v", err)
+	}
+	if err := g.Push(pushRepoPath, "origin", "access-request/test-uuid"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	approverRepoPath := filepath.Join(tempDir, "approver")
+	if err := g.Clone("file://"+bareRepoPath, approverRepoPath); err != nil {
+		t.Fatalf("Clone() returned error: %v", err)
+	}
+	if _, err := g.FetchBranches(approverRepoPath, "origin", "access-request/*"); err != nil {
+		t.Fatalf("FetchBranches() returned error: %v", err)
+	}
+
+	files := map[string][]byte{"approvals/test-uuid_ABC.asc": []byte("approval")}
+	if err := g.CommitFilesToBranch(approverRepoPath, "origin", "access-request/test-uuid", files, "approve", "Admin", "admin@test.com"); err != nil {
+		t.Fatalf("CommitFilesToBranch() returned error: %v", err)
+	}
+	if err := g.PushBranch(approverRepoPath, "origin", "access-request/test-uuid"); err != nil {
+		t.Fatalf("PushBranch() returned error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(approverRepoPath, "approvals")); !os.IsNotExist(err) {
+		t.Error("CommitFilesToBranch() should not write to the worktree")
+	}
+
+	if _, err := g.FetchBranches(pushRepoPath, "origin", "access-request/*"); err != nil {
+		t.Fatalf("FetchBranches() returned error: %v", err)
+	}
+
+	approvals, err := g.ReadFilesFromBranch(pushRepoPath, "origin", "access-request/test-uuid", "approvals")
+	if err != nil {
+		t.Fatalf("ReadFilesFromBranch() returned error: %v", err)
+	}
+	if string(approvals["test-uuid_ABC.asc"]) != "approval" {
+		t.Errorf("approval file = %q, want %q", approvals["test-uuid_ABC.asc"], "approval")
+	}
+
+	requests, err := g.ReadFilesFromBranch(pushRepoPath, "origin", "access-request/test-uuid", "requests")
+	if err != nil {
+		t.Fatalf("ReadFilesFromBranch() returned error: %v", err)
+	}
+	if string(requests["test-uuid.json.gpg"]) != "request" {
+		t.Error("existing files on the branch should be preserved")
+	}
+}
+
+func TestCommitHeadToBranch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	pushRepoPath := filepath.Join(tempDir, "push")
+	g := New()
+	if err := g.Init(pushRepoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pushRepoPath, ".gpg.id"), []byte("FP_AAA\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .gpg.id: %v", err)
+	}
+	if err := g.Commit(pushRepoPath, "init", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.ConfigureRemote(pushRepoPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(pushRepoPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+	if err := g.CreateBranch(pushRepoPath, "access-request/test-uuid"); err != nil {
+		t.Fatalf("CreateBranch() returned error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(pushRepoPath, "requests"), 0700); err != nil {
+		t.Fatalf("Failed to create requests dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pushRepoPath, "requests", "test-uuid.json.gpg"), []byte("request"), 0600); err != nil {
+		t.Fatalf("Failed to write request file: %v", err)
+	}
+	if err := g.Commit(pushRepoPath, "add request", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.Push(pushRepoPath, "origin", "access-request/test-uuid"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	approverRepoPath := filepath.Join(tempDir, "approver")
+	if err := g.Clone("file://"+bareRepoPath, approverRepoPath); err != nil {
+		t.Fatalf("Clone() returned error: %v", err)
+	}
+	if _, err := g.FetchBranches(approverRepoPath, "origin", "access-request/*"); err != nil {
+		t.Fatalf("FetchBranches() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(approverRepoPath, ".gpg.id"), []byte("FP_AAA\nFP_BBB\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .gpg.id: %v", err)
+	}
+	if err := g.Commit(approverRepoPath, "rekey", "Admin", "admin@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+
+	if err := g.CommitHeadToBranch(approverRepoPath, "origin", "access-request/test-uuid", "approve", "Admin", "admin@test.com"); err != nil {
+		t.Fatalf("CommitHeadToBranch() returned error: %v", err)
+	}
+	if err := g.PushBranch(approverRepoPath, "origin", "access-request/test-uuid"); err != nil {
+		t.Fatalf("PushBranch() returned error: %v", err)
+	}
+
+	repo, err := gogit.PlainOpen(approverRepoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("access-request/test-uuid"), true)
+	if err != nil {
+		t.Fatalf("branch not created: %v", err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.NumParents() != 2 {
+		t.Errorf("expected a merge commit with 2 parents, got %d", commit.NumParents())
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.TreeHash != headCommit.TreeHash {
+		t.Error("branch commit should carry the HEAD tree")
+	}
+}
+
+func TestIsLocalURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"file:///srv/git/secrets.git", true},
+		{"/srv/git/secrets.git", true},
+		{"https://github.com/owner/repo.git", false},
+		{"ssh://git@host/srv/secrets.git", false},
+		{"git@host:secrets.git", false},
+	}
+	for _, tt := range tests {
+		if got := IsLocalURL(tt.url); got != tt.want {
+			t.Errorf("IsLocalURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPullPush_LocalOnly(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	g := New()
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "secret.txt"), []byte("secret-data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := g.Commit(repoPath, "initial commit", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+
+	has, err := g.HasRemote(repoPath, "origin")
+	if err != nil || has {
+		t.Errorf("HasRemote() = %v, %v, want false", has, err)
+	}
+	if err := g.Pull(repoPath, "origin", "main", true); err != nil {
+		t.Errorf("Pull() on a local-only store returned error: %v", err)
+	}
+	if err := g.Push(repoPath, "origin", "main"); err != nil {
+		t.Errorf("Push() on a local-only store returned error: %v", err)
+	}
+}
+
+func TestClone_PlainPath(t *testing.T) {
+	tempDir := t.TempDir()
+
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	srcPath := filepath.Join(tempDir, "src")
+	g := NewWithAuth("token-never-sent")
+	if err := g.Init(srcPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcPath, "secret.txt"), []byte("secret-data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := g.Commit(srcPath, "initial commit", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.ConfigureRemote(srcPath, "origin", bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(srcPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	clonePath := filepath.Join(tempDir, "clone")
+	if err := g.Clone(bareRepoPath, clonePath); err != nil {
+		t.Fatalf("Clone() returned error: %v", err)
+	}
+	if err := g.Pull(clonePath, "origin", "main", true); err != nil {
+		t.Fatalf("Pull() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(clonePath, "secret.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read cloned file: %v", err)
+	}
+	if string(data) != "secret-data" {
+		t.Errorf("Cloned file content = %q, want %q", string(data), "secret-data")
+	}
+}
+
+func TestPull_BrokenLocalBranch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	srcPath := filepath.Join(tempDir, "src")
+	g := New()
+	if err := g.Init(srcPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcPath, "secret.txt"), []byte("secret-data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := g.Commit(srcPath, "initial commit", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := g.ConfigureRemote(srcPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(srcPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	// A local branch that cannot be resolved must not be treated as missing,
+	// which would skip the verifier and reset onto the remote tip.
+	repo, err := gogit.PlainOpen(srcPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	loop := plumbing.NewSymbolicReference(plumbing.NewBranchReferenceName("main"), plumbing.NewBranchReferenceName("main"))
+	if err := repo.Storer.SetReference(loop); err != nil {
+		t.Fatalf("Failed to break local branch: %v", err)
+	}
+
+	verified := false
+	g.SetVerifier(func(repoPath, from, to string) error {
+		verified = true
+		return nil
+	})
+	if err := g.Pull(srcPath, "origin", "main", true); err == nil {
+		t.Error("Pull() with an unresolvable local branch should return error")
+	}
+	if verified {
+		t.Error("Pull() ran the verifier for an unresolvable local branch")
+	}
+}
@@ -0,0 +1,374 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// IncomingCommit is a commit that a pull would add to the first-parent
+// history of a branch.
+type IncomingCommit struct {
+	Hash string
+	// Parent is the first parent, the state the commit is judged against.
+	Parent string
+	// Signature is the armored PGP signature, empty for unsigned commits.
+	Signature string
+	// Payload is the commit encoded without its signature, as it was signed.
+	Payload []byte
+	// Changed lists the paths that differ from Parent.
+	Changed []string
+	// SameTree lists other parents that descend from Parent and whose tree
+	// equals this commit's. A merge that adds nothing beyond such a parent
+	// can be vouched for by it.
+	SameTree []string
+}
+
+// IncomingCommits walks the first-parent history of to, newest first, until
+// it reaches a commit that from already contains. It fails when to does not
+// descend from anything in from, which means the history was rewritten.
+func (g *Git) IncomingCommits(repoPath, from, to string) ([]IncomingCommit, error) {
+	slog.Debug("listing incoming commits", "path", repoPath, "from", from, "to", to)
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	known := make(map[plumbing.Hash]bool)
+	iter, err := repo.Log(&git.LogOptions{From: plumbing.NewHash(from)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", from, err)
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		known[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", from, err)
+	}
+
+	var incoming []IncomingCommit
+	hash := plumbing.NewHash(to)
+	for !known[hash] {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+		}
+		if commit.NumParents() == 0 {
+			return nil, fmt.Errorf("history of %s does not contain %s", to, from)
+		}
+
+		entry, err := describeIncoming(repo.Storer, commit)
+		if err != nil {
+			return nil, err
+		}
+		incoming = append(incoming, entry)
+		hash = commit.ParentHashes[0]
+	}
+
+	slog.Debug("incoming commits", "count", len(incoming))
+	return incoming, nil
+}
+
+func describeIncoming(s storer.EncodedObjectStorer, commit *object.Commit) (IncomingCommit, error) {
+	entry := IncomingCommit{
+		Hash:      commit.Hash.String(),
+		Parent:    commit.ParentHashes[0].String(),
+		Signature: commit.PGPSignature,
+	}
+
+	if commit.PGPSignature != "" {
+		payload, err := signedPayload(s, commit)
+		if err != nil {
+			return entry, err
+		}
+		entry.Payload = payload
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return entry, fmt.Errorf("failed to get parent of %s: %w", commit.Hash, err)
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return entry, fmt.Errorf("failed to get tree: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return entry, fmt.Errorf("failed to get tree: %w", err)
+	}
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return entry, fmt.Errorf("failed to diff %s: %w", commit.Hash, err)
+	}
+	for _, change := range changes {
+		if change.From.Name != "" {
+			entry.Changed = append(entry.Changed, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			entry.Changed = append(entry.Changed, change.To.Name)
+		}
+	}
+
+	for i, parentHash := range commit.ParentHashes[1:] {
+		other, err := commit.Parent(i + 1)
+		if err != nil {
+			return entry, fmt.Errorf("failed to get parent of %s: %w", commit.Hash, err)
+		}
+		if other.TreeHash != commit.TreeHash {
+			continue
+		}
+		// Only a parent built on top of the first parent can vouch for the
+		// merge; an older one would roll the branch back.
+		descends, err := parent.IsAncestor(other)
+		if err != nil {
+			return entry, fmt.Errorf("failed to walk history of %s: %w", commit.Hash, err)
+		}
+		if descends {
+			entry.SameTree = append(entry.SameTree, parentHash.String())
+		}
+	}
+
+	return entry, nil
+}
+
+// CommitSignature returns a commit's armored signature together with the
+// payload it covers.
+func (g *Git) CommitSignature(repoPath, hash string) (string, []byte, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	if commit.PGPSignature == "" {
+		return "", nil, nil
+	}
+
+	payload, err := signedPayload(repo.Storer, commit)
+	if err != nil {
+		return "", nil, err
+	}
+	return commit.PGPSignature, payload, nil
+}
+
+func signedPayload(s storer.EncodedObjectStorer, commit *object.Commit) ([]byte, error) {
+	obj := s.NewEncodedObject()
+	if err := commit.EncodeWithoutSignature(obj); err != nil {
+		return nil, fmt.Errorf("failed to encode commit %s: %w", commit.Hash, err)
+	}
+	reader, err := obj.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", commit.Hash, err)
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", commit.Hash, err)
+	}
+	return payload, nil
+}
+
+// ReadFilesAtCommit returns the regular files directly inside dirPath as of
+// the given commit. An empty dirPath reads the root of the store.
+func (g *Git) ReadFilesAtCommit(repoPath, hash, dirPath string) (map[string][]byte, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	return readCommitDir(commit, dirPath)
+}
+
+// ReadFileAtCommit returns the content of path as of the given commit.
+func (g *Git) ReadFileAtCommit(repoPath, hash, path string) ([]byte, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	f, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, hash, err)
+	}
+	reader, err := f.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, hash, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+)
+
+type FileChange struct {
+	Path   string
+	Action string
+}
+
+// LogEntry is a commit on the first-parent history of HEAD.
+type LogEntry struct {
+	Hash string
+	// Parent is the first parent, empty for the root commit.
+	Parent  string
+	Author  string
+	Email   string
+	When    time.Time
+	Message string
+	Changes []FileChange
+	// Signature and Payload are empty for unsigned commits. For a merge
+	// reported with another parent's author they come from that parent.
+	Signature string
+	Payload   []byte
+}
+
+// Log returns the first-parent history of HEAD back to since, newest first,
+// with each commit's changes relative to its first parent. A merge that
+// takes another parent's tree unchanged is reported with that parent's
+// author and message, since the merge itself is usually made by the forge.
+func (g *Git) Log(repoPath string, since time.Time) ([]LogEntry, error) {
+	slog.Debug("reading history", "path", repoPath, "since", since)
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	var entries []LogEntry
+	commit, err := repo.CommitObject(head.Hash())
+	for err == nil {
+		if commit.Committer.When.Before(since) {
+			break
+		}
+
+		entry, logErr := describeLogEntry(repo.Storer, commit)
+		if logErr != nil {
+			return nil, logErr
+		}
+		entries = append(entries, entry)
+
+		if commit.NumParents() == 0 {
+			break
+		}
+		commit, err = commit.Parent(0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+
+	slog.Debug("read history", "count", len(entries))
+	return entries, nil
+}
+
+func describeLogEntry(s storer.EncodedObjectStorer, commit *object.Commit) (LogEntry, error) {
+	entry := LogEntry{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		When:    commit.Author.When,
+		Message: strings.TrimSpace(commit.Message),
+	}
+	signed := commit
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return entry, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	parentTree := &object.Tree{}
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return entry, fmt.Errorf("failed to get parent of %s: %w", commit.Hash, err)
+		}
+		entry.Parent = parent.Hash.String()
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return entry, fmt.Errorf("failed to get tree: %w", err)
+		}
+
+		for i := 1; i < commit.NumParents(); i++ {
+			other, err := commit.Parent(i)
+			if err != nil {
+				return entry, fmt.Errorf("failed to get parent of %s: %w", commit.Hash, err)
+			}
+			if other.TreeHash == commit.TreeHash {
+				entry.Author = other.Author.Name
+				entry.Email = other.Author.Email
+				entry.When = other.Author.When
+				entry.Message = strings.TrimSpace(other.Message)
+				if commit.PGPSignature == "" {
+					signed = other
+				}
+				break
+			}
+		}
+	}
+
+	if signed.PGPSignature != "" {
+		payload, err := signedPayload(s, signed)
+		if err != nil {
+			return entry, err
+		}
+		entry.Signature = signed.PGPSignature
+		entry.Payload = payload
+	}
+
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return entry, fmt.Errorf("failed to diff %s: %w", commit.Hash, err)
+	}
+	for _, change := range changes {
+		switch {
+		case change.From.Name == "":
+			entry.Changes = append(entry.Changes, FileChange{Path: change.To.Name, Action: ChangeAdded})
+		case change.To.Name == "":
+			entry.Changes = append(entry.Changes, FileChange{Path: change.From.Name, Action: ChangeDeleted})
+		default:
+			entry.Changes = append(entry.Changes, FileChange{Path: change.To.Name, Action: ChangeModified})
+		}
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+func commitFile(t *testing.T, g *Git, repoPath, name, content, message string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if err := g.Commit(repoPath, message, "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	repo, err := gogit.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	return head.Hash().String()
+}
+
+func TestIncomingCommits(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	g := New()
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	base := commitFile(t, g, repoPath, ".gpg.id", "FP_AAA\n", "init")
+	g.SetSigner(&fakeSigner{})
+	second := commitFile(t, g, repoPath, "keys/FP_AAA.key", "key", "add key")
+	third := commitFile(t, g, repoPath, "prod/secret.gpg", "secret", "add secret")
+
+	commits, err := g.IncomingCommits(repoPath, base, third)
+	if err != nil {
+		t.Fatalf("IncomingCommits() returned error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("IncomingCommits() returned %d commits, want 2", len(commits))
+	}
+	if commits[0].Hash != third || commits[0].Parent != second {
+		t.Errorf("commits[0] = %s (parent %s), want %s (parent %s)", commits[0].Hash, commits[0].Parent, third, second)
+	}
+	if len(commits[1].Changed) != 1 || commits[1].Changed[0] != "keys/FP_AAA.key" {
+		t.Errorf("commits[1].Changed = %v, want [keys/FP_AAA.key]", commits[1].Changed)
+	}
+	if !strings.Contains(commits[0].Signature, "fake") || !strings.Contains(string(commits[0].Payload), "add secret") {
+		t.Errorf("commits[0] signature = %q, payload = %q", commits[0].Signature, commits[0].Payload)
+	}
+
+	none, err := g.IncomingCommits(repoPath, third, second)
+	if err != nil {
+		t.Fatalf("IncomingCommits() returned error: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("IncomingCommits() from a descendant returned %d commits, want 0", len(none))
+	}
+}
+
+func TestLog_Signed(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	g := New()
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	commitFile(t, g, repoPath, ".gpg.id", "FP_AAA\n", "init")
+	g.SetSigner(&fakeSigner{})
+	commitFile(t, g, repoPath, "a/b.gpg", "secret", "add secret")
+
+	entries, err := g.Log(repoPath, time.Time{})
+	if err != nil {
+		t.Fatalf("Log() returned error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Log() returned %d entries, want 2", len(entries))
+	}
+	if !strings.Contains(entries[0].Signature, "fake") || !strings.Contains(string(entries[0].Payload), "add secret") {
+		t.Errorf("signed commit signature = %q, payload = %q", entries[0].Signature, entries[0].Payload)
+	}
+	if entries[1].Signature != "" {
+		t.Errorf("unsigned commit signature = %q, want empty", entries[1].Signature)
+	}
+}
+
+func TestIncomingCommits_RewrittenHistory(t *testing.T) {
+	tempDir := t.TempDir()
+	g := New()
+
+	first := filepath.Join(tempDir, "first")
+	if err := g.Init(first); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	from := commitFile(t, g, first, ".gpg.id", "FP_AAA\n", "init")
+
+	other := filepath.Join(tempDir, "other")
+	if err := g.Init(other); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, other, ".gpg.id", "FP_EVIL\n", "init")
+	if err := g.ConfigureRemote(first, "origin", "file://"+other); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+
+	var verified bool
+	g.SetVerifier(func(repoPath, from, to string) error {
+		verified = true
+		_, err := g.IncomingCommits(repoPath, from, to)
+		return err
+	})
+	err := g.Pull(first, "origin", "main", true)
+	if err == nil {
+		t.Fatal("Pull() succeeded over rewritten history")
+	}
+	if !verified {
+		t.Error("Pull() did not call the verifier")
+	}
+
+	repo, err := gogit.PlainOpen(first)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	if head.Hash().String() != from {
+		t.Errorf("HEAD = %s after refused pull, want %s", head.Hash(), from)
+	}
+}
+
+func TestPull_VerifierRefuses(t *testing.T) {
+	tempDir := t.TempDir()
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	g := New()
+	pushRepoPath := filepath.Join(tempDir, "push")
+	if err := g.Init(pushRepoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, pushRepoPath, ".gpg.id", "FP_AAA\n", "init")
+	if err := g.ConfigureRemote(pushRepoPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(pushRepoPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	pullRepoPath := filepath.Join(tempDir, "pull")
+	if err := g.Clone("file://"+bareRepoPath, pullRepoPath); err != nil {
+		t.Fatalf("Clone() returned error: %v", err)
+	}
+
+	commitFile(t, g, pushRepoPath, ".gpg.id", "FP_AAA\nFP_EVIL\n", "add recipient")
+	if err := g.Push(pushRepoPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	puller := New()
+	puller.SetVerifier(func(repoPath, from, to string) error {
+		return errors.New("untrusted")
+	})
+	if err := puller.Pull(pullRepoPath, "origin", "main", true); err == nil {
+		t.Fatal("Pull() succeeded although the verifier refused")
+	}
+
+	data, err := os.ReadFile(filepath.Join(pullRepoPath, ".gpg.id"))
+	if err != nil {
+		t.Fatalf("Failed to read .gpg.id: %v", err)
+	}
+	if string(data) != "FP_AAA\n" {
+		t.Errorf(".gpg.id = %q after refused pull, want the trusted version", data)
+	}
+}
+
+func TestLog(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	g := New()
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	commitFile(t, g, repoPath, ".gpg.id", "FP_AAA\n", "init")
+	commitFile(t, g, repoPath, "a/b.gpg", "secret", "add secret")
+	commitFile(t, g, repoPath, "a/b.gpg", "changed", "update secret")
+
+	entries, err := g.Log(repoPath, time.Time{})
+	if err != nil {
+		t.Fatalf("Log() returned error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Log() returned %d entries, want 3", len(entries))
+	}
+	if entries[0].Message != "update secret" || entries[2].Message != "init" {
+		t.Errorf("Log() messages = %q, %q, want newest first", entries[0].Message, entries[2].Message)
+	}
+	if len(entries[0].Changes) != 1 || entries[0].Changes[0] != (FileChange{Path: "a/b.gpg", Action: ChangeModified}) {
+		t.Errorf("entries[0].Changes = %v", entries[0].Changes)
+	}
+	if len(entries[2].Changes) != 1 || entries[2].Changes[0] != (FileChange{Path: ".gpg.id", Action: ChangeAdded}) {
+		t.Errorf("root commit changes = %v", entries[2].Changes)
+	}
+	if entries[2].Parent != "" {
+		t.Errorf("root commit parent = %q, want empty", entries[2].Parent)
+	}
+	if entries[0].Signature != "" || entries[0].Payload != nil {
+		t.Errorf("unsigned commit signature = %q, payload = %q, want empty", entries[0].Signature, entries[0].Payload)
+	}
+
+	recent, err := g.Log(repoPath, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Log() returned error: %v", err)
+	}
+	if len(recent) != 0 {
+		t.Errorf("Log() since the future returned %d entries, want 0", len(recent))
+	}
+
+	data, err := g.ReadFileAtCommit(repoPath, entries[1].Hash, "a/b.gpg")
+	if err != nil || string(data) != "secret" {
+		t.Errorf("ReadFileAtCommit() = %q, %v, want \"secret\"", data, err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// ErrUnreachable marks fetches and pushes that failed because the remote
+// could not be contacted at all, as opposed to refusing the operation.
+var ErrUnreachable = errors.New("remote unreachable")
+
+const (
+	keprSection   = "kepr"
+	lastFetchName = "lastFetch"
+)
+
+func remoteError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	return err
+}
+
+// recordFetch remembers when the remote was last fetched, in the
+// repository's own git config.
+func recordFetch(repo *git.Repository) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Raw.Section(keprSection).SetOption(lastFetchName, time.Now().UTC().Format(time.RFC3339))
+	return repo.SetConfig(cfg)
+}
+
+// LastFetch returns when Pull last fetched the remote, or the zero time if
+// it never has.
+func (g *Git) LastFetch(repoPath string) (time.Time, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to open repository: %w", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read repository config: %w", err)
+	}
+
+	value := cfg.Raw.Section(keprSection).Option(lastFetchName)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	when, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s.%s %q: %w", keprSection, lastFetchName, value, err)
+	}
+	return when, nil
+}
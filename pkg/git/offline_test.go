@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPush_Unreachable(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	g := New()
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, repoPath, ".gpg.id", "FP_AAA\n", "init")
+	// Nothing listens on port 1.
+	if err := g.ConfigureRemote(repoPath, "origin", "http://127.0.0.1:1/store.git"); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+
+	if err := g.Push(repoPath, "origin", "main"); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Push() error = %v, want ErrUnreachable", err)
+	}
+	if err := g.Pull(repoPath, "origin", "main", true); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Pull() error = %v, want ErrUnreachable", err)
+	}
+}
+
+func TestLastFetch(t *testing.T) {
+	tempDir := t.TempDir()
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	g := New()
+	repoPath := filepath.Join(tempDir, "repo")
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, repoPath, ".gpg.id", "FP_AAA\n", "init")
+	if err := g.ConfigureRemote(repoPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(repoPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	last, err := g.LastFetch(repoPath)
+	if err != nil {
+		t.Fatalf("LastFetch() returned error: %v", err)
+	}
+	if !last.IsZero() {
+		t.Errorf("LastFetch() = %v before any pull, want zero", last)
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := g.Pull(repoPath, "origin", "main", true); err != nil {
+		t.Fatalf("Pull() returned error: %v", err)
+	}
+	last, err = g.LastFetch(repoPath)
+	if err != nil {
+		t.Fatalf("LastFetch() returned error: %v", err)
+	}
+	if last.Before(before) {
+		t.Errorf("LastFetch() = %v, want the time of the pull", last)
+	}
+}
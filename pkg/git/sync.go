@@ -0,0 +1,407 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrDiverged is returned by Pull when the local branch has commits that
+// are not on the remote and no Signer is set to rebase them.
+var ErrDiverged = errors.New("local branch has commits that are not on the remote")
+
+// ErrConflict is returned by Pull when local and remote commits change the
+// same files and no ConflictResolver is set.
+var ErrConflict = errors.New("local and remote changes conflict")
+
+// Conflict is a group of files that both the local and the remote branch
+// changed, differently, since they diverged.
+type Conflict struct {
+	Key   string
+	Paths []string
+	// Local and Remote are the branch tips, for reading either side.
+	Local  string
+	Remote string
+	// Recipients is set when the remote changed this .gpg.id and Paths are
+	// local changes below its directory, encrypted for the old recipients.
+	// Such changes cannot be kept as they are: keeping them stops the pull.
+	Recipients string
+}
+
+// ConflictResolver lets the caller decide what counts as a conflict and how
+// each one is settled.
+type ConflictResolver interface {
+	// Key groups paths that must be kept or dropped together.
+	Key(path string) string
+	// KeepLocal reports whether the local side of c wins.
+	KeepLocal(c Conflict) (bool, error)
+}
+
+// integrate returns the commit a pull of branch should leave the local
+// branch at. Local commits are kept: when the remote moved on too they are
+// rebased onto it, which needs a Signer so the new commits stay verifiable.
+func (g *Git) integrate(repo *git.Repository, repoPath, branch string, local, remote plumbing.Hash) (plumbing.Hash, error) {
+	localCommit, err := repo.CommitObject(local)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get commit %s: %w", local, err)
+	}
+	remoteCommit, err := repo.CommitObject(remote)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get commit %s: %w", remote, err)
+	}
+
+	var base *object.Commit
+	bases, err := localCommit.MergeBase(remoteCommit)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	if len(bases) > 0 {
+		base = bases[0]
+	}
+
+	if base != nil && base.Hash == remote {
+		slog.Debug("local branch is ahead of remote", "branch", branch)
+		return local, nil
+	}
+
+	if g.Verifier != nil {
+		if err := g.Verifier(repoPath, local.String(), remote.String()); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("refusing to pull %s: %w", branch, err)
+		}
+	}
+
+	if base == nil || base.Hash == local {
+		return remote, nil
+	}
+	if g.Signer == nil {
+		return plumbing.ZeroHash, ErrDiverged
+	}
+	return g.rebase(repo, base, localCommit, remoteCommit)
+}
+
+// rebase replays the first-parent history from base to local on top of
+// remote, as new signed commits with the original authors and messages.
+func (g *Git) rebase(repo *git.Repository, base, local, remote *object.Commit) (plumbing.Hash, error) {
+	slog.Debug("rebasing local commits", "base", base.Hash.String(), "local", local.Hash.String(), "remote", remote.Hash.String())
+
+	localChanges, err := changedFiles(base, local)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	remoteChanges, err := changedFiles(base, remote)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	dropped := make(map[string]bool)
+	for _, c := range g.conflicts(localChanges, remoteChanges, local.Hash.String(), remote.Hash.String()) {
+		if g.Resolver == nil {
+			return plumbing.ZeroHash, fmt.Errorf("%w: %s", ErrConflict, strings.Join(c.Paths, ", "))
+		}
+		keep, err := g.Resolver.KeepLocal(c)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if !keep {
+			dropped[c.Key] = true
+		}
+	}
+
+	recipientConflicts, err := g.recipientConflicts(localChanges, remoteChanges, dropped, local, remote)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	for _, c := range recipientConflicts {
+		// An earlier directory may already have dropped some of the paths.
+		c.Paths = slices.DeleteFunc(c.Paths, func(p string) bool { return dropped[g.conflictKey(p)] })
+		if len(c.Paths) == 0 {
+			continue
+		}
+		keep := true
+		if g.Resolver != nil {
+			keep, err = g.Resolver.KeepLocal(c)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+		}
+		if keep {
+			return plumbing.ZeroHash, fmt.Errorf("%w: the remote changed %s, and local changes to %s are encrypted for the old recipients",
+				ErrConflict, c.Recipients, strings.Join(c.Paths, ", "))
+		}
+		for _, p := range c.Paths {
+			dropped[g.conflictKey(p)] = true
+		}
+	}
+
+	var commits []*object.Commit
+	for commit := local; commit.Hash != base.Hash; {
+		commits = append(commits, commit)
+		if commit.NumParents() == 0 {
+			return plumbing.ZeroHash, fmt.Errorf("%s is not an ancestor of %s", base.Hash, local.Hash)
+		}
+		commit, err = commit.Parent(0)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to walk local history: %w", err)
+		}
+	}
+
+	head := remote.Hash
+	tree := remote.TreeHash
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to get parent of %s: %w", commit.Hash, err)
+		}
+		changes, err := changedFiles(parent, commit)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		newTree := tree
+		for _, p := range sortedKeys(changes) {
+			if dropped[g.conflictKey(p)] {
+				continue
+			}
+			newTree, err = insertTreeEntry(repo.Storer, newTree, strings.Split(p, "/"), changes[p])
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to apply %s: %w", p, err)
+			}
+		}
+		if newTree.IsZero() {
+			return plumbing.ZeroHash, fmt.Errorf("replaying %s would leave the repository empty", commit.Hash)
+		}
+		if newTree == tree {
+			slog.Debug("dropping commit with nothing left to apply", "commit", commit.Hash.String())
+			continue
+		}
+
+		rebased := &object.Commit{
+			Author: commit.Author,
+			Committer: object.Signature{
+				Name:  commit.Committer.Name,
+				Email: commit.Committer.Email,
+				When:  time.Now(),
+			},
+			Message:      commit.Message,
+			TreeHash:     newTree,
+			ParentHashes: []plumbing.Hash{head},
+		}
+		head, err = g.storeCommit(repo.Storer, rebased)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree = newTree
+	}
+
+	slog.Debug("rebased local commits", "count", len(commits), "head", head.String())
+	return head, nil
+}
+
+// conflicts groups the changed files by key and reports the groups that the
+// two sides left in different states.
+func (g *Git) conflicts(local, remote map[string]plumbing.Hash, localTip, remoteTip string) []Conflict {
+	localKeys := make(map[string][]string)
+	for p := range local {
+		localKeys[g.conflictKey(p)] = append(localKeys[g.conflictKey(p)], p)
+	}
+	remoteKeys := make(map[string][]string)
+	for p := range remote {
+		remoteKeys[g.conflictKey(p)] = append(remoteKeys[g.conflictKey(p)], p)
+	}
+
+	var conflicts []Conflict
+	for _, key := range sortedKeys(localKeys) {
+		if _, ok := remoteKeys[key]; !ok {
+			continue
+		}
+
+		paths := make(map[string]bool)
+		differ := false
+		for _, p := range append(append([]string{}, localKeys[key]...), remoteKeys[key]...) {
+			paths[p] = true
+			l, inLocal := local[p]
+			r, inRemote := remote[p]
+			if inLocal != inRemote || l != r {
+				differ = true
+			}
+		}
+		if differ {
+			conflicts = append(conflicts, Conflict{Key: key, Paths: sortedKeys(paths), Local: localTip, Remote: remoteTip})
+		}
+	}
+	return conflicts
+}
+
+// recipientConflicts reports, for each .gpg.id the remote changed, the local
+// additions and updates of encrypted files in its directory and in the
+// folders below it. Those files were encrypted for the recipients before the
+// remote change, so replaying them would leave them readable by keys the
+// directory no longer lists, or unreadable by keys it now does. Paths
+// already dropped are left out, as are directories whose .gpg.id the local
+// side changed and kept, and top-level folders without a .gpg.id of their
+// own, which hold the store's bookkeeping rather than secrets.
+func (g *Git) recipientConflicts(local, remote map[string]plumbing.Hash, dropped map[string]bool, localTip, remoteTip *object.Commit) ([]Conflict, error) {
+	remoteTree, err := remoteTip.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	hasRecipients := func(dir string) bool {
+		if h, ok := local[dir+"/.gpg.id"]; ok && !h.IsZero() {
+			return true
+		}
+		_, err := remoteTree.FindEntry(dir + "/.gpg.id")
+		return err == nil
+	}
+
+	var conflicts []Conflict
+	for _, r := range sortedKeys(remote) {
+		if path.Base(r) != ".gpg.id" {
+			continue
+		}
+		if l, ok := local[r]; ok && (l == remote[r] || !dropped[g.conflictKey(r)]) {
+			continue
+		}
+
+		dir := path.Dir(r)
+		var paths []string
+		for _, p := range sortedKeys(local) {
+			if !strings.HasSuffix(p, ".gpg") || local[p].IsZero() || dropped[g.conflictKey(p)] {
+				continue
+			}
+			rel := p
+			if dir != "." {
+				var ok bool
+				if rel, ok = strings.CutPrefix(p, dir+"/"); !ok {
+					continue
+				}
+			}
+			if sub, _, nested := strings.Cut(rel, "/"); nested && !hasRecipients(path.Join(dir, sub)) {
+				continue
+			}
+			paths = append(paths, p)
+		}
+		if len(paths) > 0 {
+			conflicts = append(conflicts, Conflict{
+				Key:        r,
+				Paths:      paths,
+				Local:      localTip.Hash.String(),
+				Remote:     remoteTip.Hash.String(),
+				Recipients: r,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+func (g *Git) conflictKey(path string) string {
+	if g.Resolver == nil {
+		return path
+	}
+	return g.Resolver.Key(path)
+}
+
+// changedFiles maps each path that differs between from and to to its blob
+// in to, or to the zero hash when to deletes it.
+func changedFiles(from, to *object.Commit) (map[string]plumbing.Hash, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	changes, err := fromTree.Diff(toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s and %s: %w", from.Hash, to.Hash, err)
+	}
+
+	files := make(map[string]plumbing.Hash, len(changes))
+	for _, change := range changes {
+		if change.To.Name == "" {
+			files[change.From.Name] = plumbing.ZeroHash
+			continue
+		}
+		files[change.To.Name] = change.To.TreeEntry.Hash
+	}
+	return files, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UnpushedCommits counts the commits on the first-parent history of the
+// local branch that the remote-tracking branch does not contain.
+func (g *Git) UnpushedCommits(repoPath, remoteName, branch string) (int, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+	}
+
+	known := make(map[plumbing.Hash]bool)
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), true)
+	if err == nil {
+		iter, err := repo.Log(&git.LogOptions{From: remoteRef.Hash()})
+		if err != nil {
+			return 0, fmt.Errorf("failed to read history of %s/%s: %w", remoteName, branch, err)
+		}
+		err = iter.ForEach(func(c *object.Commit) error {
+			known[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to read history of %s/%s: %w", remoteName, branch, err)
+		}
+	}
+
+	count := 0
+	commit, err := repo.CommitObject(localRef.Hash())
+	for err == nil && !known[commit.Hash] {
+		count++
+		if commit.NumParents() == 0 {
+			break
+		}
+		commit, err = commit.Parent(0)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk history of %s: %w", branch, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,388 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// divergedClones returns a clone with one local commit and a remote that
+// has moved on with another, both on top of a shared .gpg.id.
+func divergedClones(t *testing.T, localFile, localContent, remoteFile, remoteContent string) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	g := New()
+	otherPath := filepath.Join(tempDir, "other")
+	if err := g.Init(otherPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, otherPath, ".gpg.id", "FP_AAA\n", "init")
+	if err := g.ConfigureRemote(otherPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(otherPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+
+	localPath := filepath.Join(tempDir, "local")
+	if err := g.Clone("file://"+bareRepoPath, localPath); err != nil {
+		t.Fatalf("Clone() returned error: %v", err)
+	}
+	local := commitFile(t, g, localPath, localFile, localContent, "local change")
+
+	commitFile(t, g, otherPath, remoteFile, remoteContent, "remote change")
+	if err := g.Push(otherPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+	return localPath, local
+}
+
+func readFile(t *testing.T, repoPath, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(repoPath, name))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+type keepResolver struct {
+	keepLocal bool
+	asked     []Conflict
+}
+
+func (r *keepResolver) Key(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(path, ".gpg"), "_md")
+}
+
+func (r *keepResolver) KeepLocal(c Conflict) (bool, error) {
+	r.asked = append(r.asked, c)
+	return r.keepLocal, nil
+}
+
+func TestPull_KeepsCommitsAheadOfRemote(t *testing.T) {
+	tempDir := t.TempDir()
+	bareRepoPath := filepath.Join(tempDir, "bare.git")
+	createBareRepo(t, bareRepoPath)
+
+	g := New()
+	repoPath := filepath.Join(tempDir, "repo")
+	if err := g.Init(repoPath); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+	commitFile(t, g, repoPath, ".gpg.id", "FP_AAA\n", "init")
+	if err := g.ConfigureRemote(repoPath, "origin", "file://"+bareRepoPath); err != nil {
+		t.Fatalf("ConfigureRemote() returned error: %v", err)
+	}
+	if err := g.Push(repoPath, "origin", "main"); err != nil {
+		t.Fatalf("Push() returned error: %v", err)
+	}
+	unpushed := commitFile(t, g, repoPath, "a.gpg", "secret", "add secret")
+
+	if err := g.Pull(repoPath, "origin", "main", true); err != nil {
+		t.Fatalf("Pull() returned error: %v", err)
+	}
+
+	repo, err := gogit.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	if head.Hash().String() != unpushed {
+		t.Errorf("HEAD = %s after pull, want unpushed commit %s", head.Hash(), unpushed)
+	}
+	if got := readFile(t, repoPath, "a.gpg"); got != "secret" {
+		t.Errorf("a.gpg = %q after pull, want %q", got, "secret")
+	}
+
+	count, err := g.UnpushedCommits(repoPath, "origin", "main")
+	if err != nil {
+		t.Fatalf("UnpushedCommits() returned error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("UnpushedCommits() = %d, want 1", count)
+	}
+}
+
+func TestPull_DivergedWithoutSigner(t *testing.T) {
+	localPath, local := divergedClones(t, "a.gpg", "local", "b.gpg", "remote")
+
+	err := New().Pull(localPath, "origin", "main", true)
+	if !errors.Is(err, ErrDiverged) {
+		t.Fatalf("Pull() error = %v, want ErrDiverged", err)
+	}
+
+	repo, err := gogit.PlainOpen(localPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	if head.Hash().String() != local {
+		t.Errorf("HEAD = %s, want local commit %s kept", head.Hash(), local)
+	}
+}
+
+func TestPull_RebasesLocalCommits(t *testing.T) {
+	localPath, local := divergedClones(t, "a.gpg", "local", "b.gpg", "remote")
+
+	signer := &fakeSigner{}
+	g := New()
+	g.SetSigner(signer)
+	if err := g.Pull(localPath, "origin", "main", true); err != nil {
+		t.Fatalf("Pull() returned error: %v", err)
+	}
+
+	if got := readFile(t, localPath, "a.gpg"); got != "local" {
+		t.Errorf("a.gpg = %q, want the local change", got)
+	}
+	if got := readFile(t, localPath, "b.gpg"); got != "remote" {
+		t.Errorf("b.gpg = %q, want the remote change", got)
+	}
+
+	repo, err := gogit.PlainOpen(localPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to get commit: %v", err)
+	}
+	if commit.Hash.String() == local || commit.Message != "local change" {
+		t.Errorf("HEAD = %s %q, want a rebased copy of the local commit", commit.Hash, commit.Message)
+	}
+	if commit.PGPSignature == "" || len(signer.signed) != 1 {
+		t.Error("rebased commit was not signed")
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		t.Fatalf("Failed to get parent: %v", err)
+	}
+	if parent.Message != "remote change" {
+		t.Errorf("rebased commit parent = %q, want the remote commit", parent.Message)
+	}
+
+	count, err := g.UnpushedCommits(localPath, "origin", "main")
+	if err != nil {
+		t.Fatalf("UnpushedCommits() returned error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("UnpushedCommits() = %d, want 1", count)
+	}
+}
+
+func TestPull_Conflict(t *testing.T) {
+	t.Run("without resolver", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "local", "a.gpg", "remote")
+
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		err := g.Pull(localPath, "origin", "main", true)
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("Pull() error = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("same key, different files", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "local", "a_md.gpg", "remote")
+
+		resolver := &keepResolver{keepLocal: true}
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(resolver)
+		if err := g.Pull(localPath, "origin", "main", true); err != nil {
+			t.Fatalf("Pull() returned error: %v", err)
+		}
+		if len(resolver.asked) != 1 || resolver.asked[0].Key != "a" {
+			t.Fatalf("resolver asked %v, want one conflict for key a", resolver.asked)
+		}
+		if got := strings.Join(resolver.asked[0].Paths, ","); got != "a.gpg,a_md.gpg" {
+			t.Errorf("conflict paths = %s, want a.gpg,a_md.gpg", got)
+		}
+		if got := readFile(t, localPath, "a.gpg"); got != "local" {
+			t.Errorf("a.gpg = %q, want the local change kept", got)
+		}
+	})
+
+	t.Run("keep remote", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "local", "a.gpg", "remote")
+
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(&keepResolver{keepLocal: false})
+		if err := g.Pull(localPath, "origin", "main", true); err != nil {
+			t.Fatalf("Pull() returned error: %v", err)
+		}
+		if got := readFile(t, localPath, "a.gpg"); got != "remote" {
+			t.Errorf("a.gpg = %q, want the remote change", got)
+		}
+		count, err := g.UnpushedCommits(localPath, "origin", "main")
+		if err != nil {
+			t.Fatalf("UnpushedCommits() returned error: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("UnpushedCommits() = %d, want the emptied local commit dropped", count)
+		}
+	})
+
+	t.Run("identical changes", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "same", "a.gpg", "same")
+
+		resolver := &keepResolver{}
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(resolver)
+		if err := g.Pull(localPath, "origin", "main", true); err != nil {
+			t.Fatalf("Pull() returned error: %v", err)
+		}
+		if len(resolver.asked) != 0 {
+			t.Errorf("resolver asked %v for identical changes", resolver.asked)
+		}
+	})
+}
+
+func TestPull_RebaseReplaysDeletions(t *testing.T) {
+	localPath, _ := divergedClones(t, "dir/a.gpg", "local", "b.gpg", "remote")
+
+	g := New()
+	if err := os.RemoveAll(filepath.Join(localPath, "dir")); err != nil {
+		t.Fatalf("Failed to remove dir: %v", err)
+	}
+	if err := g.Commit(localPath, "remove dir", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+
+	g.SetSigner(&fakeSigner{})
+	if err := g.Pull(localPath, "origin", "main", true); err != nil {
+		t.Fatalf("Pull() returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "dir")); !os.IsNotExist(err) {
+		t.Errorf("dir still exists after rebasing its removal: %v", err)
+	}
+	if got := readFile(t, localPath, "b.gpg"); got != "remote" {
+		t.Errorf("b.gpg = %q, want the remote change", got)
+	}
+}
+
+func TestPull_RecipientsChangedOnRemote(t *testing.T) {
+	t.Run("without resolver", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "local", ".gpg.id", "FP_AAA\nFP_BBB\n")
+
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		err := g.Pull(localPath, "origin", "main", true)
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("Pull() error = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("drop local", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "local", ".gpg.id", "FP_AAA\nFP_BBB\n")
+
+		resolver := &keepResolver{keepLocal: false}
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(resolver)
+		if err := g.Pull(localPath, "origin", "main", true); err != nil {
+			t.Fatalf("Pull() returned error: %v", err)
+		}
+		if len(resolver.asked) != 1 || resolver.asked[0].Recipients != ".gpg.id" {
+			t.Fatalf("resolver asked %v, want one recipients conflict on .gpg.id", resolver.asked)
+		}
+		if got := strings.Join(resolver.asked[0].Paths, ","); got != "a.gpg" {
+			t.Errorf("conflict paths = %s, want a.gpg", got)
+		}
+		if _, err := os.Stat(filepath.Join(localPath, "a.gpg")); !os.IsNotExist(err) {
+			t.Errorf("a.gpg encrypted for the old recipients was kept: %v", err)
+		}
+		if got := readFile(t, localPath, ".gpg.id"); got != "FP_AAA\nFP_BBB\n" {
+			t.Errorf(".gpg.id = %q, want the remote recipients", got)
+		}
+	})
+
+	t.Run("keep local stops the pull", func(t *testing.T) {
+		localPath, local := divergedClones(t, "a.gpg", "local", ".gpg.id", "FP_AAA\nFP_BBB\n")
+
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(&keepResolver{keepLocal: true})
+		err := g.Pull(localPath, "origin", "main", true)
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("Pull() error = %v, want ErrConflict", err)
+		}
+
+		repo, err := gogit.PlainOpen(localPath)
+		if err != nil {
+			t.Fatalf("Failed to open repo: %v", err)
+		}
+		head, err := repo.Head()
+		if err != nil {
+			t.Fatalf("Failed to get HEAD: %v", err)
+		}
+		if head.Hash().String() != local {
+			t.Errorf("HEAD = %s, want local commit %s kept", head.Hash(), local)
+		}
+	})
+
+	t.Run("other directory", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "a.gpg", "local", "dir/.gpg.id", "FP_BBB\n")
+
+		resolver := &keepResolver{}
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(resolver)
+		if err := g.Pull(localPath, "origin", "main", true); err != nil {
+			t.Fatalf("Pull() returned error: %v", err)
+		}
+		if len(resolver.asked) != 0 {
+			t.Errorf("resolver asked %v for a file outside the changed directory", resolver.asked)
+		}
+	})
+
+	t.Run("folder without recipients", func(t *testing.T) {
+		localPath, _ := divergedClones(t, "rejections/r.json.gpg", "local", ".gpg.id", "FP_AAA\nFP_BBB\n")
+
+		resolver := &keepResolver{}
+		g := New()
+		g.SetSigner(&fakeSigner{})
+		g.SetConflictResolver(resolver)
+		if err := g.Pull(localPath, "origin", "main", true); err != nil {
+			t.Fatalf("Pull() returned error: %v", err)
+		}
+		if len(resolver.asked) != 0 {
+			t.Errorf("resolver asked %v for a file in a folder without .gpg.id", resolver.asked)
+		}
+	})
+}
@@ -27,4 +27,8 @@ type Client interface {
 	CheckRepoExists(name string) (bool, error)
 	CreateRepo(name string) error
 	GetCloneURL(name string) (string, error)
+	CreatePullRequest(repo, head, base, title, body string) (*PullRequest, error)
+	ListPullRequests(repo, head string) ([]PullRequest, error)
+	MergePullRequest(repo string, number int, message string) error
+	ClosePullRequest(repo string, number int) error
 }
@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package github
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v67/github"
+)
+
+type PullRequest struct {
+	Number int
+	Title  string
+	Head   string
+	Base   string
+	State  string
+	URL    string
+}
+
+func toPullRequest(pr *github.PullRequest) PullRequest {
+	return PullRequest{
+		Number: pr.GetNumber(),
+		Title:  pr.GetTitle(),
+		Head:   pr.GetHead().GetRef(),
+		Base:   pr.GetBase().GetRef(),
+		State:  pr.GetState(),
+		URL:    pr.GetHTMLURL(),
+	}
+}
+
+// CreatePullRequest opens a pull request in repo (owner/name) from head into base.
+func (c *GitHubClient) CreatePullRequest(repo, head, base, title, body string) (*PullRequest, error) {
+	slog.Debug("creating pull request", "repo", repo, "head", head, "base", base)
+
+	pr, _, err := c.client.PullRequests.Create(c.ctx, ExtractRepoOwner(repo), ExtractRepoName(repo), &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		slog.Error("failed to create pull request", "error", err)
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	created := toPullRequest(pr)
+	slog.Debug("pull request created", "number", created.Number, "url", created.URL)
+	return &created, nil
+}
+
+// ListPullRequests returns the open pull requests in repo whose head is the
+// given branch, or all open pull requests when head is empty.
+func (c *GitHubClient) ListPullRequests(repo, head string) ([]PullRequest, error) {
+	slog.Debug("listing pull requests", "repo", repo, "head", head)
+
+	owner := ExtractRepoOwner(repo)
+	opts := &github.PullRequestListOptions{State: "open"}
+	if head != "" {
+		opts.Head = owner + ":" + head
+	}
+
+	prs, _, err := c.client.PullRequests.List(c.ctx, owner, ExtractRepoName(repo), opts)
+	if err != nil {
+		slog.Error("failed to list pull requests", "error", err)
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	result := make([]PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		result = append(result, toPullRequest(pr))
+	}
+	return result, nil
+}
+
+func (c *GitHubClient) MergePullRequest(repo string, number int, message string) error {
+	slog.Debug("merging pull request", "repo", repo, "number", number)
+
+	_, _, err := c.client.PullRequests.Merge(c.ctx, ExtractRepoOwner(repo), ExtractRepoName(repo), number, message, nil)
+	if err != nil {
+		slog.Error("failed to merge pull request", "error", err)
+		return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+func (c *GitHubClient) ClosePullRequest(repo string, number int) error {
+	slog.Debug("closing pull request", "repo", repo, "number", number)
+
+	_, _, err := c.client.PullRequests.Edit(c.ctx, ExtractRepoOwner(repo), ExtractRepoName(repo), number, &github.PullRequest{
+		State: github.String("closed"),
+	})
+	if err != nil {
+		slog.Error("failed to close pull request", "error", err)
+		return fmt.Errorf("failed to close pull request #%d: %w", number, err)
+	}
+	return nil
+}
@@ -47,9 +47,11 @@ func (g *GPG) Encrypt(data []byte, recipients ...string) ([]byte, error) {
 	return stdout, nil
 }
 
-func (g *GPG) Decrypt(data []byte) ([]byte, error) {
-	slog.Debug("decrypting data", "size", len(data))
-
+// loopbackPassphrase returns what to answer the key's PIN or passphrase
+// prompt with, when it can be answered without asking: in CI, for headless
+// keys, which have no passphrase, and when the YubiKey user PIN is stored.
+// Otherwise gpg-agent asks through pinentry.
+func loopbackPassphrase() (string, bool) {
 	userPin := config.GetYubikeyUserPin()
 	ciMode := os.Getenv("KEPR_CI") == "true"
 
@@ -57,9 +59,16 @@ func (g *GPG) Decrypt(data []byte) ([]byte, error) {
 		userPin = ""
 	}
 
-	headless := config.GetHeadless()
+	if ciMode || config.GetHeadless() || (userPin != "" && userPin != "manual") {
+		return userPin, true
+	}
+	return "", false
+}
+
+func (g *GPG) Decrypt(data []byte) ([]byte, error) {
+	slog.Debug("decrypting data", "size", len(data))
 
-	if ciMode || headless || (userPin != "" && userPin != "manual") {
+	if userPin, ok := loopbackPassphrase(); ok {
 		slog.Debug("using automated decryption with loopback pinentry")
 		args := []string{
 			"--decrypt",
@@ -188,6 +188,7 @@ func TestGenerateKeys_Success(t *testing.T) {
 	mockExec.AddResponse("/usr/bin/gpg", []string{"--list-keys", "--with-colons"},
 		"fpr:::::::::ABCD1234ABCD1234ABCD1234ABCD1234ABCD1234:\nuid:-::::::::Test User <test@example.com>:\n", "", nil)
 	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", "ABCD1234ABCD1234ABCD1234ABCD1234ABCD1234", "cv25519", "encr", "0"}, "", "", nil)
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", "ABCD1234ABCD1234ABCD1234ABCD1234ABCD1234", "ed25519", "sign", "0"}, "", "", nil)
 
 	gpg := &GPG{
 		BinaryPath:   "/usr/bin/gpg",
@@ -207,8 +208,8 @@ func TestGenerateKeys_Success(t *testing.T) {
 		t.Errorf("expected fingerprint %q, got %q", expected, fingerprint)
 	}
 
-	if len(mockExec.Calls) != 3 {
-		t.Errorf("expected 3 calls, got %d", len(mockExec.Calls))
+	if len(mockExec.Calls) != 4 {
+		t.Errorf("expected 4 calls, got %d", len(mockExec.Calls))
 	}
 
 	if !mockExec.WasCalled("/usr/bin/gpg", "--batch", "--gen-key") {
@@ -304,6 +305,7 @@ func TestGenerateKeys_WithSpecialCharactersInName(t *testing.T) {
 	mockExec.AddResponse("/usr/bin/gpg", []string{"--list-keys", "--with-colons"},
 		"fpr:::::::::SPECIALCHARSFINGERPRINT12345678901234:\nuid:-::::::::Test User (Comment) <test+tag@example.com>:\n", "", nil)
 	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", "SPECIALCHARSFINGERPRINT12345678901234", "cv25519", "encr", "0"}, "", "", nil)
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", "SPECIALCHARSFINGERPRINT12345678901234", "ed25519", "sign", "0"}, "", "", nil)
 
 	gpg := &GPG{
 		BinaryPath: "/usr/bin/gpg",
@@ -322,8 +324,8 @@ func TestGenerateKeys_WithSpecialCharactersInName(t *testing.T) {
 		t.Errorf("expected fingerprint %q, got %q", expected, fingerprint)
 	}
 
-	if len(mockExec.Calls) != 3 {
-		t.Errorf("expected 3 calls, got %d", len(mockExec.Calls))
+	if len(mockExec.Calls) != 4 {
+		t.Errorf("expected 4 calls, got %d", len(mockExec.Calls))
 	}
 
 	firstCall := mockExec.Calls[0]
@@ -470,6 +472,44 @@ func TestListPublicKeys_Error(t *testing.T) {
 	}
 }
 
+func TestReadKeyIdentity_Success(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--show-keys", "--with-colons"},
+		"sec:u:255:22:ABCDEF:1700000000:::u:::scESC:::+:::ed25519:::0:\nfpr:::::::::FINGERPRINT1234567890123456789012345678:\nuid:u::::1700000000::HASH::Test User <test@example.com>::::::::::0:\n", "", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	name, email, err := gpg.ReadKeyIdentity([]byte("key data"))
+	if err != nil {
+		t.Fatalf("ReadKeyIdentity() failed: %v", err)
+	}
+	if name != "Test User" || email != "test@example.com" {
+		t.Errorf("ReadKeyIdentity() = %q, %q, want Test User, test@example.com", name, email)
+	}
+}
+
+func TestReadKeyIdentity_NoEmail(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--show-keys", "--with-colons"},
+		"fpr:::::::::FINGERPRINT1234567890123456789012345678:\nuid:u::::1700000000::HASH::Test User::::::::::0:\n", "", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	if _, _, err := gpg.ReadKeyIdentity([]byte("key data")); err == nil {
+		t.Error("ReadKeyIdentity() should fail when no user ID has an email")
+	}
+}
+
 func TestBackupMasterKey_Cancelled(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -766,3 +806,357 @@ func TestMockExecutor_LookPathError(t *testing.T) {
 		t.Error("expected LookPath() to fail")
 	}
 }
+
+func TestReadKeyFingerprint_Success(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--show-keys", "--with-colons"},
+		"pub:u:255:22:77ED18083565E063:1792322555:::u:::cEC:::::ed25519:::0:\n"+
+			"fpr:::::::::553D727D2BC9F896DC405B0477ED18083565E063:\n"+
+			"uid:u::::1792322555::1E3E697F5E04918CF4ABB800BC7E7AEB9D7D1E61::Test User <test@example.com>::::::::::0:\n"+
+			"sub:u:255:18:E444154F1607F657:1792322555::::::e:::::cv25519::\n"+
+			"fpr:::::::::45413717F8C8E62B84E83E07E444154F1607F657:\n",
+		"", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    tempDir,
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	fp, err := gpg.ReadKeyFingerprint([]byte("key data"))
+	if err != nil {
+		t.Fatalf("ReadKeyFingerprint() failed: %v", err)
+	}
+
+	if fp != "553D727D2BC9F896DC405B0477ED18083565E063" {
+		t.Errorf("expected primary fingerprint, got %s", fp)
+	}
+}
+
+func TestReadKeyFingerprint_NoKey(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--show-keys", "--with-colons"}, "", "", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    tempDir,
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	if _, err := gpg.ReadKeyFingerprint([]byte("not a key")); err == nil {
+		t.Fatal("expected ReadKeyFingerprint() to fail")
+	}
+}
+
+func TestHasSigningKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{
+			name: "encryption subkey only",
+			output: "sec:u:255:22:F034FC55382E672F:1792323266:::u:::cEC:::+::ed25519:::0:\n" +
+				"ssb:u:255:18:87E78172A25047CD:1792323267::::::e:::+::cv25519::\n",
+			want: false,
+		},
+		{
+			name: "signing subkey",
+			output: "sec:u:255:22:F034FC55382E672F:1792323266:::u:::cESC:::+::ed25519:::0:\n" +
+				"ssb:u:255:18:87E78172A25047CD:1792323267::::::e:::+::cv25519::\n" +
+				"ssb:u:255:22:3EAF94AE017D61D1:1792323267::::::s:::+::ed25519::\n",
+			want: true,
+		},
+		{
+			name:   "signing subkey not available",
+			output: "ssb:u:255:22:3EAF94AE017D61D1:1792323267::::::s:::#::ed25519::\n",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := NewMockExecutor()
+			mockExec.AddResponse("/usr/bin/gpg", []string{"--list-secret-keys", "--with-colons", "FP"}, tt.output, "", nil)
+
+			gpg := &GPG{
+				BinaryPath: "/usr/bin/gpg",
+				HomeDir:    t.TempDir(),
+				executor:   mockExec,
+				io:         NewMockIO(),
+			}
+
+			got, err := gpg.HasSigningKey("FP")
+			if err != nil {
+				t.Fatalf("HasSigningKey() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasSigningKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasEncryptionKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{
+			name: "encryption subkey",
+			output: "pub:u:255:22:F034FC55382E672F:1792323266:::u:::cESC::::::ed25519:::0:\n" +
+				"sub:u:255:18:87E78172A25047CD:1792323267::::::e::::::cv25519::\n",
+			want: true,
+		},
+		{
+			name: "signing only",
+			output: "pub:u:255:22:F034FC55382E672F:1792323266:::u:::scSC::::::ed25519:::0:\n" +
+				"sub:u:255:22:3EAF94AE017D61D1:1792323267::::::s::::::ed25519::\n",
+			want: false,
+		},
+		{
+			name: "encryption subkey expired",
+			output: "pub:u:255:22:F034FC55382E672F:1792323266:::u:::cSC::::::ed25519:::0:\n" +
+				"sub:e:255:18:87E78172A25047CD:1792323267::::::e::::::cv25519::\n",
+			want: false,
+		},
+		{
+			name: "encryption subkey revoked",
+			output: "pub:u:255:22:F034FC55382E672F:1792323266:::u:::cSC::::::ed25519:::0:\n" +
+				"sub:r:255:18:87E78172A25047CD:1792323267::::::e::::::cv25519::\n",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := NewMockExecutor()
+			mockExec.AddResponse("/usr/bin/gpg", []string{"--list-keys", "--with-colons", "FP"}, tt.output, "", nil)
+
+			gpg := &GPG{
+				BinaryPath: "/usr/bin/gpg",
+				HomeDir:    t.TempDir(),
+				executor:   mockExec,
+				io:         NewMockIO(),
+			}
+
+			got, err := gpg.HasEncryptionKey("FP")
+			if err != nil {
+				t.Fatalf("HasEncryptionKey() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasEncryptionKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasEncryptionKey_NotFound(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--list-keys", "--with-colons", "FP"},
+		"", "gpg: error reading key: No public key", fmt.Errorf("exit status 2"))
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	if _, err := gpg.HasEncryptionKey("FP"); err == nil {
+		t.Error("expected HasEncryptionKey() to fail for a missing key")
+	}
+}
+
+func TestGenerateKeys_SigningSubkeyFails(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--gen-key"}, "", "", nil)
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--list-keys", "--with-colons"},
+		"fpr:::::::::TESTFINGERPRINT123456789012345678:\nuid:-::::::::Test User <test@example.com>:\n", "", nil)
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", "TESTFINGERPRINT123456789012345678", "cv25519", "encr", "0"}, "", "", nil)
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", "TESTFINGERPRINT123456789012345678", "ed25519", "sign", "0"},
+		"", "gpg: error adding subkey", fmt.Errorf("exit status 2"))
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	_, err := gpg.GenerateKeys("Test User", "test@example.com")
+	if err == nil || !strings.Contains(err.Error(), "failed to generate signing subkey") {
+		t.Errorf("expected signing subkey failure, got: %v", err)
+	}
+}
+
+func TestRequireSigningKey_Missing(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--list-secret-keys", "--with-colons", "FP"},
+		"sec:u:255:22:F034FC55382E672F:1792323266:::u:::cEC:::+::ed25519:::0:\n"+
+			"ssb:u:255:18:87E78172A25047CD:1792323267::::::e:::+::cv25519::\n", "", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	err := gpg.RequireSigningKey("FP")
+	if err == nil {
+		t.Fatal("expected RequireSigningKey() to fail without a signing subkey")
+	}
+	if !strings.Contains(err.Error(), "--quick-add-key FP ed25519 sign") {
+		t.Errorf("expected instructions to add a signing subkey, got: %v", err)
+	}
+	if len(mockExec.Calls) != 1 {
+		t.Errorf("RequireSigningKey() must not change the key, got %d gpg calls", len(mockExec.Calls))
+	}
+}
+
+func TestRequireSigningKey_OnCard(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--list-secret-keys", "--with-colons", "FP"},
+		"sec:u:255:22:F034FC55382E672F:1792323266:::u:::cESC:::#::ed25519:::0:\n"+
+			"ssb:u:255:22:3EAF94AE017D61D1:1792323267::::::s:::D2760001240100000006123456780000::ed25519::\n", "", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	if err := gpg.RequireSigningKey("FP"); err != nil {
+		t.Errorf("RequireSigningKey() with the signing key on a card failed: %v", err)
+	}
+}
+
+func TestSign_Headless(t *testing.T) {
+	t.Setenv("KEPR_CI", "true")
+
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--no-tty", "--pinentry-mode", "loopback", "--passphrase", "", "--detach-sign", "--armor", "--local-user", "FP"},
+		"-----BEGIN PGP SIGNATURE-----\n", "", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	sig, err := gpg.DetachSign([]byte("commit"), "FP")
+	if err != nil {
+		t.Fatalf("DetachSign() failed: %v", err)
+	}
+	if !strings.Contains(string(sig), "PGP SIGNATURE") {
+		t.Errorf("DetachSign() = %q, want a signature", sig)
+	}
+}
+
+func TestVerify_Success(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--no-tty", "--status-fd", "2", "--decrypt"},
+		"content\n",
+		"[GNUPG:] GOODSIG 3EAF94AE017D61D1 Test M <m@x.com>\n"+
+			"[GNUPG:] VALIDSIG C2CB43FEBFFE5A340D9258143EAF94AE017D61D1 2026-10-18 1792323267 0 4 0 22 8 01 27B3F5380CCEE76BEEB48B5BF034FC55382E672F\n",
+		nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	content, signer, err := gpg.Verify([]byte("signed"))
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if string(content) != "content\n" {
+		t.Errorf("Verify() content = %q", content)
+	}
+	if signer != "27B3F5380CCEE76BEEB48B5BF034FC55382E672F" {
+		t.Errorf("Verify() signer = %s, want primary fingerprint", signer)
+	}
+}
+
+func TestVerify_NoSignature(t *testing.T) {
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--no-tty", "--status-fd", "2", "--decrypt"},
+		"content\n", "[GNUPG:] PLAINTEXT 74 0\n", nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    t.TempDir(),
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	if _, _, err := gpg.Verify([]byte("unsigned")); err == nil {
+		t.Fatal("expected Verify() to fail without a valid signature")
+	}
+}
+
+func TestVerifyDetached_Success(t *testing.T) {
+	homeDir := t.TempDir()
+	mockExec := NewMockExecutor()
+	mockExec.AddResponse("/usr/bin/gpg", []string{"--batch", "--no-tty", "--status-fd", "2", "--verify", filepath.Join(homeDir, "verify.sig"), "-"},
+		"",
+		"[GNUPG:] GOODSIG 3EAF94AE017D61D1 Test M <m@x.com>\n"+
+			"[GNUPG:] VALIDSIG C2CB43FEBFFE5A340D9258143EAF94AE017D61D1 2026-10-18 1792323267 0 4 0 22 8 00 27B3F5380CCEE76BEEB48B5BF034FC55382E672F\n",
+		nil)
+
+	gpg := &GPG{
+		BinaryPath: "/usr/bin/gpg",
+		HomeDir:    homeDir,
+		executor:   mockExec,
+		io:         NewMockIO(),
+	}
+
+	signer, err := gpg.VerifyDetached([]byte("commit payload"), []byte("signature"))
+	if err != nil {
+		t.Fatalf("VerifyDetached() failed: %v", err)
+	}
+	if signer != "27B3F5380CCEE76BEEB48B5BF034FC55382E672F" {
+		t.Errorf("VerifyDetached() signer = %s, want primary fingerprint", signer)
+	}
+	if _, err := os.Stat(filepath.Join(homeDir, "verify.sig")); !os.IsNotExist(err) {
+		t.Error("expected the signature file to be removed")
+	}
+}
+
+func TestParseValidSig_BadSig(t *testing.T) {
+	status := "[GNUPG:] BADSIG 3EAF94AE017D61D1 Test\n" +
+		"[GNUPG:] VALIDSIG C2CB 2026-10-18 1792323267 0 4 0 22 8 01 27B3F5380CCEE76BEEB48B5BF034FC55382E672F\n"
+	if got := parseValidSig(status); got != "" {
+		t.Errorf("parseValidSig() = %q, want empty on BADSIG", got)
+	}
+}
+
+func TestClearsignedText(t *testing.T) {
+	signed := "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\n{\n  \"a\": \"b\"\n}\n- -dashed\n-----BEGIN PGP SIGNATURE-----\n\nabc\n-----END PGP SIGNATURE-----\n"
+
+	got, err := ClearsignedText([]byte(signed))
+	if err != nil {
+		t.Fatalf("ClearsignedText() failed: %v", err)
+	}
+
+	want := "{\n  \"a\": \"b\"\n}\n-dashed"
+	if string(got) != want {
+		t.Errorf("ClearsignedText() = %q, want %q", got, want)
+	}
+
+	if _, err := ClearsignedText([]byte("plain text")); err == nil {
+		t.Error("expected error for a message that is not clearsigned")
+	}
+}
@@ -67,7 +67,14 @@ Expire-Date: 0
 		return "", fmt.Errorf("failed to generate encryption subkey: %w, stderr: %s", err, stderr)
 	}
 
-	slog.Debug("encryption subkey generated")
+	slog.Debug("encryption subkey generated, adding signing subkey")
+
+	_, stderr, err = g.execute("", "--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-add-key", fingerprint, "ed25519", "sign", "0")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signing subkey: %w, stderr: %s", err, stderr)
+	}
+
+	slog.Debug("signing subkey generated")
 	return fingerprint, nil
 }
 
@@ -213,15 +220,7 @@ func (g *GPG) ListPublicKeys() ([]GPGKey, error) {
 					Fingerprint: currentFingerprint,
 					UserID:      uid,
 				}
-
-				if strings.Contains(uid, "<") && strings.Contains(uid, ">") {
-					emailStart := strings.Index(uid, "<")
-					emailEnd := strings.Index(uid, ">")
-					if emailStart < emailEnd {
-						key.Email = uid[emailStart+1 : emailEnd]
-						key.Name = strings.TrimSpace(uid[:emailStart])
-					}
-				}
+				key.Name, key.Email = parseUserID(uid)
 
 				keys = append(keys, key)
 			}
@@ -231,3 +230,95 @@ func (g *GPG) ListPublicKeys() ([]GPGKey, error) {
 	slog.Debug("found keys", "count", len(keys))
 	return keys, nil
 }
+
+// parseUserID splits a "Name <email>" user ID. Both parts are empty when it
+// has no email.
+func parseUserID(uid string) (name, email string) {
+	emailStart := strings.Index(uid, "<")
+	emailEnd := strings.Index(uid, ">")
+	if emailStart < 0 || emailStart > emailEnd {
+		return "", ""
+	}
+	return strings.TrimSpace(uid[:emailStart]), uid[emailStart+1 : emailEnd]
+}
+
+// ReadKeyIdentity returns the name and email of the first user ID in
+// keyData, without importing it.
+func (g *GPG) ReadKeyIdentity(keyData []byte) (string, string, error) {
+	slog.Debug("reading key identity")
+
+	stdout, stderr, err := g.execute(string(keyData), "--show-keys", "--with-colons")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read key: %w, stderr: %s", err, stderr)
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		if !strings.HasPrefix(line, "uid:") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 10 {
+			continue
+		}
+		if name, email := parseUserID(fields[9]); email != "" {
+			return name, email, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no user ID with an email found in key data")
+}
+
+func (g *GPG) ReadKeyFingerprint(keyData []byte) (string, error) {
+	slog.Debug("reading key fingerprint")
+
+	stdout, stderr, err := g.execute(string(keyData), "--show-keys", "--with-colons")
+	if err != nil {
+		return "", fmt.Errorf("failed to read key: %w, stderr: %s", err, stderr)
+	}
+
+	expectPrimary := false
+	for _, line := range strings.Split(stdout, "\n") {
+		if strings.HasPrefix(line, "pub:") {
+			expectPrimary = true
+			continue
+		}
+		if expectPrimary && strings.HasPrefix(line, "fpr:") {
+			fields := strings.Split(line, ":")
+			if len(fields) >= 10 && fields[9] != "" {
+				return fields[9], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no public key found in key data")
+}
+
+// HasEncryptionKey reports whether fingerprint is in the keyring with a
+// usable encryption key, skipping revoked, expired and disabled ones.
+func (g *GPG) HasEncryptionKey(fingerprint string) (bool, error) {
+	slog.Debug("checking for encryption key", "fingerprint", fingerprint)
+
+	stdout, stderr, err := g.execute("", "--list-keys", "--with-colons", fingerprint)
+	if err != nil {
+		return false, fmt.Errorf("failed to list key %s: %w, stderr: %s", fingerprint, err, stderr)
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		if !strings.HasPrefix(line, "pub:") && !strings.HasPrefix(line, "sub:") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 12 {
+			continue
+		}
+		switch fields[1] {
+		case "r", "e", "i", "d":
+			continue
+		}
+		if strings.Contains(fields[11], "e") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Signing uses the key's own signing subkey, which lives on the YubiKey
+// once provisioned. kepr never creates one behind the user's back; PINs and
+// passphrases are answered the same way as for decryption.
+
+func (g *GPG) HasSigningKey(fingerprint string) (bool, error) {
+	slog.Debug("checking for signing key", "fingerprint", fingerprint)
+
+	stdout, stderr, err := g.execute("", "--list-secret-keys", "--with-colons", fingerprint)
+	if err != nil {
+		return false, fmt.Errorf("failed to list secret keys: %w, stderr: %s", err, stderr)
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		if !strings.HasPrefix(line, "sec:") && !strings.HasPrefix(line, "ssb:") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 15 {
+			continue
+		}
+		if fields[1] == "r" || fields[1] == "e" {
+			continue
+		}
+		if strings.Contains(fields[11], "s") && fields[14] != "#" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RequireSigningKey fails with instructions when fingerprint has no usable
+// signing subkey, such as keys created before kepr signed anything.
+func (g *GPG) RequireSigningKey(fingerprint string) error {
+	ok, err := g.HasSigningKey(fingerprint)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("key %s has no usable signing subkey; add one with `GNUPGHOME=%s gpg --quick-add-key %s ed25519 sign` and move it to your YubiKey with `gpg --edit-key %s` (keytocard)", fingerprint, g.HomeDir, fingerprint, fingerprint)
+	}
+	return nil
+}
+
+func (g *GPG) Sign(data []byte, fingerprint string) ([]byte, error) {
+	slog.Debug("signing data", "fingerprint", fingerprint, "size", len(data))
+
+	stdout, err := g.sign(data, "--clearsign", "--local-user", fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return stdout, nil
+}
+
+// DetachSign returns an armored detached signature over data.
+func (g *GPG) DetachSign(data []byte, fingerprint string) ([]byte, error) {
+	slog.Debug("creating detached signature", "fingerprint", fingerprint, "size", len(data))
+
+	stdout, err := g.sign(data, "--detach-sign", "--armor", "--local-user", fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return stdout, nil
+}
+
+// sign runs gpg with args over data. When the PIN cannot be answered
+// without asking, gpg-agent prompts through pinentry on the terminal, and
+// the data is passed on fd 3 so stdin stays free for it.
+func (g *GPG) sign(data []byte, args ...string) ([]byte, error) {
+	if passphrase, ok := loopbackPassphrase(); ok {
+		args = append([]string{"--batch", "--no-tty", "--pinentry-mode", "loopback", "--passphrase", passphrase}, args...)
+		stdout, stderr, err := g.executeBytes(data, args...)
+		if err != nil {
+			slog.Debug("signing failed", "error", err, "stderr", stderr)
+			return nil, fmt.Errorf("failed to sign data: %w", err)
+		}
+		return stdout, nil
+	}
+
+	args = append([]string{"--enable-special-filenames", "--output", "-"}, args...)
+	stdout, stderr, err := g.executeBytesWithPinentry(data, append(args, "-&3")...)
+	if err != nil {
+		slog.Debug("signing failed", "error", err, "stderr", stderr)
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+	return stdout, nil
+}
+
+// CommitSigner signs git commits with a key's signing subkey.
+type CommitSigner struct {
+	gpg         *GPG
+	fingerprint string
+}
+
+func (g *GPG) CommitSigner(fingerprint string) *CommitSigner {
+	return &CommitSigner{gpg: g, fingerprint: fingerprint}
+}
+
+func (s *CommitSigner) Sign(message io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+	return s.gpg.DetachSign(data, s.fingerprint)
+}
+
+// Verify checks a clearsigned message and returns its content together with
+// the primary key fingerprint of the signer.
+func (g *GPG) Verify(signed []byte) ([]byte, string, error) {
+	slog.Debug("verifying signature", "size", len(signed))
+
+	stdout, stderr, err := g.executeBytes(signed,
+		"--batch",
+		"--no-tty",
+		"--status-fd", "2",
+		"--decrypt")
+	if err != nil {
+		slog.Debug("verification failed", "error", err, "stderr", stderr)
+		return nil, "", fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	signer := parseValidSig(stderr)
+	if signer == "" {
+		return nil, "", fmt.Errorf("no valid signature found")
+	}
+
+	return stdout, signer, nil
+}
+
+// VerifyDetached checks an armored detached signature over data and returns
+// the primary key fingerprint of the signer.
+func (g *GPG) VerifyDetached(data, signature []byte) (string, error) {
+	slog.Debug("verifying detached signature", "size", len(data))
+
+	sigPath := filepath.Join(g.HomeDir, "verify.sig")
+	if err := os.WriteFile(sigPath, signature, 0600); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	defer os.Remove(sigPath)
+
+	_, stderr, err := g.executeBytes(data,
+		"--batch",
+		"--no-tty",
+		"--status-fd", "2",
+		"--verify", sigPath, "-")
+	if err != nil {
+		slog.Debug("verification failed", "error", err, "stderr", stderr)
+		return "", fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	signer := parseValidSig(stderr)
+	if signer == "" {
+		return "", fmt.Errorf("no valid signature found")
+	}
+	return signer, nil
+}
+
+func parseValidSig(status string) string {
+	goodSig := false
+	signer := ""
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "GOODSIG":
+			goodSig = true
+		case "VALIDSIG":
+			if len(fields) >= 12 {
+				signer = fields[11]
+			}
+		case "BADSIG", "ERRSIG", "EXPKEYSIG", "REVKEYSIG":
+			return ""
+		}
+	}
+	if !goodSig {
+		return ""
+	}
+	return signer
+}
+
+// ClearsignedText extracts the message from a clearsigned block without
+// verifying it, for example to read an embedded key before verification.
+func ClearsignedText(signed []byte) ([]byte, error) {
+	const header = "-----BEGIN PGP SIGNED MESSAGE-----"
+	const sigHeader = "-----BEGIN PGP SIGNATURE-----"
+
+	text := strings.ReplaceAll(string(signed), "\r\n", "\n")
+	start := strings.Index(text, header)
+	if start < 0 {
+		return nil, fmt.Errorf("not a clearsigned message")
+	}
+	rest := text[start+len(header):]
+
+	bodyStart := strings.Index(rest, "\n\n")
+	if bodyStart < 0 {
+		return nil, fmt.Errorf("malformed clearsigned message")
+	}
+	rest = rest[bodyStart+2:]
+
+	end := strings.Index(rest, "\n"+sigHeader)
+	if end < 0 {
+		return nil, fmt.Errorf("malformed clearsigned message")
+	}
+
+	var body bytes.Buffer
+	for i, line := range strings.Split(rest[:end], "\n") {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString(strings.TrimPrefix(line, "- "))
+	}
+	return body.Bytes(), nil
+}
@@ -149,6 +149,10 @@ func (y *Yubikey) Init(name, email, fingerprint string) error {
 		return fmt.Errorf("failed to move encryption key to yubikey: %w", err)
 	}
 
+	if err := y.signingKeyToYubikey(fingerprint); err != nil {
+		return fmt.Errorf("failed to move signing key to yubikey: %w", err)
+	}
+
 	slog.Debug("yubikey initialized successfully")
 	return nil
 }
@@ -389,15 +393,24 @@ func (y *Yubikey) configureCard(name, email string) error {
 
 func (y *Yubikey) encryptionKeyToYubikey(fingerprint string) error {
 	slog.Debug("moving encryption key to yubikey", "fingerprint", fingerprint)
+	return y.keyToYubikey(fingerprint, []string{"key 1", "keytocard", "2", "save"})
+}
+
+// signingKeyToYubikey moves the signing subkey, the second one GenerateKeys
+// creates, into the card's signature slot.
+func (y *Yubikey) signingKeyToYubikey(fingerprint string) error {
+	slog.Debug("moving signing key to yubikey", "fingerprint", fingerprint)
+	return y.keyToYubikey(fingerprint, []string{"key 2", "keytocard", "1", "save"})
+}
 
+func (y *Yubikey) keyToYubikey(fingerprint string, commands []string) error {
 	adminPin := config.GetYubikeyAdminPin()
 	baseArgs := []string{"--edit-key", fingerprint}
-	commands := []string{"key 1", "keytocard", "2", "save"}
 
 	if adminPin != "manual" {
 		err := y.automatedYubikey(baseArgs, commands, PinTypeAdmin)
 		if err == nil {
-			slog.Debug("encryption key moved to yubikey successfully")
+			slog.Debug("key moved to yubikey successfully")
 			return nil
 		}
 		if errors.Is(err, ErrBadPIN) && adminPin == "" {
@@ -405,16 +418,15 @@ func (y *Yubikey) encryptionKeyToYubikey(fingerprint string) error {
 			y.AdminPin = "manual"
 			config.SaveYubikeyAdminPin("manual")
 		} else if adminPin != "" {
-			return fmt.Errorf("failed to move encryption key to yubikey: %w", err)
+			return err
 		}
 	}
 
-	err := y.manualYubikey(baseArgs, commands)
-	if err != nil {
-		return fmt.Errorf("failed to move encryption key to yubikey: %w", err)
+	if err := y.manualYubikey(baseArgs, commands); err != nil {
+		return err
 	}
 
-	slog.Debug("encryption key moved to yubikey successfully")
+	slog.Debug("key moved to yubikey successfully")
 	return nil
 }
 
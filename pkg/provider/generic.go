@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package provider
+
+import (
+	"os/user"
+
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+)
+
+// Generic is any git remote kepr can push to, such as a bare repository
+// over SSH or on a shared filesystem. It has no API: the repository must
+// already exist, and the user's identity has to be entered by hand.
+type Generic struct {
+	url string
+}
+
+func NewGeneric(url string) *Generic {
+	return &Generic{url: url}
+}
+
+func (g *Generic) SetToken(string) {}
+
+func (g *Generic) GetUserIdentity() (string, string, error) {
+	return "", "", ErrUnsupported
+}
+
+// GetCurrentUserLogin returns the local user name, which only labels
+// requests since there is no account behind a generic remote.
+func (g *Generic) GetCurrentUserLogin() (string, error) {
+	return localLogin()
+}
+
+func localLogin() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+// CheckRepoExists reports whether the remote already holds commits, since
+// an empty bare repository is what CreateRepo would have made.
+func (g *Generic) CheckRepoExists(string) (bool, error) {
+	return git.New().RemoteHasCommits(g.url)
+}
+
+// CreateRepo does nothing: the remote must be created by whoever hosts it,
+// and an empty one is filled by the first push.
+func (g *Generic) CreateRepo(string) error {
+	return nil
+}
+
+func (g *Generic) GetCloneURL(string) (string, error) {
+	return g.url, nil
+}
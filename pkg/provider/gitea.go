@@ -0,0 +1,125 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package provider
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// Gitea keeps stores as private repositories of the user, through the v1
+// REST API and an access token. Forgejo speaks the same API.
+type Gitea struct {
+	api *apiClient
+}
+
+type giteaUser struct {
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+type giteaRepo struct {
+	CloneURL string `json:"clone_url"`
+}
+
+func NewGitea(baseURL string) *Gitea {
+	return &Gitea{api: newAPIClient(baseURL, "/api/v1", func(req *http.Request, token string) {
+		req.Header.Set("Authorization", "token "+token)
+	})}
+}
+
+func (g *Gitea) SetToken(token string) {
+	g.api.token = token
+}
+
+func (g *Gitea) user() (*giteaUser, error) {
+	var user giteaUser
+	if err := g.api.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch current user: %w", err)
+	}
+	return &user, nil
+}
+
+func (g *Gitea) GetUserIdentity() (string, string, error) {
+	user, err := g.user()
+	if err != nil {
+		return "", "", err
+	}
+	if user.Email == "" {
+		return user.FullName, "", fmt.Errorf("no email found in Gitea account")
+	}
+	return user.FullName, user.Email, nil
+}
+
+func (g *Gitea) GetCurrentUserLogin() (string, error) {
+	user, err := g.user()
+	if err != nil {
+		return "", err
+	}
+	if user.Login == "" {
+		return "", fmt.Errorf("user login not found")
+	}
+	return user.Login, nil
+}
+
+func (g *Gitea) repo(name string) (*giteaRepo, error) {
+	login, err := g.GetCurrentUserLogin()
+	if err != nil {
+		return nil, err
+	}
+
+	var repo giteaRepo
+	path := "/repos/" + url.PathEscape(login) + "/" + url.PathEscape(name)
+	if err := g.api.do(http.MethodGet, path, nil, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+func (g *Gitea) CheckRepoExists(name string) (bool, error) {
+	slog.Debug("checking if repository exists", "name", name)
+
+	_, err := g.repo(name)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check repository: %w", err)
+	}
+	return true, nil
+}
+
+func (g *Gitea) CreateRepo(name string) error {
+	slog.Debug("creating repository", "name", name)
+
+	body := map[string]any{"name": name, "private": true}
+	if err := g.api.do(http.MethodPost, "/user/repos", body, nil); err != nil {
+		return fmt.Errorf("failed to create repository: %w", err)
+	}
+	return nil
+}
+
+func (g *Gitea) GetCloneURL(name string) (string, error) {
+	repo, err := g.repo(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	return repo.CloneURL, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package provider
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// GitLab keeps stores as private projects in the user's namespace, through
+// the v4 REST API and a personal access token.
+type GitLab struct {
+	api *apiClient
+}
+
+type gitlabUser struct {
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	CommitEmail string `json:"commit_email"`
+	PublicEmail string `json:"public_email"`
+}
+
+type gitlabProject struct {
+	HTTPURLToRepo string `json:"http_url_to_repo"`
+}
+
+func NewGitLab(baseURL string) *GitLab {
+	return &GitLab{api: newAPIClient(baseURL, "/api/v4", func(req *http.Request, token string) {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	})}
+}
+
+func (g *GitLab) SetToken(token string) {
+	g.api.token = token
+}
+
+func (g *GitLab) user() (*gitlabUser, error) {
+	var user gitlabUser
+	if err := g.api.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch current user: %w", err)
+	}
+	return &user, nil
+}
+
+func (g *GitLab) GetUserIdentity() (string, string, error) {
+	user, err := g.user()
+	if err != nil {
+		return "", "", err
+	}
+
+	email := user.CommitEmail
+	if email == "" {
+		email = user.Email
+	}
+	if email == "" {
+		email = user.PublicEmail
+	}
+	if email == "" {
+		return user.Name, "", fmt.Errorf("no email found in GitLab account")
+	}
+	return user.Name, email, nil
+}
+
+func (g *GitLab) GetCurrentUserLogin() (string, error) {
+	user, err := g.user()
+	if err != nil {
+		return "", err
+	}
+	if user.Username == "" {
+		return "", fmt.Errorf("user login not found")
+	}
+	return user.Username, nil
+}
+
+func (g *GitLab) project(name string) (*gitlabProject, error) {
+	login, err := g.GetCurrentUserLogin()
+	if err != nil {
+		return nil, err
+	}
+
+	var project gitlabProject
+	err = g.api.do(http.MethodGet, "/projects/"+url.PathEscape(login+"/"+name), nil, &project)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (g *GitLab) CheckRepoExists(name string) (bool, error) {
+	slog.Debug("checking if project exists", "name", name)
+
+	_, err := g.project(name)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check project: %w", err)
+	}
+	return true, nil
+}
+
+func (g *GitLab) CreateRepo(name string) error {
+	slog.Debug("creating project", "name", name)
+
+	body := map[string]any{"name": name, "path": name, "visibility": "private"}
+	if err := g.api.do(http.MethodPost, "/projects", body, nil); err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+	return nil
+}
+
+func (g *GitLab) GetCloneURL(name string) (string, error) {
+	project, err := g.project(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+	return project.HTTPURLToRepo, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package provider
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/git"
+)
+
+// Local keeps a store without any hosting: either in a bare repository on
+// this machine or a shared filesystem, or with no remote at all. Nothing
+// about it needs the network, which suits air-gapped machines.
+type Local struct {
+	path string
+}
+
+// NewLocal returns the provider for the bare repository at path, which is
+// a plain path or a file:// URL. An empty path means no remote.
+func NewLocal(path string) *Local {
+	return &Local{path: path}
+}
+
+func (l *Local) SetToken(string) {}
+
+func (l *Local) GetUserIdentity() (string, string, error) {
+	return "", "", ErrUnsupported
+}
+
+func (l *Local) GetCurrentUserLogin() (string, error) {
+	return localLogin()
+}
+
+func (l *Local) dir() string {
+	return strings.TrimPrefix(l.path, "file://")
+}
+
+// CheckRepoExists reports whether the bare repository already holds a
+// store. A missing or empty one is created or filled by init.
+func (l *Local) CheckRepoExists(string) (bool, error) {
+	if l.path == "" {
+		return false, nil
+	}
+	if _, err := os.Stat(l.dir()); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return git.New().RemoteHasCommits(l.path)
+}
+
+// CreateRepo creates the bare repository unless it already exists.
+func (l *Local) CreateRepo(string) error {
+	if l.path == "" {
+		return nil
+	}
+	if _, err := os.Stat(l.dir()); err == nil {
+		return nil
+	}
+	return git.New().InitBare(l.dir())
+}
+
+func (l *Local) GetCloneURL(string) (string, error) {
+	return l.path, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	KindGitHub  = "github"
+	KindGitLab  = "gitlab"
+	KindGitea   = "gitea"
+	KindGeneric = "generic"
+	KindLocal   = "local"
+)
+
+// Kinds lists the providers a store can be created on.
+var Kinds = []string{KindGitHub, KindGitLab, KindGitea, KindGeneric, KindLocal}
+
+const DefaultGitLabURL = "https://gitlab.com"
+
+// ErrUnsupported is returned by providers that have no API for an operation.
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// Provider is the git host a store lives on. github.Client satisfies it, and
+// is still used directly for what only GitHub offers, such as pull requests.
+type Provider interface {
+	SetToken(token string)
+	GetUserIdentity() (name string, email string, err error)
+	GetCurrentUserLogin() (string, error)
+	CheckRepoExists(name string) (bool, error)
+	CreateRepo(name string) error
+	GetCloneURL(name string) (string, error)
+}
+
+func ValidKind(kind string) bool {
+	return slices.Contains(Kinds, kind)
+}
+
+// NeedsToken reports whether stores on kind authenticate with a token.
+// Generic remotes rely on the user's own SSH keys, and local ones on file
+// permissions.
+func NeedsToken(kind string) bool {
+	return kind != KindGeneric && kind != KindLocal
+}
+
+// IsLocal reports whether stores on kind work without any network.
+func IsLocal(kind string) bool {
+	return kind == KindLocal
+}
+
+// New returns the provider for a store outside GitHub. For GitLab and Gitea
+// url is the instance's base URL; for generic remotes it is the remote
+// itself, and for local stores the bare repository, if any.
+func New(kind, url string) (Provider, error) {
+	switch kind {
+	case KindGitLab:
+		if url == "" {
+			url = DefaultGitLabURL
+		}
+		return NewGitLab(url), nil
+	case KindGitea:
+		if url == "" {
+			return nil, fmt.Errorf("gitea needs the URL of the instance")
+		}
+		return NewGitea(url), nil
+	case KindGeneric:
+		if url == "" {
+			return nil, fmt.Errorf("generic git needs the URL of the remote")
+		}
+		return NewGeneric(url), nil
+	case KindLocal:
+		return NewLocal(url), nil
+	}
+	return nil, fmt.Errorf("unknown provider %q", kind)
+}
@@ -0,0 +1,255 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// fakeAPI serves one user and the repositories created through it, checking
+// that every request carries the token in header.
+func fakeAPI(t *testing.T, header, value string, routes map[string]func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(header) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler, ok := routes[r.Method+" "+r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func reply(body any) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(KindGitLab, ""); err != nil {
+		t.Errorf("New(gitlab) without URL returned error: %v", err)
+	}
+	if _, err := New(KindGitea, ""); err == nil {
+		t.Error("New(gitea) without URL should fail")
+	}
+	if _, err := New(KindGeneric, ""); err == nil {
+		t.Error("New(generic) without URL should fail")
+	}
+	if _, err := New("bitbucket", "https://example.com"); err == nil {
+		t.Error("New() should fail for an unknown provider")
+	}
+	if !ValidKind(KindGitHub) || ValidKind("bitbucket") {
+		t.Error("ValidKind() does not match Kinds")
+	}
+	if NeedsToken(KindGeneric) || !NeedsToken(KindGitLab) {
+		t.Error("NeedsToken() should be false only for generic remotes")
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	var created map[string]any
+	server := fakeAPI(t, "PRIVATE-TOKEN", "secret", map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /api/v4/user": reply(map[string]string{
+			"username": "alice", "name": "Alice", "email": "alice@private.example", "commit_email": "alice@example.com",
+		}),
+		"GET /api/v4/projects/alice%2Fkepr-store": reply(map[string]string{
+			"http_url_to_repo": "https://gitlab.example/alice/kepr-store.git",
+		}),
+		"POST /api/v4/projects": func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("{}"))
+		},
+	})
+
+	p, err := New(KindGitLab, server.URL)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if _, err := p.GetCurrentUserLogin(); err == nil {
+		t.Error("GetCurrentUserLogin() should fail without a token")
+	}
+	p.SetToken("secret")
+
+	name, email, err := p.GetUserIdentity()
+	if err != nil {
+		t.Fatalf("GetUserIdentity() returned error: %v", err)
+	}
+	if name != "Alice" || email != "alice@example.com" {
+		t.Errorf("GetUserIdentity() = %q, %q, want the commit email", name, email)
+	}
+
+	exists, err := p.CheckRepoExists("kepr-store")
+	if err != nil || !exists {
+		t.Errorf("CheckRepoExists(kepr-store) = %v, %v, want true", exists, err)
+	}
+	exists, err = p.CheckRepoExists("other")
+	if err != nil || exists {
+		t.Errorf("CheckRepoExists(other) = %v, %v, want false", exists, err)
+	}
+
+	if err := p.CreateRepo("other"); err != nil {
+		t.Fatalf("CreateRepo() returned error: %v", err)
+	}
+	if created["name"] != "other" || created["visibility"] != "private" {
+		t.Errorf("CreateRepo() sent %v, want a private project", created)
+	}
+
+	url, err := p.GetCloneURL("kepr-store")
+	if err != nil {
+		t.Fatalf("GetCloneURL() returned error: %v", err)
+	}
+	if url != "https://gitlab.example/alice/kepr-store.git" {
+		t.Errorf("GetCloneURL() = %q", url)
+	}
+}
+
+func TestGitea(t *testing.T) {
+	var created map[string]any
+	server := fakeAPI(t, "Authorization", "token secret", map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /api/v1/user": reply(map[string]string{
+			"login": "bob", "full_name": "Bob", "email": "bob@example.com",
+		}),
+		"GET /api/v1/repos/bob/kepr-store": reply(map[string]string{
+			"clone_url": "https://gitea.example/bob/kepr-store.git",
+		}),
+		"POST /api/v1/user/repos": func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("{}"))
+		},
+	})
+
+	p, err := New(KindGitea, server.URL+"/")
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	p.SetToken("secret")
+
+	login, err := p.GetCurrentUserLogin()
+	if err != nil || login != "bob" {
+		t.Errorf("GetCurrentUserLogin() = %q, %v, want bob", login, err)
+	}
+	name, email, err := p.GetUserIdentity()
+	if err != nil || name != "Bob" || email != "bob@example.com" {
+		t.Errorf("GetUserIdentity() = %q, %q, %v", name, email, err)
+	}
+
+	exists, err := p.CheckRepoExists("kepr-store")
+	if err != nil || !exists {
+		t.Errorf("CheckRepoExists(kepr-store) = %v, %v, want true", exists, err)
+	}
+	exists, err = p.CheckRepoExists("other")
+	if err != nil || exists {
+		t.Errorf("CheckRepoExists(other) = %v, %v, want false", exists, err)
+	}
+
+	if err := p.CreateRepo("other"); err != nil {
+		t.Fatalf("CreateRepo() returned error: %v", err)
+	}
+	if created["name"] != "other" || created["private"] != true {
+		t.Errorf("CreateRepo() sent %v, want a private repository", created)
+	}
+
+	url, err := p.GetCloneURL("kepr-store")
+	if err != nil || url != "https://gitea.example/bob/kepr-store.git" {
+		t.Errorf("GetCloneURL() = %q, %v", url, err)
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	barePath := filepath.Join(t.TempDir(), "store.git")
+	if _, err := gogit.PlainInit(barePath, true); err != nil {
+		t.Fatalf("failed to create bare repository: %v", err)
+	}
+	remote := "file://" + barePath
+
+	p, err := New(KindGeneric, remote)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	if _, _, err := p.GetUserIdentity(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetUserIdentity() error = %v, want ErrUnsupported", err)
+	}
+	exists, err := p.CheckRepoExists("ignored")
+	if err != nil || exists {
+		t.Errorf("CheckRepoExists() = %v, %v, want false for an empty remote", exists, err)
+	}
+	if err := p.CreateRepo("ignored"); err != nil {
+		t.Errorf("CreateRepo() returned error: %v", err)
+	}
+	url, err := p.GetCloneURL("ignored")
+	if err != nil || url != remote {
+		t.Errorf("GetCloneURL() = %q, %v, want %q", url, err, remote)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	barePath := filepath.Join(t.TempDir(), "srv", "store.git")
+
+	p, err := New(KindLocal, barePath)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if NeedsToken(KindLocal) || !IsLocal(KindLocal) {
+		t.Error("local stores should need neither a token nor the network")
+	}
+
+	exists, err := p.CheckRepoExists("ignored")
+	if err != nil || exists {
+		t.Errorf("CheckRepoExists() = %v, %v, want false for a missing repository", exists, err)
+	}
+	if err := p.CreateRepo("ignored"); err != nil {
+		t.Fatalf("CreateRepo() returned error: %v", err)
+	}
+	if _, err := gogit.PlainOpen(barePath); err != nil {
+		t.Fatalf("CreateRepo() did not create a bare repository: %v", err)
+	}
+	exists, err = p.CheckRepoExists("ignored")
+	if err != nil || exists {
+		t.Errorf("CheckRepoExists() = %v, %v, want false for an empty repository", exists, err)
+	}
+	if err := p.CreateRepo("ignored"); err != nil {
+		t.Errorf("CreateRepo() on an existing repository returned error: %v", err)
+	}
+
+	none, err := New(KindLocal, "")
+	if err != nil {
+		t.Fatalf("New() without a remote returned error: %v", err)
+	}
+	if err := none.CreateRepo("ignored"); err != nil {
+		t.Errorf("CreateRepo() without a remote returned error: %v", err)
+	}
+	if _, _, err := none.GetUserIdentity(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetUserIdentity() error = %v, want ErrUnsupported", err)
+	}
+}
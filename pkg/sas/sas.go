@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+// Package sas derives short authentication strings that let a requester and
+// an approver confirm out of band that they are looking at the same request.
+package sas
+
+import (
+	"crypto/sha256"
+	"strings"
+)
+
+// Length is the number of words in a code; each word carries 8 bits.
+const Length = 4
+
+// Code derives a word sequence from a request's fingerprint and UUID.
+func Code(fingerprint, requestUUID string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(fingerprint) + "\n" + strings.ToLower(requestUUID)))
+
+	codeWords := make([]string, Length)
+	for i := range codeWords {
+		codeWords[i] = words[sum[i]]
+	}
+	return strings.Join(codeWords, "-")
+}
+
+// Matches reports whether input is the code typed back, ignoring case and
+// accepting spaces in place of hyphens.
+func Matches(code, input string) bool {
+	normalized := strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(input), "-", " ")), "-")
+	return normalized != "" && normalized == code
+}
+
+var words = [256]string{
+	"acid", "acorn", "actor", "agent", "alarm", "album", "alpine", "amber",
+	"anchor", "angle", "apple", "apron", "arch", "arena", "armor", "arrow",
+	"atlas", "atom", "autumn", "avocado", "badge", "bagel", "baker", "bamboo",
+	"banjo", "barrel", "basil", "beacon", "beaver", "bench", "berry", "bison",
+	"blade", "blanket", "bloom", "border", "bottle", "bramble", "breeze",
+	"brick", "bridge", "bronze", "brook", "bubble", "bucket", "buffalo",
+	"bugle", "cabin", "cactus", "camel", "candle", "canoe", "canyon",
+	"carbon", "cargo", "carpet", "castle", "cedar", "cello", "chalk",
+	"cherry", "chess", "cider", "circus", "citrus", "clock", "clover",
+	"cobalt", "cocoa", "comet", "compass", "copper", "coral", "cotton",
+	"cowboy", "coyote", "crater", "crayon", "cricket", "crystal", "cupcake",
+	"dagger", "daisy", "dancer", "delta", "desert", "diamond", "dingo",
+	"dolphin", "domino", "donkey", "dragon", "drum", "eagle", "echo",
+	"eclipse", "elbow", "ember", "emerald", "engine", "falcon", "feather",
+	"fennel", "ferry", "fiddle", "fig", "flame", "flannel", "flute", "forest",
+	"fossil", "fountain", "fox", "galaxy", "garden", "garlic", "gecko",
+	"geyser", "ginger", "glacier", "globe", "goblet", "granite", "grape",
+	"gravel", "guitar", "hammer", "harbor", "harvest", "hazel", "helmet",
+	"heron", "hickory", "honey", "hornet", "husky", "igloo", "indigo", "iris",
+	"island", "ivory", "jacket", "jaguar", "jasmine", "jelly", "jigsaw",
+	"jungle", "kayak", "kernel", "kettle", "kiwi", "koala", "ladder",
+	"lagoon", "lantern", "laser", "lemon", "lentil", "library", "lilac",
+	"lizard", "lobster", "locket", "lotus", "lunar", "magnet", "mango",
+	"maple", "marble", "meadow", "melon", "meteor", "mint", "mirror", "mocha",
+	"monkey", "mosaic", "motor", "muffin", "nectar", "needle", "nickel",
+	"noodle", "nutmeg", "oasis", "oatmeal", "ocean", "olive", "onion", "opal",
+	"orbit", "orchid", "otter", "oyster", "paddle", "panda", "paper",
+	"parrot", "peach", "pebble", "pepper", "piano", "pickle", "pillow",
+	"pilot", "pine", "pirate", "planet", "plum", "pocket", "polar", "pony",
+	"poppy", "prism", "pumpkin", "puzzle", "quartz", "quiver", "rabbit",
+	"radar", "radish", "raven", "reef", "ribbon", "river", "robin", "rocket",
+	"saddle", "saffron", "salmon", "sandal", "satin", "scarf", "shadow",
+	"sierra", "silver", "skate", "sparrow", "spider", "sponge", "spruce",
+	"squid", "stamp", "sunset", "tango", "teapot", "thunder", "tiger",
+	"timber", "tomato", "tulip", "tundra", "turtle", "umbrella", "velvet",
+	"violet",
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package sas
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWords_Unique(t *testing.T) {
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		if w == "" {
+			t.Fatal("word list has an empty entry")
+		}
+		if seen[w] {
+			t.Errorf("duplicate word %q", w)
+		}
+		seen[w] = true
+	}
+}
+
+func TestCode_Deterministic(t *testing.T) {
+	fp := "553D727D2BC9F896DC405B0477ED18083565E063"
+	uuid := "04cd4f01-ecc5-4662-b0ec-66d62e14ba82"
+
+	code := Code(fp, uuid)
+	if got := len(strings.Split(code, "-")); got != Length {
+		t.Fatalf("Code() = %q has %d words, want %d", code, got, Length)
+	}
+	if Code(strings.ToLower(fp), strings.ToUpper(uuid)) != code {
+		t.Error("Code() should not depend on the case of its inputs")
+	}
+	if Code(fp, "14cd4f01-ecc5-4662-b0ec-66d62e14ba82") == code {
+		t.Error("Code() should change with the request UUID")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	code := "amber-falcon-orbit-velvet"
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"amber-falcon-orbit-velvet", true},
+		{"Amber Falcon Orbit Velvet", true},
+		{"  amber-falcon  orbit velvet ", true},
+		{"amber-falcon-orbit", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := Matches(code, tt.input); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -36,7 +36,7 @@ func ScanFingerprint(secretsPath, fingerprint string) (bool, error) {
 			return nil
 		}
 
-		if info.Name() != ".gpg.id" {
+		if !strings.HasSuffix(info.Name(), ".gpg.id") {
 			return nil
 		}
 
@@ -59,3 +59,19 @@ func ScanFingerprint(secretsPath, fingerprint string) (bool, error) {
 
 	return found, err
 }
+
+// HasAccessTo reports whether the store's fingerprint is a recipient of the
+// directory at logicalPath, looking through parent folders it cannot read.
+func (s *Store) HasAccessTo(logicalPath string) bool {
+	normalizedPath, err := NormalizePath(logicalPath)
+	if err != nil {
+		return false
+	}
+
+	dirPath, err := s.resolveAccessiblePath(SplitPath(normalizedPath))
+	if err != nil {
+		return false
+	}
+
+	return s.hasAccess(dirPath)
+}
@@ -96,3 +96,22 @@ func TestScanFingerprint_InvalidPath(t *testing.T) {
 		t.Error("expected error for nonexistent path")
 	}
 }
+
+func TestScanFingerprint_SecretOverride(t *testing.T) {
+	tempDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".gpg.id"), []byte("AAAA\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "abc.gpg.id"), []byte("AAAA\nCCCC\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := ScanFingerprint(tempDir, "CCCC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found {
+		t.Error("expected fingerprint in a per-secret override to be found")
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type DirACL struct {
+	Path         string
+	Dir          string
+	Fingerprints []string
+	Secrets      []SecretACL
+	Differs      bool
+	Accessible   bool
+}
+
+// SecretACL is a per-secret recipient override inside a directory.
+type SecretACL struct {
+	UUID         string
+	Name         string
+	Fingerprints []string
+}
+
+func (s *Store) ListACLs(path string, recursive bool) ([]DirACL, error) {
+	slog.Debug("listing access control", "path", path, "recursive", recursive)
+
+	targetPath := s.SecretsPath
+	logicalPath := ""
+
+	if path != "" {
+		normalizedPath, err := NormalizePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+
+		resolved, err := s.resolveAccessiblePath(SplitPath(normalizedPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %q: %w", path, err)
+		}
+		targetPath = resolved
+		logicalPath = normalizedPath
+	}
+
+	var parentFingerprints []string
+	if targetPath != s.SecretsPath {
+		parentFingerprints, _ = ReadGpgID(filepath.Dir(targetPath))
+	}
+
+	var result []DirACL
+	if err := s.collectACLs(targetPath, logicalPath, parentFingerprints, s.hasAccess(targetPath), recursive, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Store) collectACLs(dirPath, logicalPath string, parentFingerprints []string, accessible, recursive bool, result *[]DirACL) error {
+	fingerprints, err := ReadGpgID(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .gpg.id for %s: %w", DisplayPath(logicalPath), err)
+	}
+
+	var secrets []SecretACL
+	for uuid, overrideFingerprints := range secretOverrides(dirPath) {
+		name := uuid
+		if accessible {
+			name = s.secretName(dirPath, uuid)
+		}
+		secrets = append(secrets, SecretACL{UUID: uuid, Name: name, Fingerprints: overrideFingerprints})
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	*result = append(*result, DirACL{
+		Path:         logicalPath,
+		Dir:          dirPath,
+		Fingerprints: fingerprints,
+		Secrets:      secrets,
+		Differs:      parentFingerprints != nil && !sameFingerprints(fingerprints, parentFingerprints),
+		Accessible:   accessible,
+	})
+
+	if !recursive {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var children []DirACL
+	for _, entry := range entries {
+		if !entry.IsDir() || !isStoreDir(entry.Name()) {
+			continue
+		}
+
+		subDir := filepath.Join(dirPath, entry.Name())
+		if _, err := os.Stat(filepath.Join(subDir, ".gpg.id")); err != nil {
+			continue
+		}
+
+		segment, ok := s.dirSegment(subDir, entry.Name())
+		children = append(children, DirACL{
+			Path:       joinLogicalPath(logicalPath, segment),
+			Dir:        subDir,
+			Accessible: ok,
+		})
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Path < children[j].Path
+	})
+
+	for _, child := range children {
+		if err := s.collectACLs(child.Dir, child.Path, fingerprints, child.Accessible, recursive, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) dirSegment(dirPath, uuid string) (string, bool) {
+	if !s.hasAccess(dirPath) {
+		return uuid, false
+	}
+
+	encrypted, err := os.ReadFile(filepath.Join(dirPath, uuid+"_md.gpg"))
+	if err != nil {
+		return uuid, false
+	}
+
+	decrypted, err := s.gpg.Decrypt(encrypted)
+	if err != nil {
+		return uuid, false
+	}
+
+	metadata, err := DeserializeMetadata(decrypted)
+	if err != nil || metadata.Type != TypeDir {
+		return uuid, false
+	}
+
+	return pathSegment(metadata.Path), true
+}
+
+func (s *Store) secretName(dirPath, uuid string) string {
+	encrypted, err := os.ReadFile(filepath.Join(dirPath, uuid+"_md.gpg"))
+	if err != nil {
+		return uuid
+	}
+
+	decrypted, err := s.gpg.Decrypt(encrypted)
+	if err != nil {
+		return uuid
+	}
+
+	metadata, err := DeserializeMetadata(decrypted)
+	if err != nil {
+		return uuid
+	}
+
+	return metadata.Path
+}
+
+func joinLogicalPath(parent, segment string) string {
+	if parent == "" {
+		return segment
+	}
+	return parent + "/" + segment
+}
+
+// DisplayPath returns logicalPath for display, showing the store root as "/".
+func DisplayPath(logicalPath string) string {
+	if logicalPath == "" {
+		return "/"
+	}
+	return logicalPath
+}
+
+func sameFingerprints(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, fp := range a {
+		set[fp] = true
+	}
+	for _, fp := range b {
+		if !set[fp] {
+			return false
+		}
+	}
+	return true
+}
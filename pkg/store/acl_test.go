@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestGpgID(t *testing.T, dir string, fingerprints ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteGpgID(dir, fingerprints); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListACLs_Recursive(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_AAA")
+	writeTestGpgID(t, filepath.Join(tempDir, "u1"), "FP_AAA")
+	writeTestGpgID(t, filepath.Join(tempDir, "u1", "u2"), "FP_AAA", "FP_BBB")
+
+	st := &Store{SecretsPath: tempDir, Fingerprint: "FP_ZZZ"}
+	acls, err := st.ListACLs("", true)
+	if err != nil {
+		t.Fatalf("ListACLs() failed: %v", err)
+	}
+
+	if len(acls) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(acls))
+	}
+
+	if acls[0].Path != "" || acls[0].Differs {
+		t.Errorf("unexpected root entry: %+v", acls[0])
+	}
+	if acls[1].Path != "u1" || acls[1].Differs || acls[1].Accessible {
+		t.Errorf("unexpected u1 entry: %+v", acls[1])
+	}
+	if acls[2].Path != "u1/u2" || !acls[2].Differs {
+		t.Errorf("unexpected u1/u2 entry: %+v", acls[2])
+	}
+}
+
+func TestListACLs_NotRecursive(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_AAA")
+	writeTestGpgID(t, filepath.Join(tempDir, "u1"), "FP_AAA")
+
+	st := &Store{SecretsPath: tempDir, Fingerprint: "FP_AAA"}
+	acls, err := st.ListACLs("", false)
+	if err != nil {
+		t.Fatalf("ListACLs() failed: %v", err)
+	}
+
+	if len(acls) != 1 || !acls[0].Accessible {
+		t.Errorf("expected only an accessible root entry, got %+v", acls)
+	}
+}
+
+func TestSameFingerprints(t *testing.T) {
+	if !sameFingerprints([]string{"A", "B"}, []string{"B", "A"}) {
+		t.Error("expected order-insensitive match")
+	}
+	if sameFingerprints([]string{"A"}, []string{"A", "B"}) {
+		t.Error("expected mismatch on different lengths")
+	}
+	if sameFingerprints([]string{"A", "C"}, []string{"A", "B"}) {
+		t.Error("expected mismatch on different members")
+	}
+}
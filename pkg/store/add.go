@@ -81,7 +81,7 @@ func (s *Store) Add(path string, io cout.IO) (string, error) {
 		return "", fmt.Errorf("failed to generate UUID: %w", err)
 	}
 
-	fingerprints, err := ReadGpgID(currentPath)
+	fingerprints, err := s.secretRecipients(currentPath, uuid)
 	if err != nil {
 		return "", fmt.Errorf("failed to read .gpg.id in target directory: %w", err)
 	}
@@ -178,7 +178,7 @@ func (s *Store) AddFile(path string, fileData []byte, originalFilename string) (
 		return "", fmt.Errorf("failed to generate UUID: %w", err)
 	}
 
-	fingerprints, err := ReadGpgID(currentPath)
+	fingerprints, err := s.secretRecipients(currentPath, uuid)
 	if err != nil {
 		return "", fmt.Errorf("failed to read .gpg.id in target directory: %w", err)
 	}
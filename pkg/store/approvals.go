@@ -0,0 +1,217 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+const ApprovalsDir = "approvals"
+
+// Approval is a signed statement by an admin that a request may be granted.
+// The approver's public key is embedded so other admins can verify the
+// signature even before the key reaches keys/ on main.
+type Approval struct {
+	RequestUUID string `json:"request_uuid"`
+	Approver    string `json:"approver"`
+	// Path is the path the request asked for.
+	Path string `json:"path"`
+	// GrantPath is the path the approver grants, which may be narrower than
+	// or different from Path.
+	GrantPath string `json:"grant_path,omitempty"`
+	// Requester is the fingerprint of the machine key the request grants
+	// access to. Approvals of policy changes leave it empty.
+	Requester string `json:"requester,omitempty"`
+	PublicKey string `json:"public_key"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Granted returns the path the approval grants. Approvals recorded before
+// GrantPath existed grant Path.
+func (a *Approval) Granted() string {
+	if a.GrantPath != "" {
+		return a.GrantPath
+	}
+	return a.Path
+}
+
+func ApprovalFileName(requestUUID, approver string) string {
+	return requestUUID + "_" + approver + ".asc"
+}
+
+// ReadApprovals returns the signed approvals kept in the store's approvals/
+// directory, keyed by file name.
+func ReadApprovals(secretsPath string) (map[string][]byte, error) {
+	dir := filepath.Join(secretsPath, ApprovalsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read approvals directory: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".asc") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read approval %s: %w", entry.Name(), err)
+		}
+		files[entry.Name()] = data
+	}
+	return files, nil
+}
+
+func SignApproval(g *gpg.GPG, a Approval) ([]byte, error) {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal approval: %w", err)
+	}
+
+	signed, err := g.Sign(append(data, '\n'), a.Approver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign approval: %w", err)
+	}
+	return signed, nil
+}
+
+func VerifyApproval(g *gpg.GPG, signed []byte) (*Approval, error) {
+	text, err := gpg.ClearsignedText(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var unverified Approval
+	if err := json.Unmarshal(text, &unverified); err != nil {
+		return nil, fmt.Errorf("failed to parse approval: %w", err)
+	}
+
+	if unverified.PublicKey != "" {
+		if err := g.ImportPublicKey([]byte(unverified.PublicKey)); err != nil {
+			return nil, fmt.Errorf("failed to import approver key: %w", err)
+		}
+	}
+
+	content, signer, err := g.Verify(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var a Approval
+	if err := json.Unmarshal(content, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse approval: %w", err)
+	}
+
+	if signer != a.Approver {
+		return nil, fmt.Errorf("approval for %s was signed by %s", a.Approver, signer)
+	}
+
+	return &a, nil
+}
+
+// VerifiedApprovers returns the distinct admins with a valid signed approval
+// granting path for the request among files read from the request branch.
+func VerifiedApprovers(g *gpg.GPG, files map[string][]byte, requestUUID, path string, admins []string) []string {
+	isAdmin := make(map[string]bool, len(admins))
+	for _, fp := range admins {
+		isAdmin[fp] = true
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var approvers []string
+	for _, name := range names {
+		if !strings.HasPrefix(name, requestUUID+"_") || !strings.HasSuffix(name, ".asc") {
+			continue
+		}
+
+		a, err := VerifyApproval(g, files[name])
+		if err != nil {
+			slog.Debug("ignoring invalid approval", "file", name, "error", err)
+			continue
+		}
+		if a.RequestUUID != requestUUID || a.Granted() != path {
+			slog.Debug("ignoring approval for a different request", "file", name)
+			continue
+		}
+		if !isAdmin[a.Approver] {
+			slog.Debug("ignoring approval from non-admin", "file", name, "approver", a.Approver)
+			continue
+		}
+		approvers = appendUnique(approvers, a.Approver)
+	}
+
+	return approvers
+}
+
+// RecordedApproval returns the first valid approval of the request signed by
+// one of admins among files read from approvals/, or nil when there is none.
+func RecordedApproval(g *gpg.GPG, files map[string][]byte, requestUUID string, admins []string) *Approval {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if strings.HasPrefix(name, requestUUID+"_") && strings.HasSuffix(name, ".asc") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a, err := VerifyApproval(g, files[name])
+		if err != nil {
+			slog.Debug("ignoring invalid approval", "file", name, "error", err)
+			continue
+		}
+		if a.RequestUUID == requestUUID && slices.Contains(admins, a.Approver) {
+			return a
+		}
+	}
+	return nil
+}
+
+// RequestedKeys returns the fingerprints granted access through a request,
+// as recorded by valid approvals signed by one of admins among files read
+// from approvals/.
+func RequestedKeys(g *gpg.GPG, files map[string][]byte, admins []string) map[string]bool {
+	keys := make(map[string]bool)
+	for name, signed := range files {
+		a, err := VerifyApproval(g, signed)
+		if err != nil {
+			slog.Debug("ignoring invalid approval", "file", name, "error", err)
+			continue
+		}
+		if a.Requester != "" && slices.Contains(admins, a.Approver) {
+			keys[a.Requester] = true
+		}
+	}
+	return keys
+}
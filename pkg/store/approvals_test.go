@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApproval_GrantPath(t *testing.T) {
+	a := Approval{RequestUUID: "3f2a", Approver: "ADMIN", Path: "prod", GrantPath: "prod/app"}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded Approval
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Path != "prod" || decoded.Granted() != "prod/app" {
+		t.Errorf("decoded approval = %+v, want prod narrowed to prod/app", decoded)
+	}
+
+	legacy := Approval{RequestUUID: "3f2a", Approver: "ADMIN", Path: "prod"}
+	if got := legacy.Granted(); got != "prod" {
+		t.Errorf("Granted() without GrantPath = %q, want the requested path", got)
+	}
+}
+
+func TestRecordedApproval_IgnoresUnsigned(t *testing.T) {
+	data, err := json.Marshal(Approval{RequestUUID: "3f2a", Approver: "ADMIN", Path: "prod", GrantPath: "prod/app"})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	files := map[string][]byte{ApprovalFileName("3f2a", "ADMIN"): data}
+
+	if a := RecordedApproval(nil, files, "3f2a", []string{"ADMIN"}); a != nil {
+		t.Errorf("RecordedApproval() = %+v for an unsigned approval, want nil", a)
+	}
+}
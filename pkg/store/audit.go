@@ -0,0 +1,163 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+// Kinds of change reported by `kepr audit`.
+const (
+	AuditInit    = "init"
+	AuditAdd     = "add"
+	AuditUpdate  = "update"
+	AuditRekey   = "rekey"
+	AuditApprove = "approve"
+	AuditGrant   = "grant"
+	AuditRevoke  = "revoke"
+	AuditRequest = "request"
+	AuditReject  = "reject"
+	AuditOther   = "other"
+)
+
+// auditMessages classifies commits by the messages kepr writes for them.
+var auditMessages = []struct {
+	prefix string
+	kind   string
+}{
+	{"initialized secret store", AuditInit},
+	{"Approve access request", AuditApprove},
+	{"Grant access", AuditGrant},
+	{"Revoke", AuditRevoke},
+	{"Reject access request", AuditReject},
+	{"New access request", AuditRequest},
+	{"Prune", AuditRequest},
+}
+
+// metaDirs are the store's own top-level directories, which hold no secrets.
+var metaDirs = map[string]bool{
+	"keys":       true,
+	"groups":     true,
+	"requests":   true,
+	"rejections": true,
+	ApprovalsDir: true,
+}
+
+// ClassifyCommit works out what a commit did from its message and the files
+// it added, modified and deleted.
+func ClassifyCommit(message string, added, modified, deleted []string) string {
+	for _, m := range auditMessages {
+		if strings.HasPrefix(message, m.prefix) {
+			return m.kind
+		}
+	}
+	if strings.Contains(message, " to group ") || strings.Contains(message, " from group ") {
+		return AuditRekey
+	}
+
+	for _, p := range added {
+		if isSecretFile(p) {
+			return AuditAdd
+		}
+	}
+
+	recipients := false
+	for _, p := range append(append(append([]string{}, added...), modified...), deleted...) {
+		if path.Base(p) == ".gpg.id" || strings.HasSuffix(p, ".gpg.id") || strings.HasPrefix(p, "groups/") {
+			recipients = true
+		}
+	}
+	if recipients {
+		return AuditRekey
+	}
+
+	for _, p := range modified {
+		if isSecretFile(p) {
+			return AuditUpdate
+		}
+	}
+	return AuditOther
+}
+
+func isSecretFile(p string) bool {
+	if metaDirs[strings.SplitN(p, "/", 2)[0]] {
+		return false
+	}
+	return strings.HasSuffix(p, ".gpg") && !strings.HasSuffix(p, "_md.gpg")
+}
+
+// PathResolver maps store files to logical paths by decrypting the metadata
+// next to them. Names this machine cannot decrypt stay as UUIDs.
+type PathResolver struct {
+	gpg   *gpg.GPG
+	names map[string]string
+}
+
+func NewPathResolver(g *gpg.GPG) *PathResolver {
+	return &PathResolver{gpg: g, names: make(map[string]string)}
+}
+
+// Resolve returns the logical path of a file in the store, using read to
+// load metadata files by their path in the store. Files outside the secret
+// tree, such as keys/ or policy.json, are returned unchanged.
+func (r *PathResolver) Resolve(relPath string, read func(relPath string) []byte) string {
+	dir, file := path.Split(relPath)
+	dir = strings.TrimSuffix(dir, "/")
+
+	var segments []string
+	if dir != "" {
+		segments = strings.Split(dir, "/")
+	}
+	if (len(segments) > 0 && metaDirs[segments[0]]) || !strings.Contains(file, ".gpg") {
+		return relPath
+	}
+
+	var logical []string
+	current := ""
+	for _, uuid := range segments {
+		current = path.Join(current, uuid)
+		logical = append(logical, r.name(read(path.Join(current, uuid+"_md.gpg")), uuid))
+	}
+
+	uuid := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(file, ".gpg.id"), "_md.gpg"), ".gpg")
+	if uuid != "" && (len(segments) == 0 || uuid != segments[len(segments)-1]) {
+		logical = append(logical, r.name(read(path.Join(current, uuid+"_md.gpg")), uuid))
+	}
+
+	return DisplayPath(strings.Join(logical, "/"))
+}
+
+func (r *PathResolver) name(metadata []byte, uuid string) string {
+	if len(metadata) == 0 {
+		return uuid
+	}
+	if name, ok := r.names[string(metadata)]; ok {
+		return name
+	}
+
+	name := uuid
+	if decrypted, err := r.gpg.Decrypt(metadata); err == nil {
+		if m, err := DeserializeMetadata(decrypted); err == nil && m.Path != "" {
+			name = pathSegment(m.Path)
+		}
+	}
+	r.names[string(metadata)] = name
+	return name
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import "testing"
+
+func TestClassifyCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		added    []string
+		modified []string
+		want     string
+	}{
+		{"approve", "Approve access request 3f2a", nil, []string{"a/.gpg.id"}, AuditApprove},
+		{"grant", "Grant access to prod for AAAA", nil, []string{"a/.gpg.id"}, AuditGrant},
+		{"expire", "Revoke 2 expired grant(s)", nil, []string{"a/.gpg.id"}, AuditRevoke},
+		{"group", "Add AAAA to group ops", []string{"groups/ops.gpg"}, nil, AuditRekey},
+		{"new secret", "updated store with new UUID b", []string{"a/b.gpg", "a/b_md.gpg"}, nil, AuditAdd},
+		{"changed secret", "updated store with new UUID b", nil, []string{"a/b.gpg"}, AuditUpdate},
+		{"recipients", "manual edit", nil, []string{"a/.gpg.id", "a/b.gpg"}, AuditRekey},
+		{"request file", "something", []string{"requests/x.json.gpg"}, nil, AuditOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyCommit(tt.message, tt.added, tt.modified, nil); got != tt.want {
+				t.Errorf("ClassifyCommit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathResolver_Resolve(t *testing.T) {
+	r := NewPathResolver(nil)
+	r.names["meta-a"] = "prod"
+	r.names["meta-b"] = "password"
+
+	files := map[string][]byte{
+		"a/a_md.gpg": []byte("meta-a"),
+		"a/b_md.gpg": []byte("meta-b"),
+	}
+	read := func(p string) []byte { return files[p] }
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a/b.gpg", "prod/password"},
+		{"a/b.gpg.id", "prod/password"},
+		{"a/.gpg.id", "prod"},
+		{"a/a_md.gpg", "prod"},
+		{".gpg.id", "/"},
+		{"a/c.gpg", "prod/c"},
+		{"x/y.gpg", "x/y"},
+		{"keys/AAAA.key", "keys/AAAA.key"},
+		{"policy.json", "policy.json"},
+	}
+	for _, tt := range tests {
+		if got := r.Resolve(tt.path, read); got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
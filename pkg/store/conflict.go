@@ -0,0 +1,39 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"path"
+	"strings"
+)
+
+// ConflictKey groups the files of a store that must be kept or dropped
+// together when local and remote changes are reconciled: a secret's data,
+// metadata and per-secret recipients share its UUID. Folder recipient files
+// and everything outside the secret tree stand on their own.
+func ConflictKey(p string) string {
+	file := path.Base(p)
+	if file == ".gpg.id" || metaDirs[strings.SplitN(p, "/", 2)[0]] {
+		return p
+	}
+	for _, suffix := range []string{".gpg.id", "_md.gpg", ".gpg"} {
+		if strings.HasSuffix(file, suffix) {
+			return strings.TrimSuffix(p, suffix)
+		}
+	}
+	return p
+}
@@ -0,0 +1,42 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import "testing"
+
+func TestConflictKey(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"dir/abc.gpg", "dir/abc"},
+		{"dir/abc_md.gpg", "dir/abc"},
+		{"dir/abc.gpg.id", "dir/abc"},
+		{"dir/dir_md.gpg", "dir/dir"},
+		{"dir/.gpg.id", "dir/.gpg.id"},
+		{".gpg.id", ".gpg.id"},
+		{"keys/FP.key", "keys/FP.key"},
+		{"groups/ops.gpg", "groups/ops.gpg"},
+		{"policy.json", "policy.json"},
+	}
+
+	for _, tt := range tests {
+		if got := ConflictKey(tt.path); got != tt.want {
+			t.Errorf("ConflictKey(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+const ExpirationsFile = "expirations.json"
+
+// Expiration records when a fingerprint's access to a path should be revoked.
+type Expiration struct {
+	Fingerprint string    `json:"fingerprint"`
+	Path        string    `json:"path"`
+	Expires     time.Time `json:"expires"`
+	// Kept lists the recipient files under Path, relative to the store,
+	// that give the fingerprint access independently of this grant. The
+	// revocation leaves them alone.
+	Kept []string `json:"kept,omitempty"`
+}
+
+type Expirations struct {
+	Grants []Expiration `json:"grants"`
+}
+
+func LoadExpirations(secretsPath string) (*Expirations, error) {
+	data, err := os.ReadFile(filepath.Join(secretsPath, ExpirationsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Expirations{}, nil
+		}
+		return nil, fmt.Errorf("failed to read expirations: %w", err)
+	}
+
+	var e Expirations
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse expirations: %w", err)
+	}
+	return &e, nil
+}
+
+func SaveExpirations(secretsPath string, e *Expirations) error {
+	sort.Slice(e.Grants, func(i, j int) bool {
+		if e.Grants[i].Path != e.Grants[j].Path {
+			return e.Grants[i].Path < e.Grants[j].Path
+		}
+		return e.Grants[i].Fingerprint < e.Grants[j].Fingerprint
+	})
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal expirations: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(secretsPath, ExpirationsFile), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write expirations: %w", err)
+	}
+
+	return EnsureGitignore(secretsPath)
+}
+
+// Set records the expiry of a fingerprint's grant on path, together with the
+// recipient files that listed it before the grant. Renewing a grant only
+// moves its expiry, since by then the grant itself has added the fingerprint
+// everywhere.
+func (e *Expirations) Set(fingerprint, path string, expires time.Time, kept []string) {
+	for i, g := range e.Grants {
+		if g.Fingerprint == fingerprint && g.Path == path {
+			e.Grants[i].Expires = expires
+			return
+		}
+	}
+	e.Grants = append(e.Grants, Expiration{Fingerprint: fingerprint, Path: path, Expires: expires, Kept: kept})
+}
+
+// Keep records that files give fingerprint access independently of its
+// expiring grants on overlapping paths, for example after a permanent grant
+// on path.
+func (e *Expirations) Keep(fingerprint, path string, files []string) {
+	for i, g := range e.Grants {
+		if g.Fingerprint != fingerprint || !overlaps(g.Path, path) {
+			continue
+		}
+		for _, f := range files {
+			if !slices.Contains(e.Grants[i].Kept, f) {
+				e.Grants[i].Kept = append(e.Grants[i].Kept, f)
+			}
+		}
+		sort.Strings(e.Grants[i].Kept)
+	}
+}
+
+// Overlapping returns the other grants of the same fingerprint on paths
+// above or below grant's path.
+func (e *Expirations) Overlapping(grant Expiration) []Expiration {
+	var overlapping []Expiration
+	for _, g := range e.Grants {
+		if g.Fingerprint != grant.Fingerprint || g.Path == grant.Path {
+			continue
+		}
+		if overlaps(g.Path, grant.Path) {
+			overlapping = append(overlapping, g)
+		}
+	}
+	return overlapping
+}
+
+func overlaps(a, b string) bool {
+	return PathWithin(a, b) || PathWithin(b, a)
+}
+
+func (e *Expirations) Remove(fingerprint, path string) {
+	kept := e.Grants[:0]
+	for _, g := range e.Grants {
+		if g.Fingerprint != fingerprint || g.Path != path {
+			kept = append(kept, g)
+		}
+	}
+	e.Grants = kept
+}
+
+// Due returns the grants whose expiry is at or before now.
+func (e *Expirations) Due(now time.Time) []Expiration {
+	var due []Expiration
+	for _, g := range e.Grants {
+		if !g.Expires.After(now) {
+			due = append(due, g)
+		}
+	}
+	return due
+}
+
+// RecipientFiles returns the recipient files at or below logicalPath,
+// relative to the store, that list fingerprint directly.
+func (s *Store) RecipientFiles(logicalPath, fingerprint string) ([]string, error) {
+	dirPath, err := s.ResolvePath(logicalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %q: %w", logicalPath, err)
+	}
+
+	var files []string
+	if err := s.collectRecipientFiles(dirPath, fingerprint, &files); err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (s *Store) collectRecipientFiles(dirPath, fingerprint string, files *[]string) error {
+	fingerprints, err := ReadGpgID(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .gpg.id: %w", err)
+	}
+	if slices.Contains(fingerprints, fingerprint) {
+		*files = append(*files, s.relativePath(filepath.Join(dirPath, ".gpg.id")))
+	}
+	for uuid, overrideFingerprints := range secretOverrides(dirPath) {
+		if slices.Contains(overrideFingerprints, fingerprint) {
+			*files = append(*files, s.relativePath(SecretGpgIDPath(dirPath, uuid)))
+		}
+	}
+
+	subDirs, err := rekeySubdirs(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, subDir := range subDirs {
+		if err := s.collectRecipientFiles(subDir, fingerprint, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) relativePath(path string) string {
+	rel, err := filepath.Rel(s.SecretsPath, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// KeepPermanentGrant records a grant without expiry of fingerprint on path,
+// or on the single secret at path, so that revoking an expiring grant of the
+// same fingerprint on an overlapping path leaves it in place. An expiring
+// grant on the same path becomes permanent.
+func (s *Store) KeepPermanentGrant(logicalPath, fingerprint string, secret bool) error {
+	e, err := LoadExpirations(s.SecretsPath)
+	if err != nil {
+		return err
+	}
+	grant := Expiration{Fingerprint: fingerprint, Path: logicalPath}
+	if len(e.Overlapping(grant)) == 0 && !slices.ContainsFunc(e.Grants, func(g Expiration) bool {
+		return g.Fingerprint == fingerprint && g.Path == logicalPath
+	}) {
+		return nil
+	}
+
+	var files []string
+	if secret {
+		dirPath, uuid, err := s.resolveSecret(logicalPath)
+		if err != nil {
+			return err
+		}
+		files = []string{s.relativePath(SecretGpgIDPath(dirPath, uuid))}
+	} else {
+		e.Remove(fingerprint, logicalPath)
+		files, err = s.RecipientFiles(logicalPath, fingerprint)
+		if err != nil {
+			return err
+		}
+	}
+
+	e.Keep(fingerprint, logicalPath, files)
+	return SaveExpirations(s.SecretsPath, e)
+}
+
+// RevokeGrant removes the grant's fingerprint from its path and every folder
+// below it, except from the recipient files the grant kept.
+func (s *Store) RevokeGrant(grant Expiration) error {
+	keep := make(map[string]bool, len(grant.Kept))
+	for _, f := range grant.Kept {
+		keep[filepath.Join(s.SecretsPath, filepath.FromSlash(f))] = true
+	}
+	return s.UpdateRecipients(grant.Path, RecipientUpdate{Remove: []string{grant.Fingerprint}, Keep: keep})
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpirations_SetAndDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	e := &Expirations{}
+
+	e.Set("FP_AAA", "prod", now.Add(-time.Hour), nil)
+	e.Set("FP_BBB", "prod", now.Add(time.Hour), nil)
+	e.Set("FP_AAA", "dev", now, nil)
+	e.Set("FP_BBB", "prod", now.Add(-time.Minute), nil)
+
+	if len(e.Grants) != 3 {
+		t.Fatalf("len(Grants) = %d, want 3", len(e.Grants))
+	}
+
+	due := e.Due(now)
+	if len(due) != 3 {
+		t.Fatalf("len(Due()) = %d, want 3", len(due))
+	}
+
+	e.Remove("FP_AAA", "prod")
+	if len(e.Grants) != 2 {
+		t.Fatalf("len(Grants) after Remove = %d, want 2", len(e.Grants))
+	}
+	if len(e.Due(now.Add(-2*time.Minute))) != 0 {
+		t.Errorf("Due() before any expiry returned grants")
+	}
+}
+
+func TestExpirations_SaveAndLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	expires := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	e, err := LoadExpirations(tempDir)
+	if err != nil {
+		t.Fatalf("LoadExpirations() on missing file error = %v", err)
+	}
+	e.Set("FP_AAA", "prod", expires, nil)
+	if err := SaveExpirations(tempDir, e); err != nil {
+		t.Fatalf("SaveExpirations() error = %v", err)
+	}
+
+	loaded, err := LoadExpirations(tempDir)
+	if err != nil {
+		t.Fatalf("LoadExpirations() error = %v", err)
+	}
+	if len(loaded.Grants) != 1 || !loaded.Grants[0].Expires.Equal(expires) {
+		t.Errorf("loaded grants = %+v, want FP_AAA on prod expiring %v", loaded.Grants, expires)
+	}
+}
+
+func TestExpirations_KeepAndOverlapping(t *testing.T) {
+	expires := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	e := &Expirations{}
+	e.Set("FP_AAA", "prod", expires, []string{"prod/db/.gpg.id"})
+	e.Set("FP_AAA", "prod/db", expires, nil)
+	e.Set("FP_AAA", "dev", expires, nil)
+	e.Set("FP_BBB", "prod/db", expires, nil)
+
+	e.Set("FP_AAA", "prod", expires.Add(time.Hour), []string{"ignored"})
+	if got := e.Grants[0].Kept; !slices.Equal(got, []string{"prod/db/.gpg.id"}) {
+		t.Errorf("Kept after renewal = %v, want unchanged", got)
+	}
+
+	overlapping := e.Overlapping(e.Grants[0])
+	if len(overlapping) != 1 || overlapping[0].Path != "prod/db" || overlapping[0].Fingerprint != "FP_AAA" {
+		t.Errorf("Overlapping() = %+v, want FP_AAA on prod/db", overlapping)
+	}
+
+	e.Keep("FP_AAA", "prod/db/cache", []string{"prod/db/cache/.gpg.id"})
+	if got := e.Grants[0].Kept; !slices.Equal(got, []string{"prod/db/.gpg.id", "prod/db/cache/.gpg.id"}) {
+		t.Errorf("Kept on prod = %v", got)
+	}
+	if got := e.Grants[1].Kept; !slices.Equal(got, []string{"prod/db/cache/.gpg.id"}) {
+		t.Errorf("Kept on prod/db = %v", got)
+	}
+	if len(e.Grants[2].Kept) != 0 || len(e.Grants[3].Kept) != 0 {
+		t.Errorf("Keep() touched a grant on another path or fingerprint: %+v", e.Grants)
+	}
+}
+
+func TestCollectRecipientFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_ADMIN")
+	writeTestGpgID(t, filepath.Join(tempDir, "abc"), "FP_ADMIN", "FP_AAA")
+	if err := WriteSecretGpgID(tempDir, "s1", []string{"FP_ADMIN", "FP_AAA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	st := &Store{SecretsPath: tempDir}
+	var files []string
+	if err := st.collectRecipientFiles(tempDir, "FP_AAA", &files); err != nil {
+		t.Fatalf("collectRecipientFiles() error = %v", err)
+	}
+	slices.Sort(files)
+	want := []string{"abc/.gpg.id", "s1" + secretGpgIDSuffix}
+	if !slices.Equal(files, want) {
+		t.Errorf("collectRecipientFiles() = %v, want %v", files, want)
+	}
+
+	files = nil
+	if err := st.collectRecipientFiles(tempDir, "FP_BBB", &files); err != nil {
+		t.Fatalf("collectRecipientFiles() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("collectRecipientFiles() for an absent key = %v, want none", files)
+	}
+}
+
+func TestUpdateRecipients_Keep(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	db := filepath.Join(prod, "db")
+	writeTestGpgID(t, prod, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, db, "FP_AAA", "FP_BBB")
+	if err := WriteSecretGpgID(prod, "abc", []string{"FP_AAA", "FP_BBB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	st := &Store{SecretsPath: tempDir}
+	update := RecipientUpdate{
+		Remove: []string{"FP_BBB"},
+		Keep: map[string]bool{
+			filepath.Join(db, ".gpg.id"): true,
+			SecretGpgIDPath(prod, "abc"): true,
+		},
+	}
+	if err := st.updateRecipients(prod, "prod", update); err != nil {
+		t.Fatalf("updateRecipients() failed: %v", err)
+	}
+
+	if got, _ := ReadGpgID(prod); !slices.Equal(got, []string{"FP_AAA"}) {
+		t.Errorf("prod recipients = %v, want [FP_AAA]", got)
+	}
+	if got, _ := ReadGpgID(db); !slices.Equal(got, []string{"FP_AAA", "FP_BBB"}) {
+		t.Errorf("kept prod/db recipients = %v, want unchanged", got)
+	}
+	if got, _ := ReadSecretGpgID(prod, "abc"); !slices.Equal(got, []string{"FP_AAA", "FP_BBB"}) {
+		t.Errorf("kept override recipients = %v, want unchanged", got)
+	}
+}
@@ -16,13 +16,40 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 */
 package store
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
 func GenerateGitignore() string {
 	return `*
 !.gitignore
 !.gpg.id
 !*.gpg
+!*.gpg.id
 !keys/
 !keys/*.key
 !requests/
+!rejections/
+!approvals/
+!approvals/*.asc
+!policy.json
+!expirations.json
 `
 }
+
+// EnsureGitignore rewrites the store .gitignore when it predates patterns
+// that newer kepr versions rely on.
+func EnsureGitignore(secretsPath string) error {
+	gitignorePath := filepath.Join(secretsPath, ".gitignore")
+	current, err := os.ReadFile(gitignorePath)
+	if err == nil && string(current) == GenerateGitignore() {
+		return nil
+	}
+
+	if err := os.WriteFile(gitignorePath, []byte(GenerateGitignore()), 0600); err != nil {
+		return fmt.Errorf("failed to update .gitignore file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+// GroupPrefix marks a .gpg.id entry as a reference to a recipient group
+// stored under groups/<name>.gpg instead of a raw fingerprint.
+const GroupPrefix = "@"
+
+var (
+	ErrGroupNotFound    = errors.New("group not found")
+	ErrInvalidGroupName = errors.New("invalid group name")
+	ErrUnsignedGroup    = errors.New("group is not signed")
+	ErrUntrustedGroup   = errors.New("group is not signed by a root recipient")
+
+	groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+func IsGroupRef(entry string) bool {
+	return strings.HasPrefix(entry, GroupPrefix)
+}
+
+func GroupPath(secretsPath, name string) string {
+	return filepath.Join(secretsPath, "groups", name+".gpg")
+}
+
+func ValidateGroupName(name string) error {
+	if !groupNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidGroupName, name)
+	}
+	return nil
+}
+
+// ReadGroup returns the members of a group. The group must be signed by a
+// fingerprint listed directly in the root .gpg.id, so only admins can
+// change who a group grants access to.
+func (s *Store) ReadGroup(name string) ([]string, error) {
+	if err := ValidateGroupName(name); err != nil {
+		return nil, err
+	}
+
+	if members, ok := s.groups[name]; ok {
+		return members, nil
+	}
+
+	decrypted, err := s.decryptGroup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	signers, err := s.groupSigners()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := openGroup(s.gpg, name, decrypted, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.groups == nil {
+		s.groups = make(map[string][]string)
+	}
+	s.groups[name] = members
+	return members, nil
+}
+
+// ReadUnverifiedGroup returns the members of a group without checking its
+// signature, so an admin can review and re-sign a group written before
+// groups were signed.
+func (s *Store) ReadUnverifiedGroup(name string) ([]string, error) {
+	if err := ValidateGroupName(name); err != nil {
+		return nil, err
+	}
+
+	decrypted, err := s.decryptGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if text, err := gpg.ClearsignedText(decrypted); err == nil {
+		decrypted = text
+	}
+	return parseFingerprints(string(decrypted)), nil
+}
+
+func (s *Store) decryptGroup(name string) ([]byte, error) {
+	encrypted, err := os.ReadFile(GroupPath(s.SecretsPath, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read group %s: %w", name, err)
+	}
+
+	decrypted, err := s.gpg.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt group %s: %w", name, err)
+	}
+	return decrypted, nil
+}
+
+// RootFingerprints returns the fingerprints listed directly in the root
+// .gpg.id. Group references are left out, since only the admins and the
+// group's members can expand them.
+func RootFingerprints(secretsPath string) ([]string, error) {
+	entries, err := ReadGpgID(secretsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root recipients: %w", err)
+	}
+
+	var fingerprints []string
+	for _, entry := range entries {
+		if !IsGroupRef(entry) {
+			fingerprints = append(fingerprints, entry)
+		}
+	}
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("the root .gpg.id lists no fingerprint directly, only groups")
+	}
+	return fingerprints, nil
+}
+
+// RootAdmins returns the fingerprints listed directly in the root .gpg.id
+// and the members of the root groups this machine can read. As with commit
+// trust, admin groups it cannot decrypt or verify are skipped.
+func (s *Store) RootAdmins() ([]string, error) {
+	entries, err := ReadGpgID(s.SecretsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root recipients: %w", err)
+	}
+
+	var admins []string
+	for _, entry := range entries {
+		if !IsGroupRef(entry) {
+			admins = appendUnique(admins, entry)
+			continue
+		}
+		members, err := s.ReadGroup(strings.TrimPrefix(entry, GroupPrefix))
+		if err != nil {
+			slog.Debug("cannot expand admin group", "group", entry, "error", err)
+			continue
+		}
+		for _, fp := range members {
+			admins = appendUnique(admins, fp)
+		}
+	}
+	return admins, nil
+}
+
+// groupSigners returns the fingerprints listed directly in the root .gpg.id
+// and imports their public keys from keys/ so their signatures can be
+// checked.
+func (s *Store) groupSigners() (map[string]bool, error) {
+	if s.signers != nil {
+		return s.signers, nil
+	}
+
+	entries, err := ReadGpgID(s.SecretsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root recipients: %w", err)
+	}
+
+	signers := make(map[string]bool)
+	for _, entry := range entries {
+		if IsGroupRef(entry) {
+			continue
+		}
+		signers[entry] = true
+
+		key, err := os.ReadFile(KeyPath(s.SecretsPath, entry))
+		if err != nil {
+			continue
+		}
+		if err := s.gpg.ImportPublicKey(key); err != nil {
+			slog.Debug("failed to import root recipient key", "fingerprint", entry, "error", err)
+		}
+	}
+
+	s.signers = signers
+	return signers, nil
+}
+
+// openGroup checks the signature on a decrypted group file and returns the
+// members it lists.
+func openGroup(g *gpg.GPG, name string, decrypted []byte, signers map[string]bool) ([]string, error) {
+	if _, err := gpg.ClearsignedText(decrypted); err != nil {
+		return nil, fmt.Errorf("%w: @%s", ErrUnsignedGroup, name)
+	}
+
+	content, signer, err := g.Verify(decrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify group @%s: %w", name, err)
+	}
+	if !signers[signer] {
+		return nil, fmt.Errorf("%w: @%s was signed by %s", ErrUntrustedGroup, name, signer)
+	}
+
+	return parseFingerprints(string(content)), nil
+}
+
+// WriteGroup stores the group members signed by this admin and encrypted to
+// the root recipients and the members themselves, so that members can
+// expand the group when writing.
+func (s *Store) WriteGroup(name string, members []string) error {
+	if err := ValidateGroupName(name); err != nil {
+		return err
+	}
+
+	for _, fp := range members {
+		if IsGroupRef(fp) {
+			return fmt.Errorf("groups cannot contain other groups: %s", fp)
+		}
+	}
+
+	signers, err := s.groupSigners()
+	if err != nil {
+		return err
+	}
+	if !signers[s.Fingerprint] {
+		return fmt.Errorf("only fingerprints listed in the root .gpg.id can change groups")
+	}
+
+	rootRecipients, err := s.ReadRecipients(s.SecretsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read root recipients: %w", err)
+	}
+
+	recipients := rootRecipients
+	for _, fp := range members {
+		recipients = appendUnique(recipients, fp)
+	}
+
+	if err := s.gpg.RequireSigningKey(s.Fingerprint); err != nil {
+		return err
+	}
+	content := strings.Join(members, "\n") + "\n"
+	signed, err := s.gpg.Sign([]byte(content), s.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to sign group %s: %w", name, err)
+	}
+
+	encrypted, err := s.gpg.Encrypt(signed, recipients...)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt group %s: %w", name, err)
+	}
+
+	groupsDir := filepath.Join(s.SecretsPath, "groups")
+	if err := os.MkdirAll(groupsDir, 0700); err != nil {
+		return fmt.Errorf("failed to create groups directory: %w", err)
+	}
+
+	if err := os.WriteFile(GroupPath(s.SecretsPath, name), encrypted, 0600); err != nil {
+		return fmt.Errorf("failed to write group %s: %w", name, err)
+	}
+
+	if s.groups == nil {
+		s.groups = make(map[string][]string)
+	}
+	s.groups[name] = members
+	return nil
+}
+
+// ExpandRecipients replaces group references with the group members.
+func (s *Store) ExpandRecipients(entries []string) ([]string, error) {
+	var expanded []string
+	for _, entry := range entries {
+		if !IsGroupRef(entry) {
+			expanded = appendUnique(expanded, entry)
+			continue
+		}
+
+		members, err := s.ReadGroup(strings.TrimPrefix(entry, GroupPrefix))
+		if err != nil {
+			return nil, err
+		}
+		for _, fp := range members {
+			expanded = appendUnique(expanded, fp)
+		}
+	}
+
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("no fingerprints left after expanding groups")
+	}
+	return expanded, nil
+}
+
+// ReadRecipients reads .gpg.id and expands group references to fingerprints.
+func (s *Store) ReadRecipients(dirPath string) ([]string, error) {
+	entries, err := ReadGpgID(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	return s.ExpandRecipients(entries)
+}
+
+// GroupReferences returns every store directory whose .gpg.id, or one of
+// whose per-secret overrides, references the group.
+func (s *Store) GroupReferences(name string) ([]string, error) {
+	ref := GroupPrefix + name
+	var dirs []string
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(s.SecretsPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != s.SecretsPath && !isStoreDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".gpg.id") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Debug("failed to read recipients, skipping", "path", path, "error", err)
+			return nil
+		}
+		dir := filepath.Dir(path)
+		for _, entry := range parseFingerprints(string(data)) {
+			if entry == ref {
+				if !seen[dir] {
+					seen[dir] = true
+					dirs = append(dirs, dir)
+				}
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan store: %w", err)
+	}
+
+	return dirs, nil
+}
+
+// RekeyGroup re-encrypts every directory that references the group so the
+// files match the group's current members.
+func (s *Store) RekeyGroup(name string) error {
+	dirs, err := s.GroupReferences(name)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		recipients, err := s.ReadRecipients(dir)
+		if err != nil {
+			return fmt.Errorf("failed to expand recipients for %s: %w", dir, err)
+		}
+		slog.Debug("rekeying directory for group", "group", name, "path", dir, "recipients", recipients)
+		if err := s.reencryptFiles(dir, recipients, ""); err != nil {
+			return fmt.Errorf("failed to rekey %s: %w", dir, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateGroupName(t *testing.T) {
+	for _, name := range []string{"ops", "team-a", "on_call2"} {
+		if err := ValidateGroupName(name); err != nil {
+			t.Errorf("ValidateGroupName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "@ops", "../ops", "a b"} {
+		if err := ValidateGroupName(name); !errors.Is(err, ErrInvalidGroupName) {
+			t.Errorf("ValidateGroupName(%q) = %v, want ErrInvalidGroupName", name, err)
+		}
+	}
+}
+
+func TestExpandRecipients(t *testing.T) {
+	st := &Store{groups: map[string][]string{"ops": {"FP_BBB", "FP_CCC"}}}
+
+	got, err := st.ExpandRecipients([]string{"FP_AAA", "@ops", "FP_CCC"})
+	if err != nil {
+		t.Fatalf("ExpandRecipients() failed: %v", err)
+	}
+
+	want := []string{"FP_AAA", "FP_BBB", "FP_CCC"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandRecipients() = %v, want %v", got, want)
+	}
+}
+
+func TestExpandRecipients_MissingGroup(t *testing.T) {
+	st := &Store{SecretsPath: t.TempDir()}
+
+	_, err := st.ExpandRecipients([]string{"FP_AAA", "@ops"})
+	if !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("ExpandRecipients() = %v, want ErrGroupNotFound", err)
+	}
+}
+
+func TestRootFingerprints(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "@ops", "FP_AAA", "FP_BBB")
+
+	got, err := RootFingerprints(tempDir)
+	if err != nil {
+		t.Fatalf("RootFingerprints() failed: %v", err)
+	}
+	want := []string{"FP_AAA", "FP_BBB"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RootFingerprints() = %v, want %v", got, want)
+	}
+
+	writeTestGpgID(t, tempDir, "@ops")
+	if _, err := RootFingerprints(tempDir); err == nil {
+		t.Error("RootFingerprints() with only a group in the root succeeded, want an error")
+	}
+}
+
+func TestRootAdmins(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_AAA", "@ops", "@unreadable")
+	st := &Store{SecretsPath: tempDir, groups: map[string][]string{"ops": {"FP_BBB", "FP_AAA"}}}
+
+	got, err := st.RootAdmins()
+	if err != nil {
+		t.Fatalf("RootAdmins() failed: %v", err)
+	}
+	want := []string{"FP_AAA", "FP_BBB"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RootAdmins() = %v, want %v", got, want)
+	}
+}
+
+func TestHasAccess_ThroughGroup(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteGpgID(dir, []string{"FP_AAA", "@ops"}); err != nil {
+		t.Fatalf("failed to write .gpg.id: %v", err)
+	}
+	st := &Store{Fingerprint: "FP_BBB", groups: map[string][]string{"ops": {"FP_BBB"}}}
+	if !st.hasAccess(dir) {
+		t.Error("hasAccess should return true when fingerprint is a member of a referenced group")
+	}
+}
+
+func TestGroupReferences(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_AAA")
+	writeTestGpgID(t, filepath.Join(tempDir, "u1"), "FP_AAA", "@ops")
+	writeTestGpgID(t, filepath.Join(tempDir, "u1", "u2"), "@ops")
+	writeTestGpgID(t, filepath.Join(tempDir, "u3"), "@dev")
+
+	st := &Store{SecretsPath: tempDir}
+	dirs, err := st.GroupReferences("ops")
+	if err != nil {
+		t.Fatalf("GroupReferences() failed: %v", err)
+	}
+
+	want := []string{filepath.Join(tempDir, "u1"), filepath.Join(tempDir, "u1", "u2")}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("GroupReferences() = %v, want %v", dirs, want)
+	}
+}
+
+func TestOpenGroup_Unsigned(t *testing.T) {
+	_, err := openGroup(nil, "ops", []byte("FP_AAA\nFP_BBB\n"), map[string]bool{"FP_AAA": true})
+	if !errors.Is(err, ErrUnsignedGroup) {
+		t.Errorf("openGroup() = %v, want ErrUnsignedGroup", err)
+	}
+}
+
+func TestWriteGroup_NotRootRecipient(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_AAA", "@admins")
+
+	st := &Store{SecretsPath: tempDir, Fingerprint: "FP_BBB"}
+	if err := st.WriteGroup("ops", []string{"FP_CCC"}); err == nil {
+		t.Error("WriteGroup() by a key outside the root .gpg.id should fail")
+	}
+}
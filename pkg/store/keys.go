@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+func KeyPath(secretsPath, fingerprint string) string {
+	return filepath.Join(secretsPath, "keys", fingerprint+".key")
+}
+
+func SavePublicKey(secretsPath string, g *gpg.GPG, fingerprint string) error {
+	keysDir := filepath.Join(secretsPath, "keys")
+	if err := os.MkdirAll(keysDir, 0700); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+
+	pubKey, err := g.ExportPublicKey(fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to export public key: %w", err)
+	}
+
+	if err := os.WriteFile(KeyPath(secretsPath, fingerprint), pubKey, 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RekeyPlanEntry describes what a recipient update would do to one
+// directory, without changing anything.
+type RekeyPlanEntry struct {
+	Path    string
+	Current []string
+	Updated []string
+	// Secrets is the number of secrets stored directly in the directory.
+	Secrets int
+	// Reencrypt is set when the directory's files would be re-encrypted.
+	Reencrypt bool
+	// Differs is set for sub-folders whose current recipients are not the
+	// same as the target directory's.
+	Differs bool
+}
+
+// PlanRecipientUpdate walks the tree UpdateRecipients would touch and reports
+// the recipient change for every directory in it.
+func (s *Store) PlanRecipientUpdate(logicalPath string, update RecipientUpdate) ([]RekeyPlanEntry, error) {
+	targetDir, err := s.ResolvePath(logicalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %q: %w", logicalPath, err)
+	}
+
+	target, err := ReadGpgID(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing fingerprints: %w", err)
+	}
+
+	var plan []RekeyPlanEntry
+	if err := s.planRecipientUpdate(targetDir, logicalPath, target, update, &plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *Store) planRecipientUpdate(dirPath, logicalPath string, target []string, update RecipientUpdate, plan *[]RekeyPlanEntry) error {
+	existing, err := ReadGpgID(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read existing fingerprints: %w", err)
+	}
+
+	updated := update.apply(existing)
+	if update.Flatten {
+		updated = update.apply(target)
+	}
+	if len(updated) == 0 {
+		return fmt.Errorf("refusing to remove every recipient from %s", logicalPath)
+	}
+
+	overridesChanged := false
+	for _, override := range secretOverrides(dirPath) {
+		if !sameFingerprints(override, update.apply(override)) {
+			overridesChanged = true
+		}
+	}
+
+	secrets, err := countSecrets(dirPath)
+	if err != nil {
+		return err
+	}
+
+	*plan = append(*plan, RekeyPlanEntry{
+		Path:      DisplayPath(logicalPath),
+		Current:   existing,
+		Updated:   updated,
+		Secrets:   secrets,
+		Reencrypt: update.Flatten || overridesChanged || !sameFingerprints(existing, updated),
+		Differs:   !sameFingerprints(existing, target),
+	})
+
+	subDirs, err := rekeySubdirs(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, subDir := range subDirs {
+		name := filepath.Base(subDir)
+		subLogicalPath := s.resolveSubdirLogicalPath(subDir, name, logicalPath)
+		if err := s.planRecipientUpdate(subDir, subLogicalPath, target, update, plan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countSecrets(dirPath string) (int, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	count := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".gpg") || strings.HasSuffix(name, "_md.gpg") {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanRecipientUpdate(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	payments := filepath.Join(prod, "payments")
+	writeTestGpgID(t, prod, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, payments, "FP_AAA")
+	if err := os.WriteFile(filepath.Join(prod, "secret.gpg"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	st := &Store{SecretsPath: tempDir}
+	var plan []RekeyPlanEntry
+	target := []string{"FP_AAA", "FP_BBB"}
+	if err := st.planRecipientUpdate(prod, "prod", target, RecipientUpdate{Add: []string{"FP_CCC"}}, &plan); err != nil {
+		t.Fatalf("planRecipientUpdate() failed: %v", err)
+	}
+
+	if len(plan) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %+v", len(plan), plan)
+	}
+	if plan[0].Secrets != 1 || !plan[0].Reencrypt || plan[0].Differs {
+		t.Errorf("unexpected prod entry: %+v", plan[0])
+	}
+	if !plan[1].Differs {
+		t.Error("expected payments to be reported as having different recipients")
+	}
+	if want := []string{"FP_AAA", "FP_CCC"}; !reflect.DeepEqual(plan[1].Updated, want) {
+		t.Errorf("payments updated = %v, want %v", plan[1].Updated, want)
+	}
+
+	got, _ := ReadGpgID(payments)
+	if !reflect.DeepEqual(got, []string{"FP_AAA"}) {
+		t.Errorf("planning changed payments recipients to %v", got)
+	}
+}
+
+func TestPlanRecipientUpdate_Flatten(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	payments := filepath.Join(prod, "payments")
+	writeTestGpgID(t, prod, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, payments, "FP_AAA")
+
+	st := &Store{SecretsPath: tempDir}
+	var plan []RekeyPlanEntry
+	target := []string{"FP_AAA", "FP_BBB"}
+	if err := st.planRecipientUpdate(prod, "prod", target, RecipientUpdate{Add: []string{"FP_CCC"}, Flatten: true}, &plan); err != nil {
+		t.Fatalf("planRecipientUpdate() failed: %v", err)
+	}
+
+	if want := []string{"FP_AAA", "FP_BBB", "FP_CCC"}; !reflect.DeepEqual(plan[1].Updated, want) {
+		t.Errorf("payments updated = %v, want %v", plan[1].Updated, want)
+	}
+}
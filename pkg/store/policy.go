@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+const PolicyFile = "policy.json"
+
+// QuorumRule requires Approvals distinct admin approvals for access requests
+// at or below Path.
+type QuorumRule struct {
+	Path      string `json:"path"`
+	Approvals int    `json:"approvals"`
+}
+
+type Policy struct {
+	Quorum []QuorumRule `json:"quorum,omitempty"`
+}
+
+func LoadPolicy(secretsPath string) (*Policy, error) {
+	data, err := os.ReadFile(filepath.Join(secretsPath, PolicyFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Policy{}, nil
+		}
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy parses the content of policy.json. Empty data is the empty
+// policy, as when the file does not exist.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if len(data) == 0 {
+		return &p, nil
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return &p, nil
+}
+
+// MarshalPolicy returns the content SavePolicy writes for p.
+func MarshalPolicy(p *Policy) ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func SavePolicy(secretsPath string, p *Policy) error {
+	data, err := MarshalPolicy(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(secretsPath, PolicyFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write policy: %w", err)
+	}
+
+	return EnsureGitignore(secretsPath)
+}
+
+// RequiredApprovals returns the quorum for granting access to path: the
+// strictest rule covering path or any directory below it, since a grant
+// reaches the whole subtree. It is 1 when no rule applies.
+func (p *Policy) RequiredApprovals(path string) int {
+	required := 1
+	for _, rule := range p.Quorum {
+		if !PathWithin(path, rule.Path) && !PathWithin(rule.Path, path) {
+			continue
+		}
+		required = max(required, rule.Approvals)
+	}
+	return required
+}
+
+// ChangeApprovals returns how many admins must approve a change to the
+// policy itself: the strictest rule anywhere in the store.
+func (p *Policy) ChangeApprovals() int {
+	return p.RequiredApprovals("")
+}
+
+// PolicyChangeID identifies a proposed policy.json content. Approvals of a
+// policy change are stored in approvals/ under this ID, with PolicyFile as
+// their path.
+func PolicyChangeID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "policy-" + hex.EncodeToString(sum[:])
+}
+
+// CheckPolicyChange returns an error when policy.json went from oldData to
+// newData without approvals from as many admins as the old policy requires.
+func CheckPolicyChange(g *gpg.GPG, oldData, newData []byte, approvals map[string][]byte, admins []string) error {
+	old, err := ParsePolicy(oldData)
+	if err != nil {
+		return err
+	}
+
+	required := old.ChangeApprovals()
+	if required <= 1 {
+		return nil
+	}
+
+	approvers := VerifiedApprovers(g, approvals, PolicyChangeID(newData), PolicyFile, admins)
+	if len(approvers) < required {
+		return fmt.Errorf("%s was changed with %d of %d required approvals", PolicyFile, len(approvers), required)
+	}
+	return nil
+}
+
+func (p *Policy) SetQuorum(path string, approvals int) {
+	for i, rule := range p.Quorum {
+		if rule.Path == path {
+			p.Quorum[i].Approvals = approvals
+			return
+		}
+	}
+	p.Quorum = append(p.Quorum, QuorumRule{Path: path, Approvals: approvals})
+}
+
+// PathWithin reports whether path equals parent or lies below it.
+func PathWithin(path, parent string) bool {
+	path = strings.Trim(path, "/")
+	parent = strings.Trim(parent, "/")
+	if parent == "" || path == parent {
+		return true
+	}
+	return strings.HasPrefix(path, parent+"/")
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicy_RequiredApprovals(t *testing.T) {
+	p := &Policy{Quorum: []QuorumRule{
+		{Path: "prod", Approvals: 2},
+		{Path: "prod/payments", Approvals: 3},
+		{Path: "prod/sandbox", Approvals: 1},
+	}}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"", 3},
+		{"dev", 1},
+		{"prod", 3},
+		{"prod/app", 2},
+		{"prod/sandbox", 2},
+		{"prod/payments", 3},
+		{"prod/payments/stripe", 3},
+		{"production", 1},
+	}
+
+	for _, tt := range tests {
+		if got := p.RequiredApprovals(tt.path); got != tt.want {
+			t.Errorf("RequiredApprovals(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPolicy_SaveAndLoad(t *testing.T) {
+	tempDir := t.TempDir()
+
+	p, err := LoadPolicy(tempDir)
+	if err != nil {
+		t.Fatalf("LoadPolicy() on missing file error = %v", err)
+	}
+	if got := p.RequiredApprovals("prod"); got != 1 {
+		t.Errorf("RequiredApprovals() with no policy = %d, want 1", got)
+	}
+
+	p.SetQuorum("prod", 2)
+	p.SetQuorum("prod", 3)
+	if err := SavePolicy(tempDir, p); err != nil {
+		t.Fatalf("SavePolicy() error = %v", err)
+	}
+
+	loaded, err := LoadPolicy(tempDir)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if len(loaded.Quorum) != 1 || loaded.RequiredApprovals("prod") != 3 {
+		t.Errorf("loaded policy = %+v, want a single prod rule of 3", loaded.Quorum)
+	}
+
+	gitignore, err := os.ReadFile(filepath.Join(tempDir, ".gitignore"))
+	if err != nil {
+		t.Fatalf("failed to read .gitignore: %v", err)
+	}
+	if !strings.Contains(string(gitignore), "!"+PolicyFile) {
+		t.Errorf(".gitignore does not track %s", PolicyFile)
+	}
+}
+
+func TestPolicy_ChangeApprovals(t *testing.T) {
+	p := &Policy{Quorum: []QuorumRule{
+		{Path: "dev", Approvals: 1},
+		{Path: "prod/payments", Approvals: 3},
+	}}
+	if got := p.ChangeApprovals(); got != 3 {
+		t.Errorf("ChangeApprovals() = %d, want 3", got)
+	}
+	if got := (&Policy{}).ChangeApprovals(); got != 1 {
+		t.Errorf("ChangeApprovals() with no rules = %d, want 1", got)
+	}
+}
+
+func TestPolicyChangeID(t *testing.T) {
+	a := PolicyChangeID([]byte(`{"quorum":[{"path":"prod","approvals":2}]}`))
+	b := PolicyChangeID([]byte(`{"quorum":[{"path":"prod","approvals":1}]}`))
+	if a == b {
+		t.Error("PolicyChangeID() is the same for different policies")
+	}
+	if !strings.HasPrefix(a, "policy-") {
+		t.Errorf("PolicyChangeID() = %q, want a policy- prefix", a)
+	}
+}
+
+func TestCheckPolicyChange(t *testing.T) {
+	strict, err := MarshalPolicy(&Policy{Quorum: []QuorumRule{{Path: "prod", Approvals: 2}}})
+	if err != nil {
+		t.Fatalf("MarshalPolicy() error = %v", err)
+	}
+	loose, err := MarshalPolicy(&Policy{Quorum: []QuorumRule{{Path: "prod", Approvals: 1}}})
+	if err != nil {
+		t.Fatalf("MarshalPolicy() error = %v", err)
+	}
+
+	if err := CheckPolicyChange(nil, nil, strict, nil, []string{"ADMIN1"}); err != nil {
+		t.Errorf("CheckPolicyChange() adding the first rule error = %v", err)
+	}
+	if err := CheckPolicyChange(nil, strict, loose, nil, []string{"ADMIN1"}); err == nil {
+		t.Error("CheckPolicyChange() loosened a 2-approval policy without approvals")
+	}
+	if err := CheckPolicyChange(nil, strict, nil, nil, []string{"ADMIN1"}); err == nil {
+		t.Error("CheckPolicyChange() deleted a 2-approval policy without approvals")
+	}
+
+	forged := map[string][]byte{ApprovalFileName(PolicyChangeID(loose), "ADMIN1"): []byte("not signed")}
+	if err := CheckPolicyChange(nil, strict, loose, forged, []string{"ADMIN1"}); err == nil {
+		t.Error("CheckPolicyChange() accepted an unsigned approval")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// StaleRequest is a pending request that should be pruned, with the reason.
+type StaleRequest struct {
+	Request PendingRequest
+	Reason  string
+}
+
+// FindStaleRequests picks the requests to prune: requests that failed
+// verification, all but the newest verified request for each fingerprint and
+// path, and, when olderThan is set, any request made before now minus
+// olderThan. Requests without a usable timestamp are never considered stale.
+// Unverified requests are kept out of the grouping so a forged fingerprint
+// cannot supersede a genuine request.
+func FindStaleRequests(requests []PendingRequest, olderThan time.Duration, now time.Time) []StaleRequest {
+	type group struct {
+		fingerprint string
+		path        string
+	}
+
+	groups := make(map[group][]PendingRequest)
+	for _, r := range requests {
+		if r.Invalid != "" || r.Fingerprint == "" {
+			continue
+		}
+		key := group{r.Fingerprint, r.Path}
+		groups[key] = append(groups[key], r)
+	}
+
+	duplicates := make(map[string]string)
+	for _, reqs := range groups {
+		if len(reqs) < 2 {
+			continue
+		}
+		sort.SliceStable(reqs, func(i, j int) bool {
+			return requestTime(reqs[i]).After(requestTime(reqs[j]))
+		})
+		for _, r := range reqs[1:] {
+			duplicates[r.UUID] = "superseded by " + reqs[0].UUID
+		}
+	}
+
+	var stale []StaleRequest
+	for _, r := range requests {
+		if r.Invalid != "" {
+			stale = append(stale, StaleRequest{Request: r, Reason: "invalid: " + r.Invalid})
+			continue
+		}
+		if reason, ok := duplicates[r.UUID]; ok {
+			stale = append(stale, StaleRequest{Request: r, Reason: reason})
+			continue
+		}
+		if olderThan <= 0 {
+			continue
+		}
+		if t := requestTime(r); !t.IsZero() && t.Before(now.Add(-olderThan)) {
+			stale = append(stale, StaleRequest{Request: r, Reason: "older than " + olderThan.String()})
+		}
+	}
+
+	return stale
+}
+
+func requestTime(r PendingRequest) time.Time {
+	t, err := time.Parse(time.RFC3339, r.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
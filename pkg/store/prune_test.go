@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindStaleRequests_Duplicates(t *testing.T) {
+	requests := []PendingRequest{
+		{UUID: "old", Fingerprint: "FP_A", Path: "prod", Timestamp: "2025-01-01T00:00:00Z"},
+		{UUID: "new", Fingerprint: "FP_A", Path: "prod", Timestamp: "2025-01-03T00:00:00Z"},
+		{UUID: "other-path", Fingerprint: "FP_A", Path: "dev", Timestamp: "2025-01-01T00:00:00Z"},
+		{UUID: "other-fp", Fingerprint: "FP_B", Path: "prod", Timestamp: "2025-01-01T00:00:00Z"},
+	}
+
+	stale := FindStaleRequests(requests, 0, time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC))
+	if len(stale) != 1 {
+		t.Fatalf("expected 1 stale request, got %d: %+v", len(stale), stale)
+	}
+	if stale[0].Request.UUID != "old" {
+		t.Errorf("pruned %s, want old", stale[0].Request.UUID)
+	}
+	if stale[0].Reason != "superseded by new" {
+		t.Errorf("Reason = %q", stale[0].Reason)
+	}
+}
+
+func TestFindStaleRequests_InvalidNotGrouped(t *testing.T) {
+	requests := []PendingRequest{
+		{UUID: "genuine", Fingerprint: "FP_A", Path: "prod", Timestamp: "2025-01-01T00:00:00Z"},
+		{UUID: "forged", Fingerprint: "FP_A", Path: "prod", Timestamp: "2025-01-03T00:00:00Z", Invalid: "request is not signed"},
+	}
+
+	stale := FindStaleRequests(requests, 0, time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC))
+	if len(stale) != 1 {
+		t.Fatalf("expected 1 stale request, got %d: %+v", len(stale), stale)
+	}
+	if stale[0].Request.UUID != "forged" {
+		t.Errorf("pruned %s, want forged", stale[0].Request.UUID)
+	}
+	if stale[0].Reason != "invalid: request is not signed" {
+		t.Errorf("Reason = %q", stale[0].Reason)
+	}
+}
+
+func TestFindStaleRequests_OlderThan(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	requests := []PendingRequest{
+		{UUID: "stale", Fingerprint: "FP_A", Path: "prod", Timestamp: "2025-01-01T00:00:00Z"},
+		{UUID: "recent", Fingerprint: "FP_B", Path: "prod", Timestamp: "2025-01-09T00:00:00Z"},
+		{UUID: "undated", Fingerprint: "FP_C", Path: "prod"},
+	}
+
+	stale := FindStaleRequests(requests, 7*24*time.Hour, now)
+	if len(stale) != 1 || stale[0].Request.UUID != "stale" {
+		t.Fatalf("expected only stale to be pruned, got %+v", stale)
+	}
+
+	if stale := FindStaleRequests(requests, 0, now); len(stale) != 0 {
+		t.Errorf("expected nothing pruned without an age limit, got %+v", stale)
+	}
+}
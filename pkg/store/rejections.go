@@ -0,0 +1,144 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+const RejectionsDir = "rejections"
+
+// Rejection tells a requester that an admin denied their access request. It
+// is signed by the admin and encrypted to the requester only.
+type Rejection struct {
+	RequestUUID string `json:"request_uuid"`
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+	Reason      string `json:"reason,omitempty"`
+	RejectedBy  string `json:"rejected_by"`
+	// Signer is the fingerprint of the admin who signed the rejection.
+	Signer    string `json:"signer"`
+	Timestamp string `json:"timestamp"`
+}
+
+func WriteRejection(secretsPath string, g *gpg.GPG, r Rejection) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rejection: %w", err)
+	}
+
+	signed, err := g.Sign(append(data, '\n'), r.Signer)
+	if err != nil {
+		return fmt.Errorf("failed to sign rejection: %w", err)
+	}
+
+	encrypted, err := g.Encrypt(signed, r.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt rejection: %w", err)
+	}
+
+	dir := filepath.Join(secretsPath, RejectionsDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create rejections directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, r.RequestUUID+".json.gpg"), encrypted, 0600); err != nil {
+		return fmt.Errorf("failed to write rejection: %w", err)
+	}
+
+	return EnsureGitignore(secretsPath)
+}
+
+// ListRejections returns the rejections addressed to fingerprint. Records
+// encrypted to other requesters, and records not signed by an admin as
+// returned by RootAdmins, are skipped.
+func ListRejections(secretsPath string, g *gpg.GPG, fingerprint string) ([]Rejection, error) {
+	dir := filepath.Join(secretsPath, RejectionsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read rejections directory: %w", err)
+	}
+
+	s, err := New(secretsPath, g, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	admins, err := s.RootAdmins()
+	if err != nil {
+		return nil, err
+	}
+	for _, fp := range admins {
+		if keyData, err := os.ReadFile(KeyPath(secretsPath, fp)); err == nil {
+			if err := g.ImportPublicKey(keyData); err != nil {
+				slog.Debug("failed to import admin key", "fingerprint", fp, "error", err)
+			}
+		}
+	}
+
+	var rejections []Rejection
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json.gpg") {
+			continue
+		}
+
+		encrypted, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rejection %s: %w", entry.Name(), err)
+		}
+
+		decrypted, err := g.Decrypt(encrypted)
+		if err != nil {
+			slog.Debug("skipping rejection for another requester", "file", entry.Name())
+			continue
+		}
+
+		content, signer, err := g.Verify(decrypted)
+		if err != nil {
+			slog.Debug("skipping unsigned rejection", "file", entry.Name(), "error", err)
+			continue
+		}
+
+		var r Rejection
+		if err := json.Unmarshal(content, &r); err != nil {
+			return nil, fmt.Errorf("failed to parse rejection %s: %w", entry.Name(), err)
+		}
+		if signer != r.Signer || !slices.Contains(admins, signer) {
+			slog.Debug("skipping rejection not signed by an admin", "file", entry.Name(), "signer", signer)
+			continue
+		}
+		if r.Fingerprint == fingerprint {
+			rejections = append(rejections, r)
+		}
+	}
+
+	sort.Slice(rejections, func(i, j int) bool {
+		return rejections[i].Timestamp < rejections[j].Timestamp
+	})
+	return rejections, nil
+}
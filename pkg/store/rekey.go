@@ -43,6 +43,111 @@ func (s *Store) ResolvePath(path string) (string, error) {
 	return currentPath, nil
 }
 
+// RecipientUpdate describes a change to the recipients of a directory tree.
+// By default each directory keeps its own recipient set and only the listed
+// fingerprints are added or removed. Flatten replaces every directory in the
+// tree with the target directory's updated set.
+type RecipientUpdate struct {
+	Add     []string
+	Remove  []string
+	Flatten bool
+	// Keep holds recipient files, by absolute path, that the update leaves
+	// as they are.
+	Keep map[string]bool
+}
+
+func (u RecipientUpdate) apply(existing []string) []string {
+	removed := make(map[string]bool, len(u.Remove))
+	for _, fp := range u.Remove {
+		removed[fp] = true
+	}
+
+	var updated []string
+	for _, fp := range existing {
+		if !removed[fp] {
+			updated = appendUnique(updated, fp)
+		}
+	}
+	for _, fp := range u.Add {
+		updated = appendUnique(updated, fp)
+	}
+	return updated
+}
+
+func (s *Store) AddRecipient(logicalPath, fingerprint string, flatten bool) error {
+	return s.UpdateRecipients(logicalPath, RecipientUpdate{Add: []string{fingerprint}, Flatten: flatten})
+}
+
+func (s *Store) UpdateRecipients(logicalPath string, update RecipientUpdate) error {
+	targetDir, err := s.ResolvePath(logicalPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", logicalPath, err)
+	}
+
+	return s.updateRecipients(targetDir, logicalPath, update)
+}
+
+func (s *Store) updateRecipients(dirPath, logicalPath string, update RecipientUpdate) error {
+	existingFingerprints, err := ReadGpgID(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read existing fingerprints: %w", err)
+	}
+
+	updatedFingerprints := update.apply(existingFingerprints)
+	if update.Keep[filepath.Join(dirPath, ".gpg.id")] {
+		updatedFingerprints = existingFingerprints
+	}
+	if len(updatedFingerprints) == 0 {
+		return fmt.Errorf("refusing to remove every recipient from %s", logicalPath)
+	}
+
+	if update.Flatten {
+		if err := applyOverrideUpdateTree(dirPath, update); err != nil {
+			return err
+		}
+		return s.Rekey(dirPath, updatedFingerprints, logicalPath)
+	}
+
+	overridesChanged, err := applyOverrideUpdate(dirPath, update)
+	if err != nil {
+		return err
+	}
+
+	if overridesChanged || !sameFingerprints(existingFingerprints, updatedFingerprints) {
+		slog.Debug("updating directory recipients", "path", dirPath, "logicalPath", logicalPath, "recipients", updatedFingerprints)
+		if err := WriteGpgID(dirPath, updatedFingerprints); err != nil {
+			return fmt.Errorf("failed to write .gpg.id: %w", err)
+		}
+		if err := s.reencryptFiles(dirPath, updatedFingerprints, logicalPath); err != nil {
+			return err
+		}
+	}
+
+	subDirs, err := rekeySubdirs(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, subDir := range subDirs {
+		name := filepath.Base(subDir)
+		subLogicalPath := s.resolveSubdirLogicalPath(subDir, name, logicalPath)
+		if err := s.updateRecipients(subDir, subLogicalPath, update); err != nil {
+			return fmt.Errorf("failed to rekey subdirectory %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func appendUnique(fingerprints []string, fingerprint string) []string {
+	for _, fp := range fingerprints {
+		if fp == fingerprint {
+			return fingerprints
+		}
+	}
+	return append(fingerprints, fingerprint)
+}
+
 func (s *Store) Rekey(dirPath string, updatedFingerprints []string, logicalPath string) error {
 	slog.Debug("rekeying directory", "path", dirPath, "logicalPath", logicalPath, "recipients", updatedFingerprints)
 
@@ -50,30 +155,69 @@ func (s *Store) Rekey(dirPath string, updatedFingerprints []string, logicalPath
 		return fmt.Errorf("failed to write .gpg.id: %w", err)
 	}
 
-	dirName := filepath.Base(dirPath)
+	subDirs, err := rekeySubdirs(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, subDir := range subDirs {
+		name := filepath.Base(subDir)
+		subLogicalPath := s.resolveSubdirLogicalPath(subDir, name, logicalPath)
+		if err := s.Rekey(subDir, updatedFingerprints, subLogicalPath); err != nil {
+			return fmt.Errorf("failed to rekey subdirectory %s: %w", name, err)
+		}
+	}
+
+	if err := s.reencryptFiles(dirPath, updatedFingerprints, logicalPath); err != nil {
+		return err
+	}
 
+	slog.Debug("rekeying complete", "path", dirPath)
+	return nil
+}
+
+func rekeySubdirs(dirPath string) ([]string, error) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
+		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
+	var subDirs []string
 	for _, entry := range entries {
-		if entry.IsDir() {
-			subDir := filepath.Join(dirPath, entry.Name())
-			gpgIDPath := filepath.Join(subDir, ".gpg.id")
-			if _, err := os.Stat(gpgIDPath); err != nil {
-				continue
-			}
-
-			subLogicalPath := s.resolveSubdirLogicalPath(subDir, entry.Name(), logicalPath)
-			if err := s.Rekey(subDir, updatedFingerprints, subLogicalPath); err != nil {
-				return fmt.Errorf("failed to rekey subdirectory %s: %w", entry.Name(), err)
-			}
+		if !entry.IsDir() {
+			continue
+		}
+		subDir := filepath.Join(dirPath, entry.Name())
+		if _, err := os.Stat(filepath.Join(subDir, ".gpg.id")); err != nil {
 			continue
 		}
+		subDirs = append(subDirs, subDir)
+	}
+	return subDirs, nil
+}
+
+func (s *Store) reencryptFiles(dirPath string, entries []string, logicalPath string) error {
+	dirName := filepath.Base(dirPath)
 
+	fingerprints, err := s.ExpandRecipients(entries)
+	if err != nil {
+		return fmt.Errorf("failed to expand recipients: %w", err)
+	}
+
+	overrides := secretOverrides(dirPath)
+	metadataRecipients, err := s.dirMetadataRecipients(fingerprints, overrides)
+	if err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range files {
 		name := entry.Name()
-		if !strings.HasSuffix(name, ".gpg") {
+		if entry.IsDir() || !strings.HasSuffix(name, ".gpg") {
 			continue
 		}
 
@@ -102,7 +246,17 @@ func (s *Store) Rekey(dirPath string, updatedFingerprints []string, logicalPath
 			}
 		}
 
-		reencrypted, err := s.gpg.Encrypt(decrypted, updatedFingerprints...)
+		recipients := fingerprints
+		if isDirMetadata {
+			recipients = metadataRecipients
+		} else if override, ok := overrides[secretUUID(name)]; ok {
+			recipients, err = s.ExpandRecipients(override)
+			if err != nil {
+				return fmt.Errorf("failed to expand recipients for %s: %w", name, err)
+			}
+		}
+
+		reencrypted, err := s.gpg.Encrypt(decrypted, recipients...)
 		if err != nil {
 			return fmt.Errorf("failed to re-encrypt %s: %w", name, err)
 		}
@@ -112,10 +266,16 @@ func (s *Store) Rekey(dirPath string, updatedFingerprints []string, logicalPath
 		}
 	}
 
-	slog.Debug("rekeying complete", "path", dirPath)
 	return nil
 }
 
+func secretUUID(fileName string) string {
+	if strings.HasSuffix(fileName, "_md.gpg") {
+		return strings.TrimSuffix(fileName, "_md.gpg")
+	}
+	return strings.TrimSuffix(fileName, ".gpg")
+}
+
 func (s *Store) resolveSubdirLogicalPath(subDir string, uuid string, parentLogicalPath string) string {
 	metadataPath := filepath.Join(subDir, uuid+"_md.gpg")
 	encrypted, err := os.ReadFile(metadataPath)
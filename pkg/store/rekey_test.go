@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecipientUpdate_Apply(t *testing.T) {
+	u := RecipientUpdate{Add: []string{"FP_CCC", "FP_AAA"}, Remove: []string{"FP_BBB"}}
+	got := u.apply([]string{"FP_AAA", "FP_BBB"})
+	want := []string{"FP_AAA", "FP_CCC"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateRecipients_PreservesNarrowerChildren(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	app := filepath.Join(prod, "app")
+	payments := filepath.Join(prod, "payments")
+	writeTestGpgID(t, prod, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, app, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, payments, "FP_AAA")
+
+	st := &Store{SecretsPath: tempDir}
+	if err := st.updateRecipients(prod, "prod", RecipientUpdate{Add: []string{"FP_CCC"}}); err != nil {
+		t.Fatalf("updateRecipients() failed: %v", err)
+	}
+
+	expected := map[string][]string{
+		prod:     {"FP_AAA", "FP_BBB", "FP_CCC"},
+		app:      {"FP_AAA", "FP_BBB", "FP_CCC"},
+		payments: {"FP_AAA", "FP_CCC"},
+	}
+	for dir, want := range expected {
+		got, err := ReadGpgID(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s recipients = %v, want %v", filepath.Base(dir), got, want)
+		}
+	}
+}
+
+func TestUpdateRecipients_Remove(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	payments := filepath.Join(prod, "payments")
+	writeTestGpgID(t, prod, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, payments, "FP_AAA")
+
+	st := &Store{SecretsPath: tempDir}
+	if err := st.updateRecipients(prod, "prod", RecipientUpdate{Remove: []string{"FP_BBB"}}); err != nil {
+		t.Fatalf("updateRecipients() failed: %v", err)
+	}
+
+	got, _ := ReadGpgID(prod)
+	if !reflect.DeepEqual(got, []string{"FP_AAA"}) {
+		t.Errorf("prod recipients = %v, want [FP_AAA]", got)
+	}
+	got, _ = ReadGpgID(payments)
+	if !reflect.DeepEqual(got, []string{"FP_AAA"}) {
+		t.Errorf("payments recipients = %v, want [FP_AAA]", got)
+	}
+}
+
+func TestUpdateRecipients_RefusesEmptyRecipients(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestGpgID(t, tempDir, "FP_AAA")
+
+	st := &Store{SecretsPath: tempDir}
+	if err := st.updateRecipients(tempDir, "prod", RecipientUpdate{Remove: []string{"FP_AAA"}}); err == nil {
+		t.Fatal("expected error when removing the last recipient")
+	}
+}
+
+func TestUpdateRecipients_Flatten(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	payments := filepath.Join(prod, "payments")
+	writeTestGpgID(t, prod, "FP_AAA", "FP_BBB")
+	writeTestGpgID(t, payments, "FP_AAA")
+
+	st := &Store{SecretsPath: tempDir}
+	if err := st.updateRecipients(prod, "prod", RecipientUpdate{Add: []string{"FP_CCC"}, Flatten: true}); err != nil {
+		t.Fatalf("updateRecipients() failed: %v", err)
+	}
+
+	got, _ := ReadGpgID(payments)
+	want := []string{"FP_AAA", "FP_BBB", "FP_CCC"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payments recipients = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// An exported request is the armored, encrypted request preceded by a short
+// plain-text header naming its UUID, so it can be carried to an admin by
+// hand and imported without a request branch.
+
+const (
+	requestExportTitle  = "kepr access request"
+	requestExportUUID   = "UUID: "
+	armoredMessageBegin = "-----BEGIN PGP MESSAGE-----"
+)
+
+func ExportRequest(uuid string, encrypted []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n%s%s\n\n", requestExportTitle, requestExportUUID, uuid)
+	buf.Write(encrypted)
+	return buf.Bytes()
+}
+
+// ParseRequestExport returns the UUID and encrypted request from a file
+// written by ExportRequest.
+func ParseRequestExport(data []byte) (string, []byte, error) {
+	start := bytes.Index(data, []byte(armoredMessageBegin))
+	if start < 0 {
+		return "", nil, fmt.Errorf("no encrypted request found")
+	}
+
+	header := string(data[:start])
+	if !strings.HasPrefix(header, requestExportTitle+"\n") {
+		return "", nil, fmt.Errorf("not a kepr access request")
+	}
+
+	var uuid string
+	for _, line := range strings.Split(header, "\n") {
+		if strings.HasPrefix(line, requestExportUUID) {
+			uuid = strings.TrimSpace(strings.TrimPrefix(line, requestExportUUID))
+		}
+	}
+	if uuid == "" || strings.ContainsAny(uuid, "/\\.") {
+		return "", nil, fmt.Errorf("request file has no valid UUID")
+	}
+
+	return uuid, data[start:], nil
+}
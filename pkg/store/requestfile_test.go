@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import "testing"
+
+func TestRequestExport_RoundTrip(t *testing.T) {
+	encrypted := []byte("-----BEGIN PGP MESSAGE-----\n\nabc\n-----END PGP MESSAGE-----\n")
+
+	uuid, got, err := ParseRequestExport(ExportRequest("uuid-1", encrypted))
+	if err != nil {
+		t.Fatalf("ParseRequestExport() error: %v", err)
+	}
+	if uuid != "uuid-1" {
+		t.Errorf("uuid = %q, want uuid-1", uuid)
+	}
+	if string(got) != string(encrypted) {
+		t.Errorf("encrypted = %q, want %q", got, encrypted)
+	}
+}
+
+func TestParseRequestExport_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"no message": "kepr access request\nUUID: uuid-1\n\n",
+		"no header":  "-----BEGIN PGP MESSAGE-----\n",
+		"bad uuid":   "kepr access request\nUUID: ../etc\n\n-----BEGIN PGP MESSAGE-----\n",
+	}
+	for name, data := range cases {
+		if _, _, err := ParseRequestExport([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -32,6 +33,111 @@ type PendingRequest struct {
 	Path        string
 	PublicKey   string
 	Timestamp   string
+	Hostname    string
+	OS          string
+	Arch        string
+	KeprVersion string
+	Reason      string
+	GitHubLogin string
+	// Invalid explains why the request failed signature verification. Such
+	// requests are listed so they can be rejected, but never approved.
+	Invalid string
+}
+
+var ErrUnsignedRequest = errors.New("request is not signed")
+
+type requestJSON struct {
+	UUID        string `json:"uuid"`
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+	PublicKey   string `json:"public_key"`
+	Timestamp   string `json:"timestamp"`
+	Hostname    string `json:"hostname"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+	KeprVersion string `json:"kepr_version"`
+	Reason      string `json:"reason"`
+	GitHubLogin string `json:"github_login"`
+}
+
+func (r requestJSON) pending(uuid string) PendingRequest {
+	return PendingRequest{
+		UUID:        uuid,
+		Fingerprint: r.Fingerprint,
+		Path:        r.Path,
+		PublicKey:   r.PublicKey,
+		Timestamp:   r.Timestamp,
+		Hostname:    r.Hostname,
+		OS:          r.OS,
+		Arch:        r.Arch,
+		KeprVersion: r.KeprVersion,
+		Reason:      r.Reason,
+		GitHubLogin: r.GitHubLogin,
+	}
+}
+
+// parseRequest returns the verified request, or the claimed one marked
+// Invalid when its signature does not match the embedded key.
+func parseRequest(g *gpg.GPG, uuid string, decrypted []byte) PendingRequest {
+	text := decrypted
+	if clear, err := gpg.ClearsignedText(decrypted); err == nil {
+		text = clear
+	}
+
+	var claimed requestJSON
+	if err := json.Unmarshal(text, &claimed); err != nil {
+		return PendingRequest{UUID: uuid, Invalid: fmt.Sprintf("failed to parse request: %v", err)}
+	}
+
+	verified, err := verifyRequest(g, uuid, decrypted, claimed)
+	if err != nil {
+		req := claimed.pending(uuid)
+		req.Invalid = err.Error()
+		return req
+	}
+	return verified.pending(uuid)
+}
+
+// verifyRequest checks that the request is clearsigned by the public key it
+// embeds, that the key matches the claimed fingerprint, and that the signed
+// UUID is the one the request was filed under, so a signed request cannot be
+// replayed under another UUID.
+func verifyRequest(g *gpg.GPG, uuid string, decrypted []byte, claimed requestJSON) (requestJSON, error) {
+	if _, err := gpg.ClearsignedText(decrypted); err != nil {
+		return requestJSON{}, ErrUnsignedRequest
+	}
+
+	keyFingerprint, err := g.ReadKeyFingerprint([]byte(claimed.PublicKey))
+	if err != nil {
+		return requestJSON{}, fmt.Errorf("failed to read embedded public key: %w", err)
+	}
+	if keyFingerprint != claimed.Fingerprint {
+		return requestJSON{}, fmt.Errorf("embedded public key %s does not match fingerprint %s", keyFingerprint, claimed.Fingerprint)
+	}
+
+	if err := g.ImportPublicKey([]byte(claimed.PublicKey)); err != nil {
+		return requestJSON{}, fmt.Errorf("failed to import embedded public key: %w", err)
+	}
+
+	content, signer, err := g.Verify(decrypted)
+	if err != nil {
+		return requestJSON{}, err
+	}
+	if signer != claimed.Fingerprint {
+		return requestJSON{}, fmt.Errorf("request signed by %s instead of %s", signer, claimed.Fingerprint)
+	}
+
+	var verified requestJSON
+	if err := json.Unmarshal(content, &verified); err != nil {
+		return requestJSON{}, fmt.Errorf("failed to parse signed request: %w", err)
+	}
+	if verified.Fingerprint != claimed.Fingerprint {
+		return requestJSON{}, fmt.Errorf("signed request does not match its content")
+	}
+	if verified.UUID != uuid {
+		return requestJSON{}, fmt.Errorf("request signed for UUID %q was filed as %s", verified.UUID, uuid)
+	}
+	return verified, nil
 }
 
 func ListRequests(secretsPath string, g *gpg.GPG) ([]PendingRequest, error) {
@@ -63,23 +169,7 @@ func ListRequests(secretsPath string, g *gpg.GPG) ([]PendingRequest, error) {
 			return nil, fmt.Errorf("failed to decrypt request %s: %w", uuid, err)
 		}
 
-		var req struct {
-			Fingerprint string `json:"fingerprint"`
-			Path        string `json:"path"`
-			PublicKey   string `json:"public_key"`
-			Timestamp   string `json:"timestamp"`
-		}
-		if err := json.Unmarshal(decrypted, &req); err != nil {
-			return nil, fmt.Errorf("failed to parse request %s: %w", uuid, err)
-		}
-
-		requests = append(requests, PendingRequest{
-			UUID:        uuid,
-			Fingerprint: req.Fingerprint,
-			Path:        req.Path,
-			PublicKey:   req.PublicKey,
-			Timestamp:   req.Timestamp,
-		})
+		requests = append(requests, parseRequest(g, uuid, decrypted))
 	}
 
 	return requests, nil
@@ -117,6 +207,9 @@ func FindRequestsByEmail(secretsPath string, g *gpg.GPG, email string, resolveEm
 
 	var matches []PendingRequest
 	for _, r := range all {
+		if r.Invalid != "" {
+			continue
+		}
 		e := resolveEmail(g, r)
 		if strings.EqualFold(e, email) {
 			matches = append(matches, r)
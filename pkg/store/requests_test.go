@@ -86,3 +86,49 @@ func TestFindRequestByPrefix_NoMatch(t *testing.T) {
 		t.Fatal("expected error for no matching request")
 	}
 }
+
+func TestParseRequest_Unsigned(t *testing.T) {
+	decrypted := []byte(`{"fingerprint":"FP_AAA","path":"prod","public_key":"key"}`)
+
+	req := parseRequest(nil, "uuid-1", decrypted)
+	if req.Invalid != ErrUnsignedRequest.Error() {
+		t.Errorf("Invalid = %q, want %q", req.Invalid, ErrUnsignedRequest.Error())
+	}
+	if req.Path != "prod" || req.Fingerprint != "FP_AAA" {
+		t.Errorf("claimed fields not kept for display: %+v", req)
+	}
+}
+
+func TestParseRequest_MachineFacts(t *testing.T) {
+	decrypted := []byte(`{"fingerprint":"FP_AAA","path":"prod","public_key":"key","timestamp":"2025-01-02T03:04:05Z",` +
+		`"hostname":"build-01","os":"linux","arch":"amd64","kepr_version":"1.2.0","reason":"deploy bot","github_login":"octocat"}`)
+
+	req := parseRequest(nil, "uuid-1", decrypted)
+	want := PendingRequest{
+		UUID:        "uuid-1",
+		Fingerprint: "FP_AAA",
+		Path:        "prod",
+		PublicKey:   "key",
+		Timestamp:   "2025-01-02T03:04:05Z",
+		Hostname:    "build-01",
+		OS:          "linux",
+		Arch:        "amd64",
+		KeprVersion: "1.2.0",
+		Reason:      "deploy bot",
+		GitHubLogin: "octocat",
+		Invalid:     ErrUnsignedRequest.Error(),
+	}
+	if req != want {
+		t.Errorf("parseRequest() = %+v, want %+v", req, want)
+	}
+}
+
+func TestParseRequest_Malformed(t *testing.T) {
+	req := parseRequest(nil, "uuid-1", []byte("not json"))
+	if req.Invalid == "" {
+		t.Error("expected malformed request to be marked invalid")
+	}
+	if req.UUID != "uuid-1" {
+		t.Errorf("UUID = %q, want uuid-1", req.UUID)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A secret can carry its own recipient list in <uuid>.gpg.id next to
+// <uuid>.gpg. When present it replaces the directory's .gpg.id for that
+// secret, and its recipients can also decrypt the directory metadata so the
+// secret stays reachable by path.
+
+const secretGpgIDSuffix = ".gpg.id"
+
+func SecretGpgIDPath(dirPath, uuid string) string {
+	return filepath.Join(dirPath, uuid+secretGpgIDSuffix)
+}
+
+func ReadSecretGpgID(dirPath, uuid string) ([]string, error) {
+	data, err := os.ReadFile(SecretGpgIDPath(dirPath, uuid))
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := parseFingerprints(string(data))
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("no fingerprints found in %s%s", uuid, secretGpgIDSuffix)
+	}
+	return fingerprints, nil
+}
+
+func WriteSecretGpgID(dirPath, uuid string, fingerprints []string) error {
+	if len(fingerprints) == 0 {
+		return fmt.Errorf("at least one fingerprint is required")
+	}
+	content := strings.Join(fingerprints, "\n") + "\n"
+	return os.WriteFile(SecretGpgIDPath(dirPath, uuid), []byte(content), 0600)
+}
+
+// secretOverrides returns the per-secret recipient lists in dirPath keyed by uuid.
+func secretOverrides(dirPath string) map[string][]string {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil
+	}
+
+	overrides := make(map[string][]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == ".gpg.id" || !strings.HasSuffix(name, secretGpgIDSuffix) {
+			continue
+		}
+		uuid := strings.TrimSuffix(name, secretGpgIDSuffix)
+		fingerprints, err := ReadSecretGpgID(dirPath, uuid)
+		if err != nil {
+			slog.Debug("failed to read secret recipients, skipping", "uuid", uuid, "error", err)
+			continue
+		}
+		overrides[uuid] = fingerprints
+	}
+	return overrides
+}
+
+// secretRecipients returns the expanded recipients for a secret, preferring
+// its own override over the directory .gpg.id.
+func (s *Store) secretRecipients(dirPath, uuid string) ([]string, error) {
+	entries, err := ReadSecretGpgID(dirPath, uuid)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		return s.ReadRecipients(dirPath)
+	}
+	return s.ExpandRecipients(entries)
+}
+
+// dirMetadataRecipients returns the directory recipients plus everyone named
+// in a per-secret override, who must be able to resolve the directory name.
+func (s *Store) dirMetadataRecipients(dirRecipients []string, overrides map[string][]string) ([]string, error) {
+	recipients := dirRecipients
+	for uuid, entries := range overrides {
+		expanded, err := s.ExpandRecipients(entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand recipients for secret %s: %w", uuid, err)
+		}
+		for _, fp := range expanded {
+			recipients = appendUnique(recipients, fp)
+		}
+	}
+	return recipients, nil
+}
+
+func (s *Store) hasSecretAccess(dirPath string) bool {
+	for _, entries := range secretOverrides(dirPath) {
+		fingerprints, err := s.ExpandRecipients(entries)
+		if err != nil {
+			fingerprints = entries
+		}
+		for _, fp := range fingerprints {
+			if fp == s.Fingerprint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyOverrideUpdate applies the recipient update to every per-secret
+// override in dirPath and reports whether any of them changed.
+func applyOverrideUpdate(dirPath string, update RecipientUpdate) (bool, error) {
+	changed := false
+	for uuid, existing := range secretOverrides(dirPath) {
+		if update.Keep[SecretGpgIDPath(dirPath, uuid)] {
+			continue
+		}
+		updated := update.apply(existing)
+		if sameFingerprints(existing, updated) {
+			continue
+		}
+		if len(updated) == 0 {
+			return false, fmt.Errorf("refusing to remove every recipient from secret %s", uuid)
+		}
+		if err := WriteSecretGpgID(dirPath, uuid, updated); err != nil {
+			return false, fmt.Errorf("failed to write recipients for secret %s: %w", uuid, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+func applyOverrideUpdateTree(dirPath string, update RecipientUpdate) error {
+	if _, err := applyOverrideUpdate(dirPath, update); err != nil {
+		return err
+	}
+
+	subDirs, err := rekeySubdirs(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, subDir := range subDirs {
+		if err := applyOverrideUpdateTree(subDir, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSecret returns the directory holding the secret at path and the
+// secret's UUID.
+func (s *Store) resolveSecret(path string) (string, string, error) {
+	normalizedPath, err := NormalizePath(path)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	segments := SplitPath(normalizedPath)
+	dirSegments := segments[:len(segments)-1]
+	secretName := segments[len(segments)-1]
+
+	dirPath := s.SecretsPath
+	if len(dirSegments) > 0 {
+		resolved, err := s.resolveAccessiblePath(dirSegments)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve path: %w", err)
+		}
+		dirPath = resolved
+	}
+
+	uuid, err := s.findSecret(dirPath, secretName)
+	if err != nil {
+		return "", "", ErrSecretNotFound
+	}
+	return dirPath, uuid, nil
+}
+
+// AddSecretRecipient gives fingerprint access to a single secret by creating
+// or extending the secret's recipient override.
+func (s *Store) AddSecretRecipient(path, fingerprint string) error {
+	dirPath, uuid, err := s.resolveSecret(path)
+	if err != nil {
+		return err
+	}
+
+	existing, err := ReadSecretGpgID(dirPath, uuid)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read secret recipients: %w", err)
+		}
+		existing, err = ReadGpgID(dirPath)
+		if err != nil {
+			return fmt.Errorf("failed to read .gpg.id: %w", err)
+		}
+	}
+
+	if err := WriteSecretGpgID(dirPath, uuid, appendUnique(existing, fingerprint)); err != nil {
+		return fmt.Errorf("failed to write secret recipients: %w", err)
+	}
+
+	if err := EnsureGitignore(s.SecretsPath); err != nil {
+		return err
+	}
+
+	dirEntries, err := ReadGpgID(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .gpg.id: %w", err)
+	}
+
+	return s.reencryptFiles(dirPath, dirEntries, "")
+}
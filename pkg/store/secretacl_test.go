@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSecretGpgID_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteSecretGpgID(dir, "abc", []string{"FP_AAA", "FP_BBB"}); err != nil {
+		t.Fatalf("WriteSecretGpgID() failed: %v", err)
+	}
+
+	got, err := ReadSecretGpgID(dir, "abc")
+	if err != nil {
+		t.Fatalf("ReadSecretGpgID() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"FP_AAA", "FP_BBB"}) {
+		t.Errorf("ReadSecretGpgID() = %v", got)
+	}
+
+	if _, err := ReadSecretGpgID(dir, "missing"); !os.IsNotExist(err) {
+		t.Errorf("ReadSecretGpgID() on missing override = %v, want not exist", err)
+	}
+}
+
+func TestSecretOverrides_IgnoresDirectoryGpgID(t *testing.T) {
+	dir := t.TempDir()
+	writeTestGpgID(t, dir, "FP_AAA")
+	if err := WriteSecretGpgID(dir, "abc", []string{"FP_BBB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides := secretOverrides(dir)
+	if len(overrides) != 1 || !reflect.DeepEqual(overrides["abc"], []string{"FP_BBB"}) {
+		t.Errorf("secretOverrides() = %v", overrides)
+	}
+}
+
+func TestHasAccess_ThroughSecretOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeTestGpgID(t, dir, "FP_AAA")
+	if err := WriteSecretGpgID(dir, "abc", []string{"FP_AAA", "FP_BBB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	st := &Store{Fingerprint: "FP_BBB"}
+	if !st.hasAccess(dir) {
+		t.Error("hasAccess should return true when fingerprint is in a per-secret override")
+	}
+
+	st = &Store{Fingerprint: "FP_CCC"}
+	if st.hasAccess(dir) {
+		t.Error("hasAccess should return false when fingerprint is in no recipient list")
+	}
+}
+
+func TestUpdateRecipients_UpdatesSecretOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	prod := filepath.Join(tempDir, "prod")
+	writeTestGpgID(t, prod, "FP_AAA")
+	if err := WriteSecretGpgID(prod, "abc", []string{"FP_AAA", "FP_BBB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	st := &Store{SecretsPath: tempDir}
+	if err := st.updateRecipients(prod, "prod", RecipientUpdate{Add: []string{"FP_CCC"}}); err != nil {
+		t.Fatalf("updateRecipients() failed: %v", err)
+	}
+
+	got, _ := ReadSecretGpgID(prod, "abc")
+	want := []string{"FP_AAA", "FP_BBB", "FP_CCC"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("override recipients = %v, want %v", got, want)
+	}
+}
+
+func TestSecretUUID(t *testing.T) {
+	if got := secretUUID("abc.gpg"); got != "abc" {
+		t.Errorf("secretUUID(abc.gpg) = %s", got)
+	}
+	if got := secretUUID("abc_md.gpg"); got != "abc" {
+		t.Errorf("secretUUID(abc_md.gpg) = %s", got)
+	}
+}
+
+func TestEnsureGitignore(t *testing.T) {
+	dir := t.TempDir()
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	if err := os.WriteFile(gitignorePath, []byte("*\n!.gitignore\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EnsureGitignore(dir); err != nil {
+		t.Fatalf("EnsureGitignore() failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(gitignorePath)
+	if string(data) != GenerateGitignore() {
+		t.Errorf("EnsureGitignore() left %q", string(data))
+	}
+}
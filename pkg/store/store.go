@@ -42,6 +42,8 @@ type Store struct {
 	SecretsPath string
 	Fingerprint string
 	gpg         *gpg.GPG
+	groups      map[string][]string
+	signers     map[string]bool
 }
 
 func New(secretsPath string, gpgClient *gpg.GPG, fingerprint string) (*Store, error) {
@@ -64,12 +66,15 @@ func (s *Store) hasAccess(dirPath string) bool {
 	if err != nil {
 		return false
 	}
+	if expanded, err := s.ExpandRecipients(fingerprints); err == nil {
+		fingerprints = expanded
+	}
 	for _, fp := range fingerprints {
 		if fp == s.Fingerprint {
 			return true
 		}
 	}
-	return false
+	return s.hasSecretAccess(dirPath)
 }
 
 func ReadGpgID(dirPath string) ([]string, error) {
@@ -78,17 +83,22 @@ func ReadGpgID(dirPath string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read .gpg.id: %w", err)
 	}
+	fingerprints := parseFingerprints(string(data))
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("no fingerprints found in .gpg.id")
+	}
+	return fingerprints, nil
+}
+
+func parseFingerprints(data string) []string {
 	var fingerprints []string
-	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
 		line = strings.TrimSpace(line)
 		if line != "" {
 			fingerprints = append(fingerprints, line)
 		}
 	}
-	if len(fingerprints) == 0 {
-		return nil, fmt.Errorf("no fingerprints found in .gpg.id")
-	}
-	return fingerprints, nil
+	return fingerprints
 }
 
 func WriteGpgID(dirPath string, fingerprints []string) error {
@@ -174,7 +184,12 @@ func (s *Store) findOrCreateDirectory(parentDirPath string, dirName string, full
 		return "", fmt.Errorf("failed to serialize metadata: %w", err)
 	}
 
-	metadataEncrypted, err := s.gpg.Encrypt(metadataJSON, parentFingerprints...)
+	recipients, err := s.ExpandRecipients(parentFingerprints)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand recipients: %w", err)
+	}
+
+	metadataEncrypted, err := s.gpg.Encrypt(metadataJSON, recipients...)
 	if err != nil {
 		return "", fmt.Errorf("failed to encrypt metadata: %w", err)
 	}
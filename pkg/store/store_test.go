@@ -166,6 +166,10 @@ func TestGenerateGitignore(t *testing.T) {
 		t.Error("Gitignore should include *.gpg")
 	}
 
+	if !strings.Contains(content, "!*.gpg.id") {
+		t.Error("Gitignore should include per-secret *.gpg.id overrides")
+	}
+
 	if !strings.Contains(content, "*") {
 		t.Error("Gitignore should ignore all by default")
 	}
@@ -471,3 +475,23 @@ func TestPathSegment(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendUnique(t *testing.T) {
+	got := appendUnique([]string{"FP_AAA", "FP_BBB"}, "FP_CCC")
+	if len(got) != 3 || got[2] != "FP_CCC" {
+		t.Errorf("appendUnique() = %v, want FP_CCC appended", got)
+	}
+
+	got = appendUnique([]string{"FP_AAA", "FP_BBB"}, "FP_AAA")
+	if len(got) != 2 {
+		t.Errorf("appendUnique() = %v, want no duplicate", got)
+	}
+}
+
+func TestKeyPath(t *testing.T) {
+	got := KeyPath("/store", "FP_AAA")
+	want := filepath.Join("/store", "keys", "FP_AAA.key")
+	if got != want {
+		t.Errorf("KeyPath() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+)
+
+// IsTrustSensitive reports whether changing path alters who can read or
+// administer the store: recipient lists, groups, public keys, the approval
+// policy, access expirations and the approval and rejection records.
+func IsTrustSensitive(p string) bool {
+	base := path.Base(p)
+	return base == ".gpg.id" ||
+		strings.HasSuffix(base, ".gpg.id") ||
+		strings.HasPrefix(p, "keys/") ||
+		strings.HasPrefix(p, "groups/") ||
+		strings.HasPrefix(p, ApprovalsDir+"/") ||
+		strings.HasPrefix(p, RejectionsDir+"/") ||
+		p == PolicyFile ||
+		p == ExpirationsFile
+}
+
+// CommitTrust holds who may change the store, as of a trusted revision.
+type CommitTrust struct {
+	// Admins are the root recipients.
+	Admins map[string]bool
+	// Known are the fingerprints with a public key in keys/.
+	Known map[string]bool
+}
+
+// NewCommitTrust derives the trust rules from a revision's root .gpg.id and
+// its groups/ and keys/ files. Groups this machine cannot decrypt, or that
+// are not signed by a fingerprint listed directly in the root .gpg.id, are
+// skipped, so their members are only trusted as known keys.
+func NewCommitTrust(g *gpg.GPG, rootGpgID []byte, groups, keys map[string][]byte) CommitTrust {
+	t := CommitTrust{Admins: make(map[string]bool), Known: make(map[string]bool)}
+
+	entries := parseFingerprints(string(rootGpgID))
+	signers := make(map[string]bool)
+	for _, entry := range entries {
+		if IsGroupRef(entry) {
+			continue
+		}
+		t.Admins[entry] = true
+		signers[entry] = true
+	}
+
+	imported := false
+	for _, entry := range entries {
+		if !IsGroupRef(entry) {
+			continue
+		}
+		name := strings.TrimPrefix(entry, GroupPrefix)
+		encrypted, ok := groups[name+".gpg"]
+		if !ok {
+			continue
+		}
+		decrypted, err := g.Decrypt(encrypted)
+		if err != nil {
+			slog.Debug("cannot expand admin group", "group", name, "error", err)
+			continue
+		}
+		if !imported {
+			importSignerKeys(g, signers, keys)
+			imported = true
+		}
+		members, err := openGroup(g, name, decrypted, signers)
+		if err != nil {
+			slog.Debug("ignoring admin group", "group", name, "error", err)
+			continue
+		}
+		for _, fp := range members {
+			t.Admins[fp] = true
+		}
+	}
+
+	for name := range keys {
+		if fp, ok := strings.CutSuffix(name, ".key"); ok {
+			t.Known[fp] = true
+		}
+	}
+	return t
+}
+
+// importSignerKeys loads the public keys of the group signers from a
+// revision's keys/ files so their signatures can be checked.
+func importSignerKeys(g *gpg.GPG, signers map[string]bool, keys map[string][]byte) {
+	for fp := range signers {
+		key, ok := keys[fp+".key"]
+		if !ok {
+			continue
+		}
+		if err := g.ImportPublicKey(key); err != nil {
+			slog.Debug("failed to import root recipient key", "fingerprint", fp, "error", err)
+		}
+	}
+}
+
+// CheckCommit returns an error when signer may not make the changes. Root
+// recipients may change anything. Other known keys may change secrets and
+// requests, and refresh their own public key.
+func (t CommitTrust) CheckCommit(signer string, changed []string) error {
+	if signer == "" {
+		return fmt.Errorf("not signed by a known key")
+	}
+	if t.Admins[signer] {
+		return nil
+	}
+	if !t.Known[signer] {
+		return fmt.Errorf("signed by unknown key %s", signer)
+	}
+
+	for _, p := range changed {
+		if !IsTrustSensitive(p) {
+			continue
+		}
+		if p == "keys/"+signer+".key" {
+			continue
+		}
+		return fmt.Errorf("%s was changed by %s, who is not a root recipient", p, signer)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package store
+
+import "testing"
+
+func TestIsTrustSensitive(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".gpg.id", true},
+		{"prod/.gpg.id", true},
+		{"prod/3f2a.gpg.id", true},
+		{"keys/AAAA.key", true},
+		{"groups/ops.gpg", true},
+		{"policy.json", true},
+		{"expirations.json", true},
+		{"approvals/3f2a_AAAA.asc", true},
+		{"rejections/3f2a.json.gpg", true},
+		{"prod/3f2a.gpg", false},
+		{"requests/3f2a.json.gpg", false},
+	}
+	for _, tt := range tests {
+		if got := IsTrustSensitive(tt.path); got != tt.want {
+			t.Errorf("IsTrustSensitive(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCommit(t *testing.T) {
+	trust := NewCommitTrust(nil, []byte("ADMIN\n"), nil, map[string][]byte{
+		"ADMIN.key":   nil,
+		"MACHINE.key": nil,
+	})
+
+	tests := []struct {
+		name    string
+		signer  string
+		changed []string
+		wantErr bool
+	}{
+		{"unsigned", "", []string{"prod/a.gpg"}, true},
+		{"admin changes recipients", "ADMIN", []string{"prod/.gpg.id", "keys/NEW.key"}, false},
+		{"known key adds secret", "MACHINE", []string{"prod/a.gpg"}, false},
+		{"known key refreshes own key", "MACHINE", []string{"keys/MACHINE.key"}, false},
+		{"known key changes recipients", "MACHINE", []string{"prod/.gpg.id"}, true},
+		{"known key adds a key", "MACHINE", []string{"keys/OTHER.key"}, true},
+		{"unknown key", "STRANGER", []string{"prod/a.gpg"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := trust.CheckCommit(tt.signer, tt.changed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCommit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
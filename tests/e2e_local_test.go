@@ -0,0 +1,419 @@
+//go:build e2e
+
+/*
+Copyright © 2025 Gonzalo Alvarez
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gonzaloalvarez/kepr/cmd"
+	"github.com/gonzaloalvarez/kepr/internal/request"
+	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/cout"
+	"github.com/gonzaloalvarez/kepr/pkg/github"
+	"github.com/gonzaloalvarez/kepr/pkg/gpg"
+	"github.com/gonzaloalvarez/kepr/pkg/shell"
+	"github.com/gonzaloalvarez/kepr/pkg/store"
+)
+
+// runKepr runs one kepr command, feeding stdin and returning what it wrote
+// to stdout.
+func runKepr(t *testing.T, app *cmd.App, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	defer func() { os.Stdin, os.Stdout = oldStdin, oldStdout }()
+
+	inR, inW, _ := os.Pipe()
+	os.Stdin = inR
+	go func() {
+		inW.WriteString(stdin)
+		inW.Close()
+	}()
+
+	outR, outW, _ := os.Pipe()
+	os.Stdout = outW
+
+	rootCmd := cmd.NewRootCmd(app)
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+
+	outW.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(outR)
+	return buf.String(), err
+}
+
+func newLocalApp(t *testing.T) *cmd.App {
+	t.Helper()
+	t.Setenv("KEPR_CI", "true")
+	t.Setenv("KEPR_HOME", filepath.Join(t.TempDir(), "kepr"))
+	// Nothing below may reach GitHub.
+	t.Setenv("GITHUB_HOST", "http://127.0.0.1:1")
+
+	return &cmd.App{
+		Shell:  &shell.SystemExecutor{},
+		UI:     cout.NewTerminal(),
+		GitHub: github.NewGitHubClient(),
+	}
+}
+
+func TestE2E_LocalBareRepo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	app := newLocalApp(t)
+	barePath := filepath.Join(t.TempDir(), "srv", "secrets.git")
+
+	if _, err := runKepr(t, app, "", "init", "secrets", "--remote", barePath, "--headless",
+		"--name", "Test User", "--email", "test@example.com"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	if _, err := runKepr(t, app, "my-local-secret\n", "add", "db/password"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	output, err := runKepr(t, app, "", "get", "db/password")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if !strings.Contains(output, "my-local-secret") {
+		t.Errorf("expected output to contain the secret, got %q", output)
+	}
+
+	bare, err := gogit.PlainOpen(barePath)
+	if err != nil {
+		t.Fatalf("expected a bare repository at %s: %v", barePath, err)
+	}
+	ref, err := bare.Reference(plumbing.NewBranchReferenceName("main"), true)
+	if err != nil {
+		t.Fatalf("expected main to be pushed to the bare repository: %v", err)
+	}
+	commit, err := bare.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("failed to read pushed commit: %v", err)
+	}
+	if commit.NumParents() == 0 {
+		t.Errorf("expected the added secret to be pushed, head is %q", commit.Message)
+	}
+
+	if _, err := runKepr(t, app, "", "sync"); err != nil {
+		t.Errorf("sync failed: %v", err)
+	}
+}
+
+func TestE2E_LocalOnly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	app := newLocalApp(t)
+
+	if _, err := runKepr(t, app, "", "init", "secrets", "--provider", "local", "--headless",
+		"--name", "Test User", "--email", "test@example.com"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	if _, err := runKepr(t, app, "my-local-secret\n", "add", "db/password"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	output, err := runKepr(t, app, "", "get", "db/password")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if !strings.Contains(output, "my-local-secret") {
+		t.Errorf("expected output to contain the secret, got %q", output)
+	}
+
+	if _, err := runKepr(t, app, "", "sync"); err == nil {
+		t.Error("sync should fail for a store without a remote")
+	}
+}
+
+func TestE2E_LocalSignedGroup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	app := newLocalApp(t)
+	barePath := filepath.Join(t.TempDir(), "srv", "secrets.git")
+
+	if _, err := runKepr(t, app, "", "init", "secrets", "--remote", barePath, "--headless",
+		"--name", "Test User", "--email", "test@example.com"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if _, err := runKepr(t, app, "my-local-secret\n", "add", "prod/db"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	fingerprint := config.GetUserFingerprint()
+	if _, err := runKepr(t, app, "", "group", "add", "ops", fingerprint); err != nil {
+		t.Fatalf("group add failed: %v", err)
+	}
+	if _, err := runKepr(t, app, "", "access", "grant", "prod", "--key", "@ops"); err != nil {
+		t.Fatalf("grant to the signed group failed: %v", err)
+	}
+
+	output, err := runKepr(t, app, "", "get", "prod/db")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if !strings.Contains(output, "my-local-secret") {
+		t.Errorf("expected output to contain the secret, got %q", output)
+	}
+}
+
+// newLocalGPG returns a gpg with its own keyring holding a freshly generated
+// key.
+func newLocalGPG(t *testing.T, name, email string) (*gpg.GPG, string) {
+	t.Helper()
+
+	g, err := gpg.New(t.TempDir(), &shell.SystemExecutor{}, cout.NewTerminal())
+	if err != nil {
+		t.Fatalf("failed to set up gpg: %v", err)
+	}
+	fingerprint, err := g.GenerateKeys(name, email)
+	if err != nil {
+		t.Fatalf("failed to generate key for %s: %v", email, err)
+	}
+	return g, fingerprint
+}
+
+func TestE2E_LocalRejectionRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	newLocalApp(t)
+	admin, adminFP := newLocalGPG(t, "Admin", "admin@example.com")
+	machine, machineFP := newLocalGPG(t, "Machine", "machine@example.com")
+
+	machineKey, err := machine.ExportPublicKey(machineFP)
+	if err != nil {
+		t.Fatalf("failed to export machine key: %v", err)
+	}
+	if err := admin.ImportPublicKey(machineKey); err != nil {
+		t.Fatalf("failed to import machine key: %v", err)
+	}
+
+	secretsPath := t.TempDir()
+	if err := store.WriteGpgID(secretsPath, []string{adminFP}); err != nil {
+		t.Fatalf("failed to write root .gpg.id: %v", err)
+	}
+	if err := store.SavePublicKey(secretsPath, admin, adminFP); err != nil {
+		t.Fatalf("failed to save admin key: %v", err)
+	}
+
+	rejection := store.Rejection{
+		RequestUUID: "3f2a",
+		Fingerprint: machineFP,
+		Path:        "prod",
+		Reason:      "use the shared CI key instead",
+		RejectedBy:  "Admin <admin@example.com>",
+		Signer:      adminFP,
+		Timestamp:   "2025-01-02T03:04:05Z",
+	}
+	if err := store.WriteRejection(secretsPath, admin, rejection); err != nil {
+		t.Fatalf("WriteRejection() failed: %v", err)
+	}
+
+	// A rejection the machine signed itself must not count.
+	forged := rejection
+	forged.RequestUUID = "9b1c"
+	forged.Signer = machineFP
+	if err := store.WriteRejection(secretsPath, machine, forged); err != nil {
+		t.Fatalf("WriteRejection() of the forged rejection failed: %v", err)
+	}
+
+	rejections, err := store.ListRejections(secretsPath, machine, machineFP)
+	if err != nil {
+		t.Fatalf("ListRejections() failed: %v", err)
+	}
+	if len(rejections) != 1 || rejections[0] != rejection {
+		t.Errorf("ListRejections() = %+v, want only the admin's rejection", rejections)
+	}
+
+	others, err := store.ListRejections(secretsPath, admin, adminFP)
+	if err != nil {
+		t.Fatalf("ListRejections() for another key failed: %v", err)
+	}
+	if len(others) != 0 {
+		t.Errorf("ListRejections() for the admin = %+v, want none", others)
+	}
+}
+
+func TestE2E_LocalSignedRequestFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	newLocalApp(t)
+	admin, adminFP := newLocalGPG(t, "Admin", "admin@example.com")
+	machine, machineFP := newLocalGPG(t, "Machine", "machine@example.com")
+
+	adminKey, err := admin.ExportPublicKey(adminFP)
+	if err != nil {
+		t.Fatalf("failed to export admin key: %v", err)
+	}
+	if err := machine.ImportPublicKey(adminKey); err != nil {
+		t.Fatalf("failed to import admin key: %v", err)
+	}
+	machineKey, err := machine.ExportPublicKey(machineFP)
+	if err != nil {
+		t.Fatalf("failed to export machine key: %v", err)
+	}
+
+	req := request.AccessRequest{
+		UUID:        "3f2a",
+		Fingerprint: machineFP,
+		Path:        "prod",
+		PublicKey:   string(machineKey),
+		Timestamp:   "2025-01-02T03:04:05Z",
+		Hostname:    "build-01",
+		OS:          "linux",
+		Arch:        "amd64",
+		KeprVersion: "1.2.0",
+		Reason:      "deploy bot for the payments service",
+		GitHubLogin: "octocat",
+	}
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	signed, err := machine.Sign(data, machineFP)
+	if err != nil {
+		t.Fatalf("failed to sign request: %v", err)
+	}
+	encrypted, err := machine.Encrypt(signed, adminFP)
+	if err != nil {
+		t.Fatalf("failed to encrypt request: %v", err)
+	}
+
+	secretsPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(secretsPath, "requests"), 0700); err != nil {
+		t.Fatalf("failed to create requests directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(secretsPath, "requests", "3f2a.json.gpg"), encrypted, 0600); err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	pending, err := store.ListRequests(secretsPath, admin)
+	if err != nil {
+		t.Fatalf("ListRequests() failed: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("ListRequests() returned %d requests, want 1", len(pending))
+	}
+	got := pending[0]
+	if got.Invalid != "" {
+		t.Fatalf("request failed verification: %s", got.Invalid)
+	}
+	if got.Hostname != req.Hostname || got.OS != req.OS || got.Arch != req.Arch || got.KeprVersion != req.KeprVersion ||
+		got.Reason != req.Reason || got.GitHubLogin != req.GitHubLogin || got.Timestamp != req.Timestamp {
+		t.Errorf("ListRequests() = %+v, want the signed machine facts and reason of %+v", got, req)
+	}
+
+	// The same signed request filed under another UUID is a replay.
+	if err := os.WriteFile(filepath.Join(secretsPath, "requests", "9b1c.json.gpg"), encrypted, 0600); err != nil {
+		t.Fatalf("failed to write replayed request: %v", err)
+	}
+	replayed, err := store.FindRequestByPrefix(secretsPath, admin, "9b1c")
+	if err != nil {
+		t.Fatalf("FindRequestByPrefix() failed: %v", err)
+	}
+	if replayed.Invalid == "" {
+		t.Error("expected a request filed under another UUID to fail verification")
+	}
+}
+
+func TestE2E_LocalRequestedKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	newLocalApp(t)
+	admin, adminFP := newLocalGPG(t, "Admin", "admin@example.com")
+	machine, machineFP := newLocalGPG(t, "Machine", "machine@example.com")
+
+	sign := func(g *gpg.GPG, fp, uuid string) []byte {
+		t.Helper()
+		key, err := g.ExportPublicKey(fp)
+		if err != nil {
+			t.Fatalf("failed to export key: %v", err)
+		}
+		signed, err := store.SignApproval(g, store.Approval{
+			RequestUUID: uuid,
+			Approver:    fp,
+			Path:        "prod",
+			Requester:   machineFP,
+			PublicKey:   string(key),
+			Timestamp:   "2025-01-02T03:04:05Z",
+		})
+		if err != nil {
+			t.Fatalf("SignApproval() failed: %v", err)
+		}
+		return signed
+	}
+
+	files := map[string][]byte{"3f2a_admin.asc": sign(admin, adminFP, "3f2a")}
+	keys := store.RequestedKeys(admin, files, []string{adminFP})
+	if !keys[machineFP] || len(keys) != 1 {
+		t.Errorf("RequestedKeys() = %v, want only the machine granted by the admin", keys)
+	}
+
+	// An approval signed by someone other than an admin does not count.
+	files = map[string][]byte{"9b1c_machine.asc": sign(machine, machineFP, "9b1c")}
+	if keys := store.RequestedKeys(admin, files, []string{adminFP}); len(keys) != 0 {
+		t.Errorf("RequestedKeys() = %v, want none for a non-admin approval", keys)
+	}
+}
+
+func TestE2E_LocalRequestFlagCombinations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	app := newLocalApp(t)
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--status", "--wait"}, "--wait"},
+		{[]string{"--approve", "3f2a", "--pr"}, "--pr"},
+		{[]string{"--prune", "--wait", "--timeout", "10m"}, "--wait"},
+		{[]string{"--wait"}, "--wait"},
+		{[]string{"--status", "--reason", "deploy bot"}, "--reason"},
+		{[]string{"--approve", "3f2a", "--reason", "deploy bot"}, "--reason"},
+	} {
+		args := append([]string{"request", "-r", "owner/repo"}, tt.args...)
+		_, err := runKepr(t, app, "", args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("kepr %s = %v, want an error about %s", strings.Join(args, " "), err, tt.want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/gonzaloalvarez/kepr/cmd"
 	"github.com/gonzaloalvarez/kepr/pkg/config"
+	"github.com/gonzaloalvarez/kepr/pkg/sas"
 	"github.com/gonzaloalvarez/kepr/tests/mocks"
 )
 
@@ -525,6 +526,7 @@ func TestApprove_HappyPath(t *testing.T) {
 		"-----BEGIN PGP PUBLIC KEY BLOCK-----\nrequester-exported-key\n-----END PGP PUBLIC KEY BLOCK-----\n", "", nil)
 
 	mockUI.ConfirmInputs = []bool{true, true, true}
+	mockUI.TextInputs = []string{sas.Code(requesterFingerprint, requestUUID)}
 
 	app := &cmd.App{
 		Shell:  mockShell,
@@ -960,6 +962,7 @@ func TestApprove_FetchFromBranch(t *testing.T) {
 		"-----BEGIN PGP PUBLIC KEY BLOCK-----\nrequester-exported-key\n-----END PGP PUBLIC KEY BLOCK-----\n", "", nil)
 
 	mockUI.ConfirmInputs = []bool{true, true, true}
+	mockUI.TextInputs = []string{sas.Code(requesterFingerprint, requestUUID)}
 
 	app := &cmd.App{
 		Shell:  mockShell,
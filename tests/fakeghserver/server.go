@@ -59,13 +59,26 @@ type Server struct {
 	listener   net.Listener
 	repos      map[string]string
 	reposMutex sync.RWMutex
+	pulls      map[string][]*fakePull
+	pullsMutex sync.Mutex
 	baseURL    string
 }
 
+type fakePull struct {
+	Number int
+	Title  string
+	Body   string
+	Head   string
+	Base   string
+	State  string
+	Merged bool
+}
+
 func New(cfg Config) *Server {
 	s := &Server{
 		config: cfg,
 		repos:  make(map[string]string),
+		pulls:  make(map[string][]*fakePull),
 	}
 
 	mux := http.NewServeMux()
@@ -327,6 +340,11 @@ func (s *Server) handleRepos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) >= 3 && parts[2] == "pulls" {
+		s.handlePulls(w, r, repoKey, parts[3:])
+		return
+	}
+
 	if r.Method == http.MethodGet {
 		s.reposMutex.RLock()
 		repoPath, exists := s.repos[repoKey]
@@ -391,3 +409,188 @@ func (s *Server) handleCloneURL(w http.ResponseWriter, r *http.Request, repoKey
 		"clone_url": cloneURL,
 	})
 }
+
+func (s *Server) handlePulls(w http.ResponseWriter, r *http.Request, repoKey string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		s.handleListPulls(w, r, repoKey)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		s.handleCreatePull(w, r, repoKey)
+	case len(rest) == 1 && r.Method == http.MethodPatch:
+		s.handleEditPull(w, r, repoKey, rest[0])
+	case len(rest) == 2 && rest[1] == "merge" && r.Method == http.MethodPut:
+		s.handleMergePull(w, r, repoKey, rest[0])
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) pullJSON(repoKey string, pr *fakePull) map[string]interface{} {
+	return map[string]interface{}{
+		"number":   pr.Number,
+		"title":    pr.Title,
+		"body":     pr.Body,
+		"state":    pr.State,
+		"merged":   pr.Merged,
+		"html_url": fmt.Sprintf("%s/%s/pull/%d", s.baseURL, repoKey, pr.Number),
+		"head":     map[string]interface{}{"ref": pr.Head},
+		"base":     map[string]interface{}{"ref": pr.Base},
+	}
+}
+
+func (s *Server) findPull(repoKey, number string) *fakePull {
+	for _, pr := range s.pulls[repoKey] {
+		if fmt.Sprint(pr.Number) == number {
+			return pr
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleListPulls(w http.ResponseWriter, r *http.Request, repoKey string) {
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+	head := r.URL.Query().Get("head")
+	if i := strings.Index(head, ":"); i >= 0 {
+		head = head[i+1:]
+	}
+
+	slog.Debug("handling list pulls request", "repo", repoKey, "state", state, "head", head)
+
+	s.pullsMutex.Lock()
+	result := []map[string]interface{}{}
+	for _, pr := range s.pulls[repoKey] {
+		if (state == "all" || pr.State == state) && (head == "" || pr.Head == head) {
+			result = append(result, s.pullJSON(repoKey, pr))
+		}
+	}
+	s.pullsMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) handleCreatePull(w http.ResponseWriter, r *http.Request, repoKey string) {
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	slog.Debug("handling create pull request", "repo", repoKey, "head", req.Head, "base", req.Base)
+
+	s.pullsMutex.Lock()
+	pr := &fakePull{
+		Number: len(s.pulls[repoKey]) + 1,
+		Title:  req.Title,
+		Body:   req.Body,
+		Head:   req.Head,
+		Base:   req.Base,
+		State:  "open",
+	}
+	s.pulls[repoKey] = append(s.pulls[repoKey], pr)
+	body := s.pullJSON(repoKey, pr)
+	s.pullsMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) handleEditPull(w http.ResponseWriter, r *http.Request, repoKey, number string) {
+	var req struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.pullsMutex.Lock()
+	defer s.pullsMutex.Unlock()
+
+	pr := s.findPull(repoKey, number)
+	if pr == nil {
+		http.Error(w, "pull request not found", http.StatusNotFound)
+		return
+	}
+	if req.State != "" {
+		pr.State = req.State
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.pullJSON(repoKey, pr))
+}
+
+// handleMergePull merges the head branch into the base branch of the bare
+// repository with a merge commit, as GitHub does by default.
+func (s *Server) handleMergePull(w http.ResponseWriter, r *http.Request, repoKey, number string) {
+	var req struct {
+		CommitMessage string `json:"commit_message"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.pullsMutex.Lock()
+	defer s.pullsMutex.Unlock()
+
+	pr := s.findPull(repoKey, number)
+	if pr == nil || pr.State != "open" {
+		http.Error(w, "pull request not found", http.StatusNotFound)
+		return
+	}
+
+	s.reposMutex.RLock()
+	repoPath, exists := s.repos[repoKey]
+	s.reposMutex.RUnlock()
+	if !exists {
+		http.Error(w, "repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := mergeBranch(repoPath, pr.Head, pr.Base, req.CommitMessage); err != nil {
+		slog.Error("failed to merge pull request", "error", err)
+		http.Error(w, "merge conflict", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pr.State = "closed"
+	pr.Merged = true
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"merged":  true,
+		"message": "Pull Request successfully merged",
+	})
+}
+
+func mergeBranch(repoPath, head, base, message string) error {
+	workDir, err := os.MkdirTemp("", "fakegh-merge-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	if message == "" {
+		message = fmt.Sprintf("Merge branch '%s'", head)
+	}
+
+	steps := [][]string{
+		{"clone", "--quiet", "--branch", base, repoPath, workDir},
+		{"-C", workDir, "-c", "user.name=fakegh", "-c", "user.email=fakegh@example.com",
+			"merge", "--quiet", "--no-ff", "-m", message, "origin/" + head},
+		{"-C", workDir, "push", "--quiet", "origin", base},
+	}
+	for _, args := range steps {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, out)
+		}
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ type MockGitHub struct {
 	Repos      map[string]bool
 	CloneURLs  map[string]string
 	AuthCalled bool
+	Pulls      []github.PullRequest
 }
 
 func NewMockGitHub(userName, userEmail string) *MockGitHub {
@@ -77,6 +78,47 @@ func (m *MockGitHub) GetCloneURL(name string) (string, error) {
 	return fmt.Sprintf("file:///tmp/mock-repos/%s/%s.git", m.UserLogin, name), nil
 }
 
+func (m *MockGitHub) CreatePullRequest(repo, head, base, title, body string) (*github.PullRequest, error) {
+	pr := github.PullRequest{
+		Number: len(m.Pulls) + 1,
+		Title:  title,
+		Head:   head,
+		Base:   base,
+		State:  "open",
+		URL:    fmt.Sprintf("https://github.com/%s/pull/%d", repo, len(m.Pulls)+1),
+	}
+	m.Pulls = append(m.Pulls, pr)
+	return &pr, nil
+}
+
+func (m *MockGitHub) ListPullRequests(repo, head string) ([]github.PullRequest, error) {
+	var open []github.PullRequest
+	for _, pr := range m.Pulls {
+		if pr.State == "open" && (head == "" || pr.Head == head) {
+			open = append(open, pr)
+		}
+	}
+	return open, nil
+}
+
+func (m *MockGitHub) MergePullRequest(repo string, number int, message string) error {
+	return m.setPullState(number, "merged")
+}
+
+func (m *MockGitHub) ClosePullRequest(repo string, number int) error {
+	return m.setPullState(number, "closed")
+}
+
+func (m *MockGitHub) setPullState(number int, state string) error {
+	for i := range m.Pulls {
+		if m.Pulls[i].Number == number {
+			m.Pulls[i].State = state
+			return nil
+		}
+	}
+	return fmt.Errorf("mock: pull request #%d not found", number)
+}
+
 func (m *MockGitHub) WasRepoCalled(name string) bool {
 	_, exists := m.Repos[name]
 	return exists